@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+)
+
+// AdminHandler holds dependencies used inside the admin http handlers.
+type AdminHandler struct {
+	Importer ports.SubscriptionImporter
+}
+
+// NewAdminHandler returns a new AdminHandler.
+func NewAdminHandler(importer ports.SubscriptionImporter) AdminHandler {
+	return AdminHandler{
+		Importer: importer,
+	}
+}
+
+// ImportSubscriptions imports the csv or jsonl subscription rows sent in the request body
+// and responds with the per row report. The format is passed through the format query,
+// dry_run only validates the rows and allow_past_start_date skips the start date check.
+func (h *AdminHandler) ImportSubscriptions(ctx *gin.Context) {
+	dryRun, err := parseBoolQuery(ctx, "dry_run")
+	if err != nil {
+		abortWithFieldError(ctx, "dry_run", err)
+		return
+	}
+	allowPastStartDate, err := parseBoolQuery(ctx, "allow_past_start_date")
+	if err != nil {
+		abortWithFieldError(ctx, "allow_past_start_date", err)
+		return
+	}
+	opts := domain.ImportOptions{
+		Format:             ctx.DefaultQuery("format", domain.ImportFormatCSV),
+		DryRun:             dryRun,
+		AllowPastStartDate: allowPastStartDate,
+	}
+
+	report, err := h.Importer.ImportSubscriptions(ctx, ctx.Request.Body, opts)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// parseBoolQuery parses an optional boolean query parameter, false when missing.
+func parseBoolQuery(ctx *gin.Context, name string) (bool, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: invalid %s query", domain.ErrInvalidRequest, name)
+	}
+	return b, nil
+}
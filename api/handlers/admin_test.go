@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AdminHttpTestSuite struct {
+	suite.Suite
+	importer *ports.MockSubscriptionImporter
+}
+
+func TestAdminHttpTestSuite(t *testing.T) {
+	suite.Run(t, new(AdminHttpTestSuite))
+}
+
+func (ts *AdminHttpTestSuite) SetupTest() {
+	ctrl := gomock.NewController(ts.T())
+	ts.importer = ports.NewMockSubscriptionImporter(ctrl)
+}
+
+func (ts *AdminHttpTestSuite) TestAdminHandlers_ImportSubscriptions() {
+	tt := []struct {
+		name             string
+		query            string
+		opts             domain.ImportOptions
+		importTimes      int
+		report           domain.ImportReport
+		err              error
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:        "Import success",
+			query:       "format=jsonl&dry_run=true&allow_past_start_date=1",
+			opts:        domain.ImportOptions{Format: "jsonl", DryRun: true, AllowPastStartDate: true},
+			importTimes: 1,
+			report: domain.ImportReport{
+				DryRun:   true,
+				Total:    2,
+				Imported: 1,
+				Failed:   1,
+				Errors:   []domain.ImportRowError{{Line: 2, Error: "invalid start date"}},
+			},
+			expectedCode:     http.StatusOK,
+			expectedResponse: `{"dry_run":true,"total":2,"imported":1,"failed":1,"errors":[{"line":2,"error":"invalid start date"}]}`,
+		},
+		{
+			name:             "Import: invalid dry_run",
+			query:            "dry_run=maybe",
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_request","title":"invalid request","status":400,"detail":"invalid request: invalid dry_run query","instance":"/api/admin/subscriptions/import","code":"invalid_request","errors":[{"field":"dry_run","code":"invalid_request","detail":"invalid dry_run query"}]}`,
+		},
+		{
+			name:             "Import: invalid format",
+			query:            "format=xml",
+			opts:             domain.ImportOptions{Format: "xml"},
+			importTimes:      1,
+			err:              domain.ErrInvalidImportFormat,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_import_format","title":"invalid import format","status":400,"instance":"/api/admin/subscriptions/import","code":"invalid_import_format"}`,
+		},
+		{
+			name:             "Import: not an admin",
+			query:            "format=csv",
+			opts:             domain.ImportOptions{Format: "csv"},
+			importTimes:      1,
+			err:              domain.ErrForbidden,
+			expectedCode:     http.StatusForbidden,
+			expectedResponse: `{"type":"urn:isildur:problem:forbidden","title":"operation not allowed","status":403,"instance":"/api/admin/subscriptions/import","code":"forbidden"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/admin/subscriptions/import?"+tc.query,
+				strings.NewReader("product_id,start_date,duration_in_months,customer_id\n"))
+
+			ts.importer.EXPECT().ImportSubscriptions(gomock.Any(), gomock.Any(), tc.opts).
+				Times(tc.importTimes).
+				Return(tc.report, tc.err)
+
+			hndlr := NewAdminHandler(ts.importer)
+			hndlr.ImportSubscriptions(c)
+			ts.Assert().EqualValues(tc.expectedCode, w.Code)
+
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Assert().Nil(err)
+			ts.Assert().EqualValues(tc.expectedResponse, string(data))
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/constants"
+	"github.com/google/uuid"
+)
+
+// APIKeysHandler holds dependencies used inside the api keys http handlers.
+type APIKeysHandler struct {
+	Keys ports.APIKeyService
+}
+
+// NewAPIKeysHandler returns a new APIKeysHandler.
+func NewAPIKeysHandler(keys ports.APIKeyService) APIKeysHandler {
+	return APIKeysHandler{
+		Keys: keys,
+	}
+}
+
+// CreateAPIKey creates an API key and responds with it, the key being only shown once.
+func (h *APIKeysHandler) CreateAPIKey(ctx *gin.Context) {
+	r := CreateAPIKeyRequest{}
+	if err := ctx.ShouldBindJSON(&r); err != nil {
+		abortWithInvalidRequest(ctx, err)
+		return
+	}
+	if _, err := govalidator.ValidateStruct(r); err != nil {
+		abortWithInvalidRequest(ctx, err)
+		return
+	}
+
+	var expiresAt *time.Time
+	if r.ExpiresAt != "" {
+		date, err := time.Parse(constants.DateFormat, r.ExpiresAt)
+		if err != nil {
+			abortWithFieldError(ctx, "expires_at", fmt.Errorf("%w: invalid expires_at date", domain.ErrInvalidAPIKey))
+			return
+		}
+		expiresAt = &date
+	}
+	scopes := make([]domain.Role, 0, len(r.Scopes))
+	for _, scope := range r.Scopes {
+		scopes = append(scopes, domain.Role(scope))
+	}
+
+	key, err := h.Keys.CreateAPIKey(ctx, r.Name, scopes, expiresAt)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, key)
+}
+
+// ListAPIKeys lists every API key, without the keys themselves.
+func (h *APIKeysHandler) ListAPIKeys(ctx *gin.Context) {
+	keys, err := h.Keys.ListAPIKeys(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, keys)
+}
+
+// RevokeAPIKey revokes an API key for given id.
+func (h *APIKeysHandler) RevokeAPIKey(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param(constants.APIKeyIDKey))
+	if err != nil {
+		abortWithError(ctx, fmt.Errorf("%w: invalid api key id: %v", domain.ErrInvalidRequest, err))
+		return
+	}
+	if err := h.Keys.RevokeAPIKey(ctx, id); err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"status_code": http.StatusOK,
+		"message":     "Successfully revoked the api key.",
+	})
+}
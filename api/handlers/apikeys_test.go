@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/constants"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type APIKeysHttpTestSuite struct {
+	suite.Suite
+	keys *ports.MockAPIKeyService
+}
+
+func TestAPIKeysHttpTestSuite(t *testing.T) {
+	suite.Run(t, new(APIKeysHttpTestSuite))
+}
+
+func (ts *APIKeysHttpTestSuite) SetupTest() {
+	ctrl := gomock.NewController(ts.T())
+	ts.keys = ports.NewMockAPIKeyService(ctrl)
+}
+
+func (ts *APIKeysHttpTestSuite) TestAPIKeysHandlers_CreateAPIKey() {
+	expiresAt := time.Date(2022, time.December, 31, 0, 0, 0, 0, time.UTC)
+	key := domain.NewAPIKey{
+		APIKey: domain.APIKey{
+			ID:        uuid.MustParse("0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f"),
+			Name:      "Partner gym",
+			Prefix:    "a1b2c3d4",
+			Hash:      "hash",
+			Scopes:    []domain.Role{domain.RoleSupport},
+			CreatedAt: time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC),
+			ExpiresAt: &expiresAt,
+		},
+		Key: "isk_a1b2c3d4_secret",
+	}
+
+	tt := []struct {
+		name             string
+		body             string
+		createTimes      int
+		expiresAt        *time.Time
+		createErr        error
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:         "Create api key",
+			body:         `{"name":"Partner gym","scopes":["support"],"expires_at":"31-12-2022"}`,
+			createTimes:  1,
+			expiresAt:    &expiresAt,
+			expectedCode: http.StatusCreated,
+			expectedResponse: `{"id":"0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f","name":"Partner gym","prefix":"a1b2c3d4",` +
+				`"scopes":["support"],"created_at":"2022-06-01T00:00:00Z","expires_at":"2022-12-31T00:00:00Z",` +
+				`"key":"isk_a1b2c3d4_secret"}`,
+		},
+		{
+			name:             "Create api key: missing name",
+			body:             `{"scopes":["support"]}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_request","title":"invalid request","status":400,"detail":"name: non zero value required","instance":"/api/admin/api-keys","code":"invalid_request","errors":[{"field":"name","code":"required","detail":"non zero value required"}]}`,
+		},
+		{
+			name:             "Create api key: invalid expiry",
+			body:             `{"name":"Partner gym","scopes":["support"],"expires_at":"2022-12-31"}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_api_key","title":"invalid api key","status":400,"detail":"invalid api key: invalid expires_at date","instance":"/api/admin/api-keys","code":"invalid_api_key","errors":[{"field":"expires_at","code":"invalid_api_key","detail":"invalid expires_at date"}]}`,
+		},
+		{
+			name:             "Create api key: not an admin",
+			body:             `{"name":"Partner gym","scopes":["support"],"expires_at":"31-12-2022"}`,
+			createTimes:      1,
+			expiresAt:        &expiresAt,
+			createErr:        domain.ErrForbidden,
+			expectedCode:     http.StatusForbidden,
+			expectedResponse: `{"type":"urn:isildur:problem:forbidden","title":"operation not allowed","status":403,"instance":"/api/admin/api-keys","code":"forbidden"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/admin/api-keys", strings.NewReader(tc.body))
+
+			ts.keys.EXPECT().CreateAPIKey(gomock.Any(), "Partner gym", []domain.Role{domain.RoleSupport}, tc.expiresAt).
+				Times(tc.createTimes).
+				Return(key, tc.createErr)
+
+			hndlr := NewAPIKeysHandler(ts.keys)
+			hndlr.CreateAPIKey(c)
+			ts.Assert().EqualValues(tc.expectedCode, w.Code)
+
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Assert().Nil(err)
+			ts.Assert().EqualValues(tc.expectedResponse, string(data))
+		})
+	}
+}
+
+func (ts *APIKeysHttpTestSuite) TestAPIKeysHandlers_RevokeAPIKey() {
+	keyID := uuid.MustParse("0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f")
+
+	tt := []struct {
+		name             string
+		id               string
+		revokeTimes      int
+		revokeErr        error
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:             "Revoke api key",
+			id:               keyID.String(),
+			revokeTimes:      1,
+			expectedCode:     http.StatusOK,
+			expectedResponse: `{"message":"Successfully revoked the api key.","status_code":200}`,
+		},
+		{
+			name:             "Revoke api key: not found",
+			id:               keyID.String(),
+			revokeTimes:      1,
+			revokeErr:        domain.ErrAPIKeyNotfound,
+			expectedCode:     http.StatusNotFound,
+			expectedResponse: `{"type":"urn:isildur:problem:api_key_not_found","title":"api key not found","status":404,"instance":"/api/admin/api-keys/0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f","code":"api_key_not_found"}`,
+		},
+		{
+			name:             "Revoke api key: invalid id",
+			id:               "not-a-uuid",
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_request","title":"invalid request","status":400,"detail":"invalid request: invalid api key id: invalid UUID length: 10","instance":"/api/admin/api-keys/not-a-uuid","code":"invalid_request"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/api/admin/api-keys/"+tc.id, nil)
+			c.Params = gin.Params{{Key: constants.APIKeyIDKey, Value: tc.id}}
+
+			ts.keys.EXPECT().RevokeAPIKey(gomock.Any(), keyID).
+				Times(tc.revokeTimes).
+				Return(tc.revokeErr)
+
+			hndlr := NewAPIKeysHandler(ts.keys)
+			hndlr.RevokeAPIKey(c)
+			ts.Assert().EqualValues(tc.expectedCode, w.Code)
+
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Assert().Nil(err)
+			ts.Assert().EqualValues(tc.expectedResponse, string(data))
+		})
+	}
+}
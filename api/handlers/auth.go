@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+)
+
+// apiKeyHeader carries the API keys of the partners, also accepted as bearer tokens.
+const apiKeyHeader = "X-API-Key"
+
+// Authenticate authenticates the requests with a JWT or an API key, as bearer token, or an API
+// key in the X-API-Key header, and puts the principal in the request context for the services
+// to scope the data to the caller. Requests without valid credentials are rejected with 401.
+func Authenticate(verifier ports.TokenVerifier, keys ports.APIKeyService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var principal domain.Principal
+		var err error
+		header := ctx.GetHeader("Authorization")
+		token := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+		switch {
+		case ctx.GetHeader(apiKeyHeader) != "":
+			principal, err = keys.AuthenticateAPIKey(ctx, ctx.GetHeader(apiKeyHeader))
+		case !strings.HasPrefix(header, "Bearer ") || token == "":
+			err = domain.ErrUnauthenticated
+		case strings.HasPrefix(token, domain.APIKeyPrefix):
+			principal, err = keys.AuthenticateAPIKey(ctx, token)
+		default:
+			principal, err = verifier.Verify(token)
+		}
+		if err != nil {
+			// Storage failures aren't the caller's fault
+			if !errors.Is(err, domain.ErrUnauthenticated) {
+				abortWithError(ctx, err)
+				return
+			}
+			abortUnauthenticated(ctx)
+			return
+		}
+		ctx.Request = ctx.Request.WithContext(domain.ContextWithPrincipal(ctx.Request.Context(), principal))
+		ctx.Next()
+	}
+}
+
+// RequireRole rejects with 403 the requests of the principals without any of the roles, before
+// reaching the services, which also check the roles of every operation. The requests without a
+// principal, when the authentication is disabled, are let through.
+func RequireRole(roles ...domain.Role) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if principal, ok := domain.PrincipalFromContext(ctx.Request.Context()); ok && !principal.HasRole(roles...) {
+			abortWithError(ctx, domain.ErrForbidden)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// abortUnauthenticated rejects a request, without telling why the credentials were refused.
+func abortUnauthenticated(ctx *gin.Context) {
+	ctx.Header("WWW-Authenticate", `Bearer realm="isildur"`)
+	abortWithError(ctx, domain.ErrUnauthenticated)
+}
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type AuthTestSuite struct {
+	suite.Suite
+	verifier *ports.MockTokenVerifier
+	keys     *ports.MockAPIKeyService
+}
+
+func TestAuthTestSuite(t *testing.T) {
+	suite.Run(t, new(AuthTestSuite))
+}
+
+func (ts *AuthTestSuite) SetupTest() {
+	ctrl := gomock.NewController(ts.T())
+	ts.verifier = ports.NewMockTokenVerifier(ctrl)
+	ts.keys = ports.NewMockAPIKeyService(ctrl)
+}
+
+func (ts *AuthTestSuite) TestAuthenticate() {
+	tt := []struct {
+		name             string
+		authorization    string
+		apiKey           string
+		verifyTimes      int
+		verifyErr        error
+		keyTimes         int
+		keyErr           error
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:             "Valid token",
+			authorization:    "Bearer valid-token",
+			verifyTimes:      1,
+			expectedCode:     http.StatusOK,
+			expectedResponse: "customer-1",
+		},
+		{
+			name:             "Missing header",
+			expectedCode:     http.StatusUnauthorized,
+			expectedResponse: `{"type":"urn:isildur:problem:unauthenticated","title":"authentication required","status":401,"instance":"/whoami","code":"unauthenticated"}`,
+		},
+		{
+			name:             "Not a bearer token",
+			authorization:    "Basic dXNlcjpwYXNz",
+			expectedCode:     http.StatusUnauthorized,
+			expectedResponse: `{"type":"urn:isildur:problem:unauthenticated","title":"authentication required","status":401,"instance":"/whoami","code":"unauthenticated"}`,
+		},
+		{
+			name:             "Empty bearer token",
+			authorization:    "Bearer ",
+			expectedCode:     http.StatusUnauthorized,
+			expectedResponse: `{"type":"urn:isildur:problem:unauthenticated","title":"authentication required","status":401,"instance":"/whoami","code":"unauthenticated"}`,
+		},
+		{
+			name:             "API key header",
+			apiKey:           "isk_a1b2c3d4_secret",
+			keyTimes:         1,
+			expectedCode:     http.StatusOK,
+			expectedResponse: "api-key:1",
+		},
+		{
+			name:             "API key as bearer token",
+			authorization:    "Bearer isk_a1b2c3d4_secret",
+			keyTimes:         1,
+			expectedCode:     http.StatusOK,
+			expectedResponse: "api-key:1",
+		},
+		{
+			name:             "Invalid API key",
+			apiKey:           "isk_a1b2c3d4_secret",
+			keyTimes:         1,
+			keyErr:           domain.ErrUnauthenticated,
+			expectedCode:     http.StatusUnauthorized,
+			expectedResponse: `{"type":"urn:isildur:problem:unauthenticated","title":"authentication required","status":401,"instance":"/whoami","code":"unauthenticated"}`,
+		},
+		{
+			name:             "API key storage failure",
+			apiKey:           "isk_a1b2c3d4_secret",
+			keyTimes:         1,
+			keyErr:           errors.New("connection refused"),
+			expectedCode:     http.StatusInternalServerError,
+			expectedResponse: `{"type":"urn:isildur:problem:internal_error","title":"Internal Server Error","status":500,"instance":"/whoami","code":"internal_error"}`,
+		},
+		{
+			name:             "Invalid token",
+			authorization:    "Bearer valid-token",
+			verifyTimes:      1,
+			verifyErr:        domain.ErrUnauthenticated,
+			expectedCode:     http.StatusUnauthorized,
+			expectedResponse: `{"type":"urn:isildur:problem:unauthenticated","title":"authentication required","status":401,"instance":"/whoami","code":"unauthenticated"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			ts.verifier.EXPECT().Verify("valid-token").
+				Times(tc.verifyTimes).
+				Return(domain.Principal{Subject: "customer-1"}, tc.verifyErr)
+			ts.keys.EXPECT().AuthenticateAPIKey(gomock.Any(), "isk_a1b2c3d4_secret").
+				Times(tc.keyTimes).
+				Return(domain.Principal{Subject: "api-key:1"}, tc.keyErr)
+
+			r := gin.New()
+			r.Use(Authenticate(ts.verifier, ts.keys))
+			r.GET("/whoami", func(ctx *gin.Context) {
+				principal, _ := domain.PrincipalFromContext(ctx)
+				ctx.String(http.StatusOK, principal.Subject)
+			})
+
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+			if tc.apiKey != "" {
+				req.Header.Set(apiKeyHeader, tc.apiKey)
+			}
+			r.ServeHTTP(w, req)
+
+			ts.Assert().Equal(tc.expectedCode, w.Code)
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Require().Nil(err)
+			ts.Assert().Equal(tc.expectedResponse, string(data))
+			if tc.expectedCode == http.StatusUnauthorized {
+				ts.Assert().Equal(`Bearer realm="isildur"`, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func (ts *AuthTestSuite) TestRequireRole() {
+	tt := []struct {
+		name         string
+		principal    *domain.Principal
+		expectedCode int
+	}{
+		{
+			name:         "Allowed role",
+			principal:    &domain.Principal{Subject: "staff-1", Roles: []domain.Role{domain.RoleSupport}},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Customer",
+			principal:    &domain.Principal{Subject: "customer-1", Roles: []domain.Role{domain.RoleCustomer}},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:         "Authentication disabled",
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			r := gin.New()
+			r.Use(func(ctx *gin.Context) {
+				if tc.principal != nil {
+					ctx.Request = ctx.Request.WithContext(domain.ContextWithPrincipal(ctx.Request.Context(), *tc.principal))
+				}
+			}, RequireRole(domain.RoleSupport, domain.RoleAdmin))
+			r.POST("/refund", func(ctx *gin.Context) {
+				ctx.Status(http.StatusOK)
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/refund", nil))
+			ts.Assert().Equal(tc.expectedCode, w.Code)
+		})
+	}
+}
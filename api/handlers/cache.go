@@ -0,0 +1,45 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// productsCacheControl lets the clients store the products, revalidated with If-None-Match
+// before every use, the catalog changing at any time. Private as the APIs are authenticated.
+const productsCacheControl = "private, no-cache"
+
+// jsonWithETag responds v as json, along with a strong ETag hashing the body, or 304 Not Modified
+// without body when the request passes the same ETag in If-None-Match.
+func jsonWithETag(ctx *gin.Context, cacheControl string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	sum := sha256.Sum256(data)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	ctx.Header("ETag", etag)
+	ctx.Header("Cache-Control", cacheControl)
+	if etagMatches(ctx.GetHeader("If-None-Match"), etag) {
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+	ctx.Data(http.StatusOK, "application/json; charset=utf-8", data)
+}
+
+// etagMatches reports whether an If-None-Match header lists the etag, compared weakly.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// openAPISpec is the OpenAPI 3 document of every route set up by SetupRouter.
+//
+//go:embed openapi.json
+var openAPISpec []byte
+
+// docsPage renders openAPISpec with Swagger UI.
+//
+//go:embed docs.html
+var docsPage []byte
+
+// OpenAPISpec responds with the OpenAPI document of the apis.
+func OpenAPISpec(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "application/json", openAPISpec)
+}
+
+// Docs responds with the docs page of the apis.
+func Docs(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/repositories/memory"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type DocsTestSuite struct {
+	suite.Suite
+	router *gin.Engine
+	spec   map[string]interface{}
+}
+
+func TestDocsTestSuite(t *testing.T) {
+	suite.Run(t, new(DocsTestSuite))
+}
+
+func (ts *DocsTestSuite) SetupTest() {
+	ctrl := gomock.NewController(ts.T())
+	ts.router = gin.New()
+	// The verifier is never called, the docs are public
+	SetupRouter(ts.router, &config.CFG{}, memory.NewRepositories(memory.NewStore()), RouterOptions{
+		Verifier: ports.NewMockTokenVerifier(ctrl),
+	})
+	ts.spec = map[string]interface{}{}
+	ts.Require().Nil(json.Unmarshal(openAPISpec, &ts.spec))
+}
+
+// ginParam matches the parameters of gin route paths.
+var ginParam = regexp.MustCompile(`[:*]([^/]+)`)
+
+// specPath converts a gin route path to an OpenAPI path template.
+func specPath(route string) string {
+	return ginParam.ReplaceAllString(route, "{$1}")
+}
+
+func (ts *DocsTestSuite) TestEveryRouteIsDocumented() {
+	paths := ts.spec["paths"].(map[string]interface{})
+	documented := map[string]bool{}
+	for path, item := range paths {
+		for method := range item.(map[string]interface{}) {
+			if method != "parameters" {
+				documented[strings.ToUpper(method)+" "+path] = true
+			}
+		}
+	}
+
+	registered := map[string]bool{}
+	for _, route := range ts.router.Routes() {
+		operation := route.Method + " " + specPath(route.Path)
+		registered[operation] = true
+		ts.Assert().True(documented[operation], "%s is missing from openapi.json", operation)
+	}
+	for operation := range documented {
+		ts.Assert().True(registered[operation], "%s is documented but not routed", operation)
+	}
+}
+
+func (ts *DocsTestSuite) TestReferencesResolve() {
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch n := node.(type) {
+		case map[string]interface{}:
+			if ref, ok := n["$ref"].(string); ok {
+				var target interface{} = ts.spec
+				for _, name := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+					parent, _ := target.(map[string]interface{})
+					target = parent[name]
+				}
+				ts.Assert().NotNil(target, "%s doesn't resolve", ref)
+			}
+			for _, child := range n {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range n {
+				walk(child)
+			}
+		}
+	}
+	walk(ts.spec)
+}
+
+func (ts *DocsTestSuite) TestServeDocs() {
+	tt := []struct {
+		path        string
+		contentType string
+	}{
+		{path: "/api/openapi.json", contentType: "application/json"},
+		{path: "/api/docs", contentType: "text/html; charset=utf-8"},
+	}
+	for _, tc := range tt {
+		ts.Run(tc.path, func() {
+			w := httptest.NewRecorder()
+			ts.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			ts.Assert().Equal(http.StatusOK, w.Code)
+			ts.Assert().Equal(tc.contentType, w.Header().Get("Content-Type"))
+		})
+	}
+}
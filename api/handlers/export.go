@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+)
+
+const (
+	exportFormatCSV   = "csv"
+	exportFormatJSONL = "jsonl"
+
+	// exportFlushEvery is the number of rows written between two flushes to the client.
+	exportFlushEvery = 500
+)
+
+// exportCSVHeader is the header line of the csv export.
+var exportCSVHeader = []string{
+	"id", "product_id", "product_name", "product_monthly_price", "customer_id", "status",
+	"duration_in_months", "tax", "total_cost", "start_date", "end_date", "version",
+}
+
+// ExportSubscriptions streams every subscription matching the same filters as listing,
+// joined with its product, as csv or jsonl depending on the format query.
+func (h *HTTPHandler) ExportSubscriptions(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", exportFormatCSV)
+	if format != exportFormatCSV && format != exportFormatJSONL {
+		abortWithFieldError(ctx, "format", fmt.Errorf("%w %q", domain.ErrInvalidExportFormat, format))
+		return
+	}
+	filter, err := parseSubscriptionFilter(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	// The response is only started with the first row, so that errors happening before
+	// can still be reported with a proper status code.
+	var write func(domain.SubscriptionExportRow) error
+	var flush func()
+	started, rows := false, 0
+	start := func() {
+		started = true
+		contentType := "text/csv; charset=utf-8"
+		if format == exportFormatJSONL {
+			contentType = "application/x-ndjson"
+		}
+		ctx.Header("Content-Type", contentType)
+		ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="subscriptions-%s.%s"`,
+			time.Now().UTC().Format("20060102"), format))
+		ctx.Status(http.StatusOK)
+
+		if format == exportFormatJSONL {
+			enc := json.NewEncoder(ctx.Writer)
+			write = func(row domain.SubscriptionExportRow) error { return enc.Encode(row) }
+			flush = ctx.Writer.Flush
+			return
+		}
+		w := csv.NewWriter(ctx.Writer)
+		_ = w.Write(exportCSVHeader)
+		write = func(row domain.SubscriptionExportRow) error { return w.Write(csvExportRecord(row)) }
+		flush = func() {
+			w.Flush()
+			ctx.Writer.Flush()
+		}
+	}
+
+	err = h.Subs.ExportSubscriptions(ctx, filter, func(row domain.SubscriptionExportRow) error {
+		if !started {
+			start()
+		}
+		if err := write(row); err != nil {
+			return err
+		}
+		if rows++; rows%exportFlushEvery == 0 {
+			flush()
+		}
+		return nil
+	})
+	if err != nil && !started {
+		abortWithError(ctx, err)
+		return
+	}
+	if err != nil {
+		// Too late to change the status. The connection is cut, for the client to see an
+		// incomplete transfer rather than a truncated file looking complete.
+		_ = ctx.Error(err)
+		panic(http.ErrAbortHandler)
+	}
+	if !started {
+		start()
+	}
+	flush()
+}
+
+// csvExportRecord returns the csv fields of an exported row.
+func csvExportRecord(row domain.SubscriptionExportRow) []string {
+	return []string{
+		row.ID.String(),
+		row.ProductID.String(),
+		row.ProductName,
+		strconv.FormatFloat(row.ProductMonthlyPrice, 'f', 2, 64),
+		row.CustomerID,
+		row.Status.String(),
+		strconv.Itoa(int(row.DurationInMonths)),
+		strconv.FormatFloat(row.Tax, 'f', 2, 64),
+		strconv.FormatFloat(row.TotalCost, 'f', 2, 64),
+		row.StartDate.UTC().Format(time.RFC3339),
+		row.EndDate.UTC().Format(time.RFC3339),
+		strconv.Itoa(row.Version),
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+)
+
+func getExportRow() domain.SubscriptionExportRow {
+	startDate := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+	return domain.SubscriptionExportRow{
+		ID:                  uuid.MustParse("0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f"),
+		ProductID:           uuid.MustParse("56f79fee-0cb0-4e87-9bca-7b5811cca4ce"),
+		ProductName:         "YOGA L1",
+		ProductMonthlyPrice: 5,
+		CustomerID:          "customer-1",
+		DurationInMonths:    3,
+		Tax:                 1.05,
+		TotalCost:           16.05,
+		Status:              domain.SubscriptionStatusActive,
+		StartDate:           startDate,
+		EndDate:             startDate.AddDate(0, 3, 0),
+		Version:             1,
+	}
+}
+
+func (ts *HttpTestSuite) TestHttpHandlers_ExportSubscriptions() {
+	productID := uuid.MustParse("56f79fee-0cb0-4e87-9bca-7b5811cca4ce")
+
+	tt := []struct {
+		name                string
+		query               string
+		filter              domain.SubscriptionFilter
+		exportTimes         int
+		exportErr           error
+		expectedCode        int
+		expectedContentType string
+		expectedResponse    string
+	}{
+		{
+			name:                "Export csv",
+			query:               "status=active&product_id=" + productID.String(),
+			filter:              domain.SubscriptionFilter{Status: domain.SubscriptionStatusActive, ProductID: productID},
+			exportTimes:         1,
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedResponse: "id,product_id,product_name,product_monthly_price,customer_id,status,duration_in_months,tax,total_cost,start_date,end_date,version\n" +
+				"0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f,56f79fee-0cb0-4e87-9bca-7b5811cca4ce,YOGA L1,5.00,customer-1,active,3,1.05,16.05,2022-06-01T00:00:00Z,2022-09-01T00:00:00Z,1\n",
+		},
+		{
+			name:                "Export jsonl",
+			query:               "format=jsonl",
+			exportTimes:         1,
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/x-ndjson",
+			expectedResponse: `{"id":"0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f","product_id":"56f79fee-0cb0-4e87-9bca-7b5811cca4ce",` +
+				`"product_name":"YOGA L1","product_monthly_price":5,"customer_id":"customer-1","duration_in_months":3,` +
+				`"tax":1.05,"total_cost":16.05,"status":"active","start_date":"2022-06-01T00:00:00Z",` +
+				`"end_date":"2022-09-01T00:00:00Z","version":1}` + "\n",
+		},
+		{
+			name:             "Export: invalid format",
+			query:            "format=xml",
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_export_format","title":"invalid export format","status":400,"detail":"invalid export format \"xml\"","instance":"/api/subscription/export","code":"invalid_export_format","errors":[{"field":"format","code":"invalid_export_format","detail":"invalid export format \"xml\""}]}`,
+		},
+		{
+			name:             "Export: invalid product id",
+			query:            "product_id=1234",
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_product_id","title":"invalid product id","status":400,"instance":"/api/subscription/export","code":"invalid_product_id"}`,
+		},
+		{
+			name:             "Export: failure before the first row",
+			exportTimes:      1,
+			exportErr:        errors.New("connection refused"),
+			expectedCode:     http.StatusInternalServerError,
+			expectedResponse: `{"type":"urn:isildur:problem:internal_error","title":"Internal Server Error","status":500,"instance":"/api/subscription/export","code":"internal_error"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/subscription/export?"+tc.query, nil)
+
+			ts.subsSvc.EXPECT().ExportSubscriptions(gomock.Any(), tc.filter, gomock.Any()).
+				Times(tc.exportTimes).
+				DoAndReturn(func(_ interface{}, _ domain.SubscriptionFilter, fn func(domain.SubscriptionExportRow) error) error {
+					if tc.exportErr != nil {
+						return tc.exportErr
+					}
+					return fn(getExportRow())
+				})
+
+			hndlr := NewHTTPHandler(ts.subsSvc, ts.prodSvc)
+			hndlr.ExportSubscriptions(c)
+			ts.Assert().EqualValues(tc.expectedCode, w.Code)
+			if tc.expectedContentType != "" {
+				ts.Assert().Equal(tc.expectedContentType, w.Header().Get("Content-Type"))
+				ts.Assert().True(strings.HasPrefix(w.Header().Get("Content-Disposition"), `attachment; filename="subscriptions-`))
+			}
+
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Assert().Nil(err)
+			ts.Assert().Equal(tc.expectedResponse, string(data))
+		})
+	}
+}
+
+func (ts *HttpTestSuite) TestHttpHandlers_ExportSubscriptions_FailureWhileStreaming() {
+	tt := []struct {
+		name string
+		rows int
+	}{
+		{name: "After the first row", rows: 1},
+		// The headers and the first rows were already sent
+		{name: "After a flush", rows: exportFlushEvery + 1},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			ts.subsSvc.EXPECT().ExportSubscriptions(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ interface{}, _ domain.SubscriptionFilter, fn func(domain.SubscriptionExportRow) error) error {
+					for i := 0; i < tc.rows; i++ {
+						if err := fn(getExportRow()); err != nil {
+							return err
+						}
+					}
+					return errors.New("connection reset")
+				})
+
+			logs := &bytes.Buffer{}
+			r := gin.New()
+			r.Use(Recovery(logger.New(logger.LevelRelease, logs)))
+			hndlr := NewHTTPHandler(ts.subsSvc, ts.prodSvc)
+			r.GET("/api/subscription/export", hndlr.ExportSubscriptions)
+			server := httptest.NewServer(r)
+			defer server.Close()
+
+			// The client sees an incomplete transfer, not a complete looking file
+			resp, err := http.Get(server.URL + "/api/subscription/export")
+			if err == nil {
+				defer resp.Body.Close()
+				ts.Assert().Equal(http.StatusOK, resp.StatusCode)
+				_, err = io.ReadAll(resp.Body)
+			}
+			ts.Assert().NotNil(err)
+
+			entry := map[string]interface{}{}
+			ts.Require().Nil(json.Unmarshal(logs.Bytes(), &entry))
+			ts.Assert().Equal("request aborted", entry["message"])
+			ts.Assert().Equal("connection reset", entry["error"])
+		})
+	}
+}
+
+func (ts *HttpTestSuite) TestHttpHandlers_ListSubscriptions() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/subscription/?status=paused&limit=10&offset=5", nil)
+
+	ts.subsSvc.EXPECT().ListSubscriptions(gomock.Any(), domain.SubscriptionFilter{
+		Status: domain.SubscriptionStatusPaused,
+		Limit:  10,
+		Offset: 5,
+	}).Times(1).Return([]domain.Subscription{}, nil)
+
+	hndlr := NewHTTPHandler(ts.subsSvc, ts.prodSvc)
+	hndlr.ListSubscriptions(c)
+	ts.Assert().EqualValues(http.StatusOK, w.Code)
+
+	data, err := io.ReadAll(w.Result().Body)
+	ts.Assert().Nil(err)
+	ts.Assert().Equal("[]", string(data))
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessCheckTimeout bounds every readiness check, not to hang the probes of the orchestrator.
+const readinessCheckTimeout = 2 * time.Second
+
+// ReadinessCheck reports whether a dependency the requests need is available, e.g. pings the
+// database.
+type ReadinessCheck func(ctx context.Context) error
+
+// Health serves the liveness and readiness probes.
+type Health struct {
+	mu       sync.RWMutex
+	checks   map[string]ReadinessCheck
+	draining int32
+}
+
+// NewHealth returns a Health ready as long as its checks pass.
+func NewHealth() *Health {
+	return &Health{
+		checks: map[string]ReadinessCheck{},
+	}
+}
+
+// AddCheck adds a check to the readiness of the service.
+func (h *Health) AddCheck(name string, check ReadinessCheck) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks[name] = check
+}
+
+// Drain reports the service as not ready from now on, for the load balancers to stop sending
+// it requests while the requests in flight complete.
+func (h *Health) Drain() {
+	atomic.StoreInt32(&h.draining, 1)
+}
+
+// Live responds as long as the service is serving requests.
+func (h *Health) Live(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, HealthResponse{Status: "ok"})
+}
+
+// Ready runs every check, responding with 503 and the failed checks unless they all pass.
+func (h *Health) Ready(ctx *gin.Context) {
+	if atomic.LoadInt32(&h.draining) == 1 {
+		ctx.JSON(http.StatusServiceUnavailable, HealthResponse{Status: "draining"})
+		return
+	}
+
+	h.mu.RLock()
+	checks := make(map[string]ReadinessCheck, len(h.checks))
+	names := make([]string, 0, len(h.checks))
+	for name, check := range h.checks {
+		checks[name] = check
+		names = append(names, name)
+	}
+	h.mu.RUnlock()
+	sort.Strings(names)
+
+	res := HealthResponse{Status: "ok", Checks: map[string]string{}}
+	status := http.StatusOK
+	for _, name := range names {
+		checkCtx, cancel := context.WithTimeout(ctx.Request.Context(), readinessCheckTimeout)
+		err := checks[name](checkCtx)
+		cancel()
+		if err != nil {
+			res.Status = "unavailable"
+			res.Checks[name] = err.Error()
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		res.Checks[name] = "ok"
+	}
+	ctx.JSON(status, res)
+}
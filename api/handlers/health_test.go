@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/goakshit/isildur/repositories/memory"
+	"github.com/stretchr/testify/suite"
+)
+
+type HealthTestSuite struct {
+	suite.Suite
+	health *Health
+	logs   *bytes.Buffer
+	router *gin.Engine
+}
+
+func TestHealthTestSuite(t *testing.T) {
+	suite.Run(t, new(HealthTestSuite))
+}
+
+func (ts *HealthTestSuite) SetupTest() {
+	ts.health = NewHealth()
+	ts.logs = &bytes.Buffer{}
+	ts.router = gin.New()
+	SetupRouter(ts.router, &config.CFG{}, memory.NewRepositories(memory.NewStore()), RouterOptions{
+		Health: ts.health,
+		Logger: logger.New(logger.LevelRelease, ts.logs),
+	})
+}
+
+func (ts *HealthTestSuite) serve(path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func (ts *HealthTestSuite) TestLive() {
+	w := ts.serve("/healthz")
+	ts.Assert().Equal(http.StatusOK, w.Code)
+	ts.Assert().JSONEq(`{"status":"ok"}`, w.Body.String())
+	// The probes aren't logged
+	ts.Assert().Empty(ts.logs.String())
+}
+
+func (ts *HealthTestSuite) TestReady() {
+	tt := []struct {
+		name             string
+		databaseErr      error
+		drain            bool
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:             "Ready",
+			expectedCode:     http.StatusOK,
+			expectedResponse: `{"status":"ok","checks":{"database":"ok","workers":"ok"}}`,
+		},
+		{
+			name:             "DatabaseDown",
+			databaseErr:      errors.New("connection refused"),
+			expectedCode:     http.StatusServiceUnavailable,
+			expectedResponse: `{"status":"unavailable","checks":{"database":"connection refused","workers":"ok"}}`,
+		},
+		{
+			name:             "Draining",
+			drain:            true,
+			expectedCode:     http.StatusServiceUnavailable,
+			expectedResponse: `{"status":"draining"}`,
+		},
+	}
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			ts.SetupTest()
+			ts.health.AddCheck("database", func(ctx context.Context) error {
+				ts.Assert().NotNil(ctx.Done(), "checks are bounded")
+				return tc.databaseErr
+			})
+			ts.health.AddCheck("workers", func(context.Context) error { return nil })
+			if tc.drain {
+				ts.health.Drain()
+			}
+
+			w := ts.serve("/readyz")
+			ts.Assert().Equal(tc.expectedCode, w.Code)
+			ts.Assert().JSONEq(tc.expectedResponse, w.Body.String())
+		})
+	}
+}
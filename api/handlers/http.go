@@ -3,8 +3,10 @@
 package handlers
 
 import (
-	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/asaskevich/govalidator"
@@ -12,6 +14,7 @@ import (
 	"github.com/goakshit/isildur/core/domain"
 	"github.com/goakshit/isildur/core/ports"
 	"github.com/goakshit/isildur/platform/constants"
+	"github.com/goakshit/isildur/services"
 	"github.com/google/uuid"
 )
 
@@ -35,45 +38,32 @@ func NewHTTPHandler(
 // CreateSubscription creates a subscription for a given product.
 func (h *HTTPHandler) CreateSubscription(ctx *gin.Context) {
 	r := CreateSubscriptionRequest{}
-	if err := ctx.BindJSON(&r); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
-			StatusCode: http.StatusBadRequest,
-			Error:      err.Error(),
-		})
+	if err := ctx.ShouldBindJSON(&r); err != nil {
+		abortWithInvalidRequest(ctx, err)
 		return
 	}
 
 	// Validate the request data
 	if _, err := govalidator.ValidateStruct(r); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
-			StatusCode: http.StatusBadRequest,
-			Error:      err.Error(),
-		})
+		abortWithInvalidRequest(ctx, err)
 		return
 	}
 
 	pid, err := uuid.Parse(r.ProductID)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
-			StatusCode: http.StatusBadRequest,
-			Error:      err.Error(),
-		})
+		abortWithFieldError(ctx, "product_id", domain.ErrProductIDIsInvalid)
 		return
 	}
 
 	// Parsing the start date.
 	date, err := time.Parse(constants.DateFormat, r.StartDate)
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
-			StatusCode: http.StatusBadRequest,
-			Error:      domain.ErrInvalidStartDate.Error(),
-		})
+		abortWithFieldError(ctx, "start_date", domain.ErrInvalidStartDate)
 		return
 	}
 
 	if err = h.Subs.CreateSubscription(ctx, pid, r.DurationInMonths, date); err != nil {
-		errResp := mapErrorResponseFromError(err)
-		ctx.AbortWithStatusJSON(errResp.StatusCode, errResp)
+		abortWithError(ctx, err)
 		return
 	}
 	ctx.JSON(http.StatusOK, gin.H{
@@ -82,119 +72,178 @@ func (h *HTTPHandler) CreateSubscription(ctx *gin.Context) {
 	})
 }
 
-// FetchAllProducts fetches all the available products.
+// FetchAllProducts fetches a page of the available products, searched, filtered and sorted by
+// the query parameters, see services.ParseProductQuery and jsonWithETag. A full page links to
+// the next one in the Link header.
 func (h *HTTPHandler) FetchAllProducts(ctx *gin.Context) {
-	products, err := h.Products.FetchAllProducts(ctx)
+	query, err := services.ParseProductQuery(ctx.Request.URL.Query())
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, ErrorResponse{
-			StatusCode: http.StatusInternalServerError,
-			Error:      err.Error(),
-		})
+		abortWithError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, products)
+	products, err := h.Products.SearchProducts(ctx, query)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	if len(products) == query.Limit {
+		next := ctx.Request.URL.Query()
+		next.Set("offset", strconv.Itoa(query.Offset+query.Limit))
+		ctx.Header("Link", fmt.Sprintf(`<%s?%s>; rel="next"`, ctx.Request.URL.Path, next.Encode()))
+	}
+	jsonWithETag(ctx, productsCacheControl, products)
 }
 
 // FetchProduct fetches all the available products.
 func (h *HTTPHandler) FetchProduct(ctx *gin.Context) {
 	pID, err := uuid.Parse(ctx.Param(constants.ProductIDKey))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
-			StatusCode: http.StatusBadRequest,
-			Error:      err.Error(),
-		})
+		abortWithError(ctx, fmt.Errorf("%w: %v", domain.ErrProductIDIsInvalid, err))
 		return
 	}
 	product, err := h.Products.FetchProduct(ctx, pID)
 	if err != nil {
-		errResp := mapErrorResponseFromError(err)
-		ctx.AbortWithStatusJSON(errResp.StatusCode, errResp)
+		abortWithError(ctx, err)
 		return
 	}
-	ctx.JSON(http.StatusOK, product)
+	jsonWithETag(ctx, productsCacheControl, product)
 }
 
 // FetchSubscription fetches subscription details for given id.
 func (h *HTTPHandler) FetchSubscription(ctx *gin.Context) {
 	sID, err := uuid.Parse(ctx.Param(constants.SubscriptionIDKey))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
-			StatusCode: http.StatusBadRequest,
-			Error:      err.Error(),
-		})
+		abortWithError(ctx, fmt.Errorf("%w: %v", domain.ErrSubscriptionIDIsInvalid, err))
 		return
 	}
 	subscription, err := h.Subs.FetchSubscription(ctx, sID)
 	if err != nil {
-		errResp := mapErrorResponseFromError(err)
-		ctx.AbortWithStatusJSON(errResp.StatusCode, errResp)
+		abortWithError(ctx, err)
 		return
 	}
+	ctx.Header("ETag", formatETag(subscription.Version))
 	ctx.JSON(http.StatusOK, subscription)
 }
 
+// ListSubscriptions lists a page of subscriptions, filtered by the status and product_id queries
+// and paginated by the limit and offset queries.
+func (h *HTTPHandler) ListSubscriptions(ctx *gin.Context) {
+	filter, err := parseSubscriptionFilter(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	subscriptions, err := h.Subs.ListSubscriptions(ctx, filter)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, subscriptions)
+}
+
 // UpdateSubscriptionStatus updates the state of subscription for a given id.
+// The request must carry the subscription ETag in the If-Match header.
 func (h *HTTPHandler) UpdateSubscriptionStatus(ctx *gin.Context) {
 	sID, err := uuid.Parse(ctx.Param(constants.SubscriptionIDKey))
 	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
-			StatusCode: http.StatusBadRequest,
-			Error:      err.Error(),
-		})
+		abortWithError(ctx, fmt.Errorf("%w: %v", domain.ErrSubscriptionIDIsInvalid, err))
 		return
 	}
 
 	updateStatus := ctx.Query("status")
 	if updateStatus == "" || domain.MapStringToSubscriptionStatus(updateStatus) == "" {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, ErrorResponse{
-			StatusCode: http.StatusBadRequest,
-			Error:      domain.ErrInvalidSubscriptionStatusPassed.Error(),
-		})
+		abortWithFieldError(ctx, "status", domain.ErrInvalidSubscriptionStatusPassed)
 		return
 	}
 
-	subscription, err := h.Subs.FetchSubscription(ctx, sID)
+	version, err := parseETag(ctx.GetHeader("If-Match"))
 	if err != nil {
-		errResp := mapErrorResponseFromError(err)
-		ctx.AbortWithStatusJSON(errResp.StatusCode, errResp)
+		abortWithError(ctx, err)
 		return
 	}
 
-	// Error handling: Don't allow subscription to be active if it is cancelled
-	if subscription.Status == domain.SubscriptionStatusCancel {
-		errResp := mapErrorResponseFromError(domain.ErrCannotUpdateCancelledSubscription)
-		ctx.AbortWithStatusJSON(errResp.StatusCode, errResp)
-		return
-	}
-
-	if err = h.Subs.UpdateSubscriptionStatus(ctx, sID, domain.MapStringToSubscriptionStatus(updateStatus)); err != nil {
-		errResp := mapErrorResponseFromError(err)
-		ctx.AbortWithStatusJSON(errResp.StatusCode, errResp)
+	if err = h.Subs.UpdateSubscriptionStatus(ctx, sID, version, domain.MapStringToSubscriptionStatus(updateStatus)); err != nil {
+		abortWithError(ctx, err)
 		return
 	}
+	ctx.Header("ETag", formatETag(version+1))
 	ctx.JSON(http.StatusOK, gin.H{
 		"status_code": http.StatusOK,
 		"message":     "Successfully updated the subscription status.",
 	})
 }
 
+// statusByKind maps the kinds of the domain errors to the http status codes.
+var statusByKind = map[domain.ErrorKind]int{
+	domain.KindNotFound:           http.StatusNotFound,
+	domain.KindInvalid:            http.StatusBadRequest,
+	domain.KindFailedPrecondition: http.StatusBadRequest,
+	domain.KindVersionRequired:    http.StatusPreconditionRequired,
+	domain.KindConflict:           http.StatusPreconditionFailed,
+	domain.KindUnauthenticated:    http.StatusUnauthorized,
+	domain.KindForbidden:          http.StatusForbidden,
+	domain.KindRateLimited:        http.StatusTooManyRequests,
+}
+
+// mapErrorResponseFromError returns the problem details of an error, with the status code of
+// the domain error it wraps.
 func mapErrorResponseFromError(err error) ErrorResponse {
-	resp := ErrorResponse{
-		Error:      err.Error(),
-		StatusCode: http.StatusInternalServerError,
+	status, ok := statusByKind[domain.KindOf(err)]
+	if !ok {
+		status = http.StatusInternalServerError
 	}
-	if errors.Is(err, domain.ErrProductNotfound) ||
-		errors.Is(err, domain.ErrSubscriptionNotfound) {
-
-		resp.StatusCode = http.StatusNotFound
+	return newProblem(status, err)
+}
 
-	} else if errors.Is(err, domain.ErrProductIDIsInvalid) ||
-		errors.Is(err, domain.ErrInvalidStartDate) ||
-		errors.Is(err, domain.ErrSubscriptionIDIsInvalid) ||
-		errors.Is(err, domain.ErrCannotUpdateCancelledSubscription) {
+// parseSubscriptionFilter parses the subscriptions filter from the query parameters.
+func parseSubscriptionFilter(ctx *gin.Context) (domain.SubscriptionFilter, error) {
+	filter := domain.SubscriptionFilter{
+		Status: domain.SubscriptionStatus(ctx.Query("status")),
+	}
+	if productID := ctx.Query("product_id"); productID != "" {
+		pID, err := uuid.Parse(productID)
+		if err != nil {
+			return filter, domain.ErrProductIDIsInvalid
+		}
+		filter.ProductID = pID
+	}
+	var err error
+	if limit := ctx.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			return filter, domain.ErrInvalidPagination
+		}
+	}
+	if offset := ctx.Query("offset"); offset != "" {
+		if filter.Offset, err = strconv.Atoi(offset); err != nil {
+			return filter, domain.ErrInvalidPagination
+		}
+	}
+	return filter, nil
+}
 
-		resp.StatusCode = http.StatusBadRequest
+// formatETag returns the strong entity tag for a given subscription version.
+func formatETag(version int) string {
+	return fmt.Sprintf("%q", strconv.Itoa(version))
+}
 
+// parseETag extracts the subscription version from an If-Match header value. The wildcard
+// doesn't tell the version the update is based on, and the weak or malformed tags aren't the
+// ETag of a subscription.
+func parseETag(header string) (int, error) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, domain.ErrSubscriptionVersionRequired
+	}
+	if header == "*" {
+		return 0, fmt.Errorf("%w: If-Match must be the ETag of the subscription, not *", domain.ErrSubscriptionVersionRequired)
+	}
+	tag, err := strconv.Unquote(header)
+	if err != nil {
+		return 0, fmt.Errorf("%w: If-Match must be a single strong ETag", domain.ErrInvalidRequest)
+	}
+	version, err := strconv.Atoi(tag)
+	if err != nil || version <= 0 {
+		return 0, domain.ErrSubscriptionVersionMismatch
 	}
-	return resp
+	return version, nil
 }
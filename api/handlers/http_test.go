@@ -45,33 +45,118 @@ func getProduct() domain.Product {
 }
 
 func (ts *HttpTestSuite) TestHttpHandlers_FetchAllProducts() {
-	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
+	expectedProducts := []domain.Product{
+		getProduct(),
+	}
+	expectedProductsBytes, _ := json.Marshal(expectedProducts)
 
-	c.Request = &http.Request{
-		Header: make(http.Header),
+	tt := []struct {
+		name         string
+		target       string
+		query        domain.ProductQuery
+		expectedLink string
+	}{
+		{
+			name:   "Default page",
+			target: "/api/products/",
+			query:  domain.ProductQuery{Limit: constants.DefaultPageSize},
+		},
+		{
+			name:   "Searched",
+			target: "/api/products/?q=yoga&instructor=A.+Dhar&max_price=10&sort=-price&limit=5",
+			query: domain.ProductQuery{
+				Search:     "yoga",
+				Instructor: "A. Dhar",
+				MaxPrice:   10,
+				Sort:       domain.ProductSortPrice,
+				Descending: true,
+				Limit:      5,
+			},
+		},
+		{
+			name:         "Full page",
+			target:       "/api/products/?q=yoga&limit=1&offset=2",
+			query:        domain.ProductQuery{Search: "yoga", Limit: 1, Offset: 2},
+			expectedLink: `</api/products/?limit=1&offset=3&q=yoga>; rel="next"`,
+		},
 	}
 
-	c.Request.Method = "GET"
-	c.Request.Header.Set("Content-Type", "application/json")
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, tc.target, nil)
+			c.Request.Header.Set("Content-Type", "application/json")
 
-	expectedProducts := []domain.Product{
-		getProduct(),
-	}
+			ts.prodSvc.EXPECT().SearchProducts(gomock.Any(), tc.query).
+				Times(1).
+				Return(expectedProducts, nil)
 
-	expectedProductsBytes, _ := json.Marshal(expectedProducts)
+			hndlr := NewHTTPHandler(ts.subsSvc, ts.prodSvc)
+			hndlr.FetchAllProducts(c)
+			ts.Assert().EqualValues(http.StatusOK, w.Code)
+			ts.Assert().Equal(tc.expectedLink, w.Header().Get("Link"))
 
-	ts.prodSvc.EXPECT().FetchAllProducts(gomock.Any()).
-		Times(1).
-		Return(expectedProducts, nil)
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Assert().Nil(err)
+			ts.Assert().EqualValues(data, expectedProductsBytes)
+		})
+	}
+}
+
+func (ts *HttpTestSuite) TestHttpHandlers_FetchAllProducts_InvalidQuery() {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/products/?sort=rating", nil)
 
 	hndlr := NewHTTPHandler(ts.subsSvc, ts.prodSvc)
 	hndlr.FetchAllProducts(c)
-	ts.Assert().EqualValues(http.StatusOK, w.Code)
+	ts.Assert().EqualValues(http.StatusBadRequest, w.Code)
+	ts.Assert().Contains(w.Body.String(), `"code":"invalid_query"`)
+}
 
-	data, err := io.ReadAll(w.Result().Body)
-	ts.Assert().Nil(err)
-	ts.Assert().EqualValues(data, expectedProductsBytes)
+func (ts *HttpTestSuite) TestHttpHandlers_FetchAllProducts_ETag() {
+	products := []domain.Product{getProduct()}
+	ts.prodSvc.EXPECT().SearchProducts(gomock.Any(), gomock.Any()).Times(4).Return(products, nil)
+	hndlr := NewHTTPHandler(ts.subsSvc, ts.prodSvc)
+	r := gin.New()
+	r.GET("/api/products/", hndlr.FetchAllProducts)
+
+	fetch := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/products/", nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := fetch("")
+	ts.Require().Equal(http.StatusOK, w.Code)
+	etag := w.Header().Get("ETag")
+	ts.Assert().Regexp(`^"[0-9a-f]{32}"$`, etag)
+	ts.Assert().Equal("private, no-cache", w.Header().Get("Cache-Control"))
+
+	tt := []struct {
+		name         string
+		ifNoneMatch  string
+		expectedCode int
+	}{
+		{name: "Unchanged", ifNoneMatch: etag, expectedCode: http.StatusNotModified},
+		{name: "Weak and listed", ifNoneMatch: `"other", W/` + etag, expectedCode: http.StatusNotModified},
+		{name: "Changed", ifNoneMatch: `"other"`, expectedCode: http.StatusOK},
+	}
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := fetch(tc.ifNoneMatch)
+			ts.Assert().Equal(tc.expectedCode, w.Code)
+			ts.Assert().Equal(etag, w.Header().Get("ETag"))
+			if tc.expectedCode == http.StatusNotModified {
+				ts.Assert().Empty(w.Body.String())
+			}
+		})
+	}
 }
 
 func (ts *HttpTestSuite) TestHttpHandlers_FetchProductSuccess() {
@@ -117,7 +202,7 @@ func (ts *HttpTestSuite) TestHttpHandlers_FetchProductNotFound() {
 	c.Request.Header.Set("Content-Type", "application/json")
 	c.AddParam(constants.ProductIDKey, productID.String())
 
-	expectedResponse := []byte(`{"status_code":404,"error":"product not found"}`)
+	expectedResponse := []byte(`{"type":"urn:isildur:problem:product_not_found","title":"product not found","status":404,"code":"product_not_found"}`)
 
 	ts.prodSvc.EXPECT().FetchProduct(gomock.Any(), productID).
 		Times(1).
@@ -133,9 +218,6 @@ func (ts *HttpTestSuite) TestHttpHandlers_FetchProductNotFound() {
 }
 
 func (ts *HttpTestSuite) TestHttpHandlers_UpdateSubscription() {
-	w := httptest.NewRecorder()
-	c, _ := gin.CreateTestContext(w)
-
 	productID, subsID := uuid.New(), uuid.New()
 	subscription := domain.Subscription{
 		ID:               uuid.New(),
@@ -146,12 +228,7 @@ func (ts *HttpTestSuite) TestHttpHandlers_UpdateSubscription() {
 		Status:           domain.SubscriptionStatusInactive,
 		StartDate:        time.Now(),
 		EndDate:          time.Now().AddDate(0, 3, 0),
-	}
-
-	type fetchSubscriptionMock struct {
-		timesToCall int
-		retSubs     domain.Subscription
-		retErr      error
+		Version:          2,
 	}
 
 	type updateSubscriptionStatusMock struct {
@@ -163,29 +240,96 @@ func (ts *HttpTestSuite) TestHttpHandlers_UpdateSubscription() {
 		name             string
 		subscriptionID   uuid.UUID
 		status           domain.SubscriptionStatus
+		ifMatch          string
+		expectedCode     int
+		expectedETag     string
 		expectedResponse []byte
-		fsmock           fetchSubscriptionMock
 		usmock           updateSubscriptionStatusMock
 	}{
 		{
 			name:             "Update Subscription status success",
 			subscriptionID:   subsID,
 			status:           domain.SubscriptionStatusActive,
+			ifMatch:          `"2"`,
+			expectedCode:     http.StatusOK,
+			expectedETag:     `"3"`,
 			expectedResponse: []byte(`{"message":"Successfully updated the subscription status.","status_code":200}`),
-			fsmock: fetchSubscriptionMock{
+			usmock: updateSubscriptionStatusMock{
 				timesToCall: 1,
-				retSubs:     subscription,
 				retErr:      nil,
 			},
+		},
+		{
+			name:             "Update Subscription status: missing If-Match",
+			subscriptionID:   subsID,
+			status:           domain.SubscriptionStatusActive,
+			ifMatch:          "",
+			expectedCode:     http.StatusPreconditionRequired,
+			expectedResponse: []byte(`{"type":"urn:isildur:problem:subscription_version_required","title":"subscription version is required","status":428,"code":"subscription_version_required"}`),
+		},
+		{
+			name:             "Update Subscription status: wildcard If-Match",
+			subscriptionID:   subsID,
+			status:           domain.SubscriptionStatusActive,
+			ifMatch:          "*",
+			expectedCode:     http.StatusPreconditionRequired,
+			expectedResponse: []byte(`{"type":"urn:isildur:problem:subscription_version_required","title":"subscription version is required","status":428,"detail":"subscription version is required: If-Match must be the ETag of the subscription, not *","code":"subscription_version_required"}`),
+		},
+		{
+			name:             "Update Subscription status: weak If-Match",
+			subscriptionID:   subsID,
+			status:           domain.SubscriptionStatusActive,
+			ifMatch:          `W/"2"`,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: []byte(`{"type":"urn:isildur:problem:invalid_request","title":"invalid request","status":400,"detail":"invalid request: If-Match must be a single strong ETag","code":"invalid_request"}`),
+		},
+		{
+			name:             "Update Subscription status: malformed If-Match",
+			subscriptionID:   subsID,
+			status:           domain.SubscriptionStatusActive,
+			ifMatch:          `"2", "3"`,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: []byte(`{"type":"urn:isildur:problem:invalid_request","title":"invalid request","status":400,"detail":"invalid request: If-Match must be a single strong ETag","code":"invalid_request"}`),
+		},
+		{
+			name:             "Update Subscription status: unknown version",
+			subscriptionID:   subsID,
+			status:           domain.SubscriptionStatusActive,
+			ifMatch:          `"abc"`,
+			expectedCode:     http.StatusPreconditionFailed,
+			expectedResponse: []byte(`{"type":"urn:isildur:problem:subscription_version_mismatch","title":"subscription has been modified","status":412,"code":"subscription_version_mismatch"}`),
+		},
+		{
+			name:             "Update Subscription status: stale If-Match",
+			subscriptionID:   subsID,
+			status:           domain.SubscriptionStatusPaused,
+			ifMatch:          `"2"`,
+			expectedCode:     http.StatusPreconditionFailed,
+			expectedResponse: []byte(`{"type":"urn:isildur:problem:subscription_version_mismatch","title":"subscription has been modified","status":412,"code":"subscription_version_mismatch"}`),
+			usmock: updateSubscriptionStatusMock{
+				timesToCall: 1,
+				retErr:      domain.ErrSubscriptionVersionMismatch,
+			},
+		},
+		{
+			name:             "Update Subscription status: cancelled subscription",
+			subscriptionID:   subsID,
+			status:           domain.SubscriptionStatusActive,
+			ifMatch:          `"2"`,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: []byte(`{"type":"urn:isildur:problem:subscription_cancelled","title":"cannot update cancelled subsciption","status":400,"code":"subscription_cancelled"}`),
 			usmock: updateSubscriptionStatusMock{
 				timesToCall: 1,
-				retErr:      nil,
+				retErr:      domain.ErrCannotUpdateCancelledSubscription,
 			},
 		},
 	}
 
 	for _, tc := range tt {
 		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
 			r := &http.Request{
 				Header: make(http.Header),
 				URL: &url.URL{
@@ -194,18 +338,19 @@ func (ts *HttpTestSuite) TestHttpHandlers_UpdateSubscription() {
 			}
 			r.Method = "PATCH"
 			r.Header.Set("Content-Type", "application/json")
+			if tc.ifMatch != "" {
+				r.Header.Set("If-Match", tc.ifMatch)
+			}
 			c.AddParam(constants.SubscriptionIDKey, subsID.String())
 			c.Request = r
-			ts.subsSvc.EXPECT().FetchSubscription(gomock.Any(), tc.subscriptionID).
-				Times(tc.fsmock.timesToCall).
-				Return(tc.fsmock.retSubs, tc.fsmock.retErr)
-
-			ts.subsSvc.EXPECT().UpdateSubscriptionStatus(gomock.Any(), tc.subscriptionID, tc.status).
+			ts.subsSvc.EXPECT().UpdateSubscriptionStatus(gomock.Any(), tc.subscriptionID, subscription.Version, tc.status).
 				Times(tc.usmock.timesToCall).
 				Return(tc.usmock.retErr)
 
 			hndlr := NewHTTPHandler(ts.subsSvc, ts.prodSvc)
 			hndlr.UpdateSubscriptionStatus(c)
+			ts.Assert().EqualValues(tc.expectedCode, w.Code)
+			ts.Assert().EqualValues(tc.expectedETag, w.Header().Get("ETag"))
 
 			data, err := io.ReadAll(w.Result().Body)
 			ts.Assert().Nil(err)
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+)
+
+// requestIDHeader carries the id correlating the logs of a request, generated unless the client
+// or a proxy in front of the service passed one.
+const requestIDHeader = "X-Request-ID"
+
+// RequestID puts the id of the request in its context, for the services and the repositories to
+// log it, and in the response headers.
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := domain.NewRequestID(ctx.GetHeader(requestIDHeader))
+		ctx.Header(requestIDHeader, id)
+		ctx.Request = ctx.Request.WithContext(domain.ContextWithRequestID(ctx.Request.Context(), id))
+		ctx.Next()
+	}
+}
+
+// AccessLog logs every request once served, with its status and duration. The server errors are
+// logged as errors, with their cause.
+func AccessLog(log ports.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		fields := []interface{}{
+			"method", ctx.Request.Method,
+			"path", ctx.Request.URL.Path,
+			"status", status,
+			"duration_ms", time.Since(start),
+			"client_ip", ctx.ClientIP(),
+		}
+		if err := ctx.Errors.Last(); err != nil {
+			fields = append(fields, "error", err.Err)
+		}
+		if status >= http.StatusInternalServerError {
+			log.Error(ctx, "request failed", fields...)
+			return
+		}
+		log.Info(ctx, "request served", fields...)
+	}
+}
+
+// Recovery turns the panics of the handlers into internal errors, logged with their stack. The
+// handlers aborting their response with http.ErrAbortHandler are let through, for the server to
+// cut the connection.
+func Recovery(log ports.Logger) gin.HandlerFunc {
+	return gin.CustomRecoveryWithWriter(io.Discard, func(ctx *gin.Context, recovered interface{}) {
+		if recovered == http.ErrAbortHandler {
+			// Not logged by the access log either, the panic going through it
+			fields := []interface{}{"method", ctx.Request.Method, "path", ctx.Request.URL.Path}
+			if err := ctx.Errors.Last(); err != nil {
+				fields = append(fields, "error", err.Err)
+			}
+			log.Error(ctx, "request aborted", fields...)
+			panic(recovered)
+		}
+		log.Error(ctx, "panic recovered", "panic", recovered, "stack", string(debug.Stack()))
+		// The panic isn't the caller's business
+		abortWithError(ctx, errors.New(http.StatusText(http.StatusInternalServerError)))
+	})
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type LoggingTestSuite struct {
+	suite.Suite
+	logs *bytes.Buffer
+	r    *gin.Engine
+}
+
+func TestLoggingTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggingTestSuite))
+}
+
+func (ts *LoggingTestSuite) SetupTest() {
+	ts.logs = &bytes.Buffer{}
+	log := logger.New(logger.LevelRelease, ts.logs)
+
+	ts.r = gin.New()
+	ts.r.Use(RequestID(), AccessLog(log), Recovery(log))
+	ts.r.GET("/api/products/", func(ctx *gin.Context) {
+		// The handlers pass the gin context down to the services
+		ctx.String(http.StatusOK, domain.RequestIDFromContext(ctx))
+	})
+	ts.r.GET("/api/subscription/", func(ctx *gin.Context) {
+		abortWithError(ctx, errors.New("connection refused"))
+	})
+	ts.r.GET("/api/reports/revenue", func(ctx *gin.Context) {
+		panic("boom")
+	})
+}
+
+// entries decodes the json lines logged while serving the requests.
+func (ts *LoggingTestSuite) entries() []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(ts.logs.String()), "\n") {
+		entry := map[string]interface{}{}
+		ts.Require().Nil(json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (ts *LoggingTestSuite) TestRequestID() {
+	tt := []struct {
+		name       string
+		requestID  string
+		generated  bool
+		expectedID string
+	}{
+		{
+			name:      "Generated when missing",
+			generated: true,
+		},
+		{
+			name:       "Passed by the client",
+			requestID:  "0b7c2a8e-req",
+			expectedID: "0b7c2a8e-req",
+		},
+		{
+			name:      "Replaced when not printable",
+			requestID: "forged\tlevel=error",
+			generated: true,
+		},
+		{
+			name:      "Replaced when too long",
+			requestID: strings.Repeat("a", 129),
+			generated: true,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			ts.logs.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/api/products/", nil)
+			if tc.requestID != "" {
+				req.Header.Set(requestIDHeader, tc.requestID)
+			}
+			w := httptest.NewRecorder()
+			ts.r.ServeHTTP(w, req)
+
+			id := w.Header().Get(requestIDHeader)
+			if tc.generated {
+				_, err := uuid.Parse(id)
+				ts.Assert().Nil(err)
+			} else {
+				ts.Assert().Equal(tc.expectedID, id)
+			}
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Require().Nil(err)
+			ts.Assert().Equal(id, string(data))
+
+			entries := ts.entries()
+			ts.Require().Len(entries, 1)
+			ts.Assert().Equal("info", entries[0]["level"])
+			ts.Assert().Equal("request served", entries[0]["message"])
+			ts.Assert().Equal(id, entries[0]["request_id"])
+			ts.Assert().Equal("GET", entries[0]["method"])
+			ts.Assert().Equal("/api/products/", entries[0]["path"])
+			ts.Assert().EqualValues(http.StatusOK, entries[0]["status"])
+			ts.Assert().Contains(entries[0], "duration_ms")
+		})
+	}
+}
+
+func (ts *LoggingTestSuite) TestServerErrorsAreLogged() {
+	w := httptest.NewRecorder()
+	ts.r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/subscription/", nil))
+	ts.Assert().Equal(http.StatusInternalServerError, w.Code)
+	ts.Assert().NotContains(w.Body.String(), "connection refused")
+
+	entries := ts.entries()
+	ts.Require().Len(entries, 1)
+	ts.Assert().Equal("error", entries[0]["level"])
+	ts.Assert().Equal("request failed", entries[0]["message"])
+	ts.Assert().Equal("connection refused", entries[0]["error"])
+}
+
+func (ts *LoggingTestSuite) TestRecovery() {
+	w := httptest.NewRecorder()
+	ts.r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/reports/revenue", nil))
+	ts.Assert().Equal(http.StatusInternalServerError, w.Code)
+	data, err := io.ReadAll(w.Result().Body)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(`{"type":"urn:isildur:problem:internal_error","title":"Internal Server Error","status":500,"instance":"/api/reports/revenue","code":"internal_error"}`, string(data))
+
+	entries := ts.entries()
+	ts.Require().Len(entries, 2)
+	ts.Assert().Equal("panic recovered", entries[0]["message"])
+	ts.Assert().Equal("boom", entries[0]["panic"])
+	ts.Assert().Contains(entries[0]["stack"], "runtime/debug.Stack")
+	ts.Assert().Equal("request failed", entries[1]["message"])
+	ts.Assert().Equal(entries[0]["request_id"], entries[1]["request_id"])
+}
@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/platform/metrics"
+)
+
+// unmatchedRoute labels the requests matching no route, not to label the metrics per path.
+const unmatchedRoute = "unmatched"
+
+// Instrument records the duration of every request per route and status.
+func Instrument(m *metrics.Metrics) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		m.ObserveRequest(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(start))
+	}
+}
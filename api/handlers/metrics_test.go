@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/metrics"
+	"github.com/goakshit/isildur/repositories/memory"
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsTestSuite struct {
+	suite.Suite
+	router *gin.Engine
+}
+
+func TestMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
+
+func (ts *MetricsTestSuite) SetupTest() {
+	ts.router = gin.New()
+	SetupRouter(ts.router, &config.CFG{}, memory.NewRepositories(memory.NewStore()), RouterOptions{
+		Metrics: metrics.New(),
+	})
+}
+
+func (ts *MetricsTestSuite) serve(path string) (int, string) {
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	data, err := io.ReadAll(w.Result().Body)
+	ts.Require().Nil(err)
+	return w.Code, string(data)
+}
+
+func (ts *MetricsTestSuite) TestRequestsPerRoute() {
+	code, _ := ts.serve("/api/products/")
+	ts.Require().Equal(http.StatusOK, code)
+	code, _ = ts.serve("/api/products/0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f")
+	ts.Require().Equal(http.StatusNotFound, code)
+	code, _ = ts.serve("/api/unknown")
+	ts.Require().Equal(http.StatusNotFound, code)
+
+	code, body := ts.serve("/metrics")
+	ts.Require().Equal(http.StatusOK, code)
+	ts.Assert().Contains(body, `isildur_http_request_duration_seconds_count{method="GET",route="/api/products/",status="200"} 1`)
+	// Labelled by the route, not by the product id
+	ts.Assert().Contains(body, `isildur_http_request_duration_seconds_count{method="GET",route="/api/products/:product-id",status="404"} 1`)
+	ts.Assert().Contains(body, `isildur_http_request_duration_seconds_count{method="GET",route="unmatched",status="404"} 1`)
+}
+
+func (ts *MetricsTestSuite) TestNotExposedWithoutMetrics() {
+	ts.router = gin.New()
+	SetupRouter(ts.router, &config.CFG{}, memory.NewRepositories(memory.NewStore()), RouterOptions{})
+
+	code, _ := ts.serve("/metrics")
+	ts.Assert().Equal(http.StatusNotFound, code)
+}
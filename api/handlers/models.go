@@ -1,10 +1,31 @@
 package handlers
 
 // ErrorResponse represents the standard error response that gets sent
-// for every non 200 request.
+// for every non 200 request, as RFC 7807 problem details.
 type ErrorResponse struct {
-	StatusCode int    `json:"status_code"`
-	Error      string `json:"error"`
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code is the stable machine code of the error, the problem type without its prefix.
+	Code string `json:"code"`
+	// Errors lists the invalid fields of the request, if any.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError represents an invalid field of a request.
+type FieldError struct {
+	Field  string `json:"field"`
+	Code   string `json:"code"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// HealthResponse represents the response of the health probes, with the outcome of every
+// readiness check.
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
 }
 
 // CreateSubscriptionRequest represents the request structure for create
@@ -14,3 +35,11 @@ type CreateSubscriptionRequest struct {
 	StartDate        string `json:"start_date" valid:"required"`
 	DurationInMonths int8   `json:"duration_in_months" valid:"required,numeric"`
 }
+
+// CreateAPIKeyRequest represents the request structure for create api key endpoint.
+// The key never expires when no expiry date is passed.
+type CreateAPIKeyRequest struct {
+	Name      string   `json:"name" valid:"required"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expires_at"`
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+)
+
+const (
+	// problemContentType is the content type of the error responses, as per RFC 7807.
+	problemContentType = "application/problem+json"
+	// problemTypePrefix prefixes the error codes to make up the problem types.
+	problemTypePrefix = "urn:isildur:problem:"
+	// codeInternalError is the code of the errors which aren't domain errors.
+	codeInternalError = "internal_error"
+)
+
+// abortWithError aborts the request with the problem details of an error.
+func abortWithError(ctx *gin.Context, err error) {
+	abortWithProblem(ctx, err, mapErrorResponseFromError(err))
+}
+
+// abortWithFieldError aborts the request with the problem details of an error caused by a
+// request field, the query parameter or the body field name.
+func abortWithFieldError(ctx *gin.Context, field string, err error) {
+	problem := mapErrorResponseFromError(err)
+	problem.Errors = []FieldError{{
+		Field:  field,
+		Code:   problem.Code,
+		Detail: strings.TrimPrefix(err.Error(), problem.Title+": "),
+	}}
+	abortWithProblem(ctx, err, problem)
+}
+
+// abortWithInvalidRequest aborts a request whose body can't be decoded or validated, with an
+// error for every invalid field.
+func abortWithInvalidRequest(ctx *gin.Context, err error) {
+	problem := mapErrorResponseFromError(domain.ErrInvalidRequest)
+	problem.Detail = err.Error()
+	problem.Errors = fieldErrors(err)
+	abortWithProblem(ctx, err, problem)
+}
+
+// abortWithProblem aborts the request with a problem occurring on its path. The error causing
+// it is recorded for the access log.
+func abortWithProblem(ctx *gin.Context, err error, problem ErrorResponse) {
+	_ = ctx.Error(err)
+	if ctx.Request != nil && ctx.Request.URL != nil {
+		problem.Instance = ctx.Request.URL.Path
+	}
+	// Set before rendering, which keeps the content type already set
+	ctx.Header("Content-Type", problemContentType)
+	ctx.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// newProblem returns the problem details of an error, titled by the domain error it wraps.
+func newProblem(status int, err error) ErrorResponse {
+	problem := ErrorResponse{
+		Type:   problemTypePrefix + codeInternalError,
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: err.Error(),
+		Code:   codeInternalError,
+	}
+	var domainErr *domain.Error
+	if errors.As(err, &domainErr) {
+		problem.Type = problemTypePrefix + domainErr.Code
+		problem.Title = domainErr.Message
+		problem.Code = domainErr.Code
+	}
+	// The detail only tells more than the title when the error is wrapped with some context. The
+	// server errors don't tell their cause, only recorded for the access log.
+	if problem.Detail == problem.Title || status >= http.StatusInternalServerError {
+		problem.Detail = ""
+	}
+	return problem
+}
+
+// fieldErrors breaks the decoding and validation errors of a request body down by field.
+func fieldErrors(err error) []FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return []FieldError{{
+			Field:  typeErr.Field,
+			Code:   "type",
+			Detail: "expected " + typeErr.Type.String(),
+		}}
+	}
+
+	var fields []FieldError
+	var validationErrs govalidator.Errors
+	if errors.As(err, &validationErrs) {
+		for _, e := range validationErrs.Errors() {
+			fields = append(fields, fieldErrors(e)...)
+		}
+	}
+	var validationErr govalidator.Error
+	if errors.As(err, &validationErr) {
+		fields = append(fields, FieldError{
+			Field:  strings.Join(append(append([]string{}, validationErr.Path...), validationErr.Name), "."),
+			Code:   validationErr.Validator,
+			Detail: validationErr.Err.Error(),
+		})
+	}
+	return fields
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProblemTestSuite struct {
+	suite.Suite
+	subsSvc *ports.MockSubscriptionService
+}
+
+func TestProblemTestSuite(t *testing.T) {
+	suite.Run(t, new(ProblemTestSuite))
+}
+
+func (ts *ProblemTestSuite) SetupTest() {
+	ctrl := gomock.NewController(ts.T())
+	ts.subsSvc = ports.NewMockSubscriptionService(ctrl)
+}
+
+func (ts *ProblemTestSuite) TestCreateSubscriptionProblems() {
+	const productID = "0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f"
+
+	tt := []struct {
+		name             string
+		body             string
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:             "Body isn't json",
+			body:             `{"product_id":`,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_request","title":"invalid request","status":400,"detail":"unexpected EOF","instance":"/api/subscription/","code":"invalid_request"}`,
+		},
+		{
+			name:             "Field of the wrong type",
+			body:             `{"product_id":"` + productID + `","start_date":"02-01-2022","duration_in_months":"3"}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_request","title":"invalid request","status":400,"detail":"json: cannot unmarshal string into Go struct field CreateSubscriptionRequest.duration_in_months of type int8","instance":"/api/subscription/","code":"invalid_request","errors":[{"field":"duration_in_months","code":"type","detail":"expected int8"}]}`,
+		},
+		{
+			name:             "Every invalid field is reported",
+			body:             `{"product_id":"1234"}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_request","title":"invalid request","status":400,"detail":"duration_in_months: non zero value required;product_id: 1234 does not validate as uuidv4;start_date: non zero value required","instance":"/api/subscription/","code":"invalid_request","errors":[{"field":"product_id","code":"uuidv4","detail":"1234 does not validate as uuidv4"},{"field":"start_date","code":"required","detail":"non zero value required"},{"field":"duration_in_months","code":"required","detail":"non zero value required"}]}`,
+		},
+		{
+			name:             "Invalid start date",
+			body:             `{"product_id":"` + productID + `","start_date":"2022-01-02","duration_in_months":3}`,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_start_date","title":"invalid start date","status":400,"instance":"/api/subscription/","code":"invalid_start_date","errors":[{"field":"start_date","code":"invalid_start_date","detail":"invalid start date"}]}`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/subscription/", strings.NewReader(tc.body))
+			c.Request.Header.Set("Content-Type", "application/json")
+
+			hndlr := NewHTTPHandler(ts.subsSvc, nil)
+			hndlr.CreateSubscription(c)
+			ts.Assert().Equal(tc.expectedCode, w.Code)
+			ts.Assert().Equal(problemContentType, w.Header().Get("Content-Type"))
+
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Assert().Nil(err)
+			ts.Assert().Equal(tc.expectedResponse, string(data))
+		})
+	}
+}
+
+func (ts *ProblemTestSuite) TestNewProblem() {
+	tt := []struct {
+		name     string
+		status   int
+		err      error
+		expected ErrorResponse
+	}{
+		{
+			name:   "Domain error",
+			status: http.StatusNotFound,
+			err:    domain.ErrProductNotfound,
+			expected: ErrorResponse{
+				Type:   "urn:isildur:problem:product_not_found",
+				Title:  "product not found",
+				Status: http.StatusNotFound,
+				Code:   "product_not_found",
+			},
+		},
+		{
+			name:   "Wrapped domain error",
+			status: http.StatusBadRequest,
+			err:    fmt.Errorf("%w: bad uuid", domain.ErrProductIDIsInvalid),
+			expected: ErrorResponse{
+				Type:   "urn:isildur:problem:invalid_product_id",
+				Title:  "invalid product id",
+				Status: http.StatusBadRequest,
+				Detail: "invalid product id: bad uuid",
+				Code:   "invalid_product_id",
+			},
+		},
+		{
+			name:   "Unknown error",
+			status: http.StatusInternalServerError,
+			err:    errors.New("connection refused"),
+			expected: ErrorResponse{
+				Type:   "urn:isildur:problem:internal_error",
+				Title:  "Internal Server Error",
+				Status: http.StatusInternalServerError,
+				Code:   "internal_error",
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			ts.Assert().Equal(tc.expected, newProblem(tc.status, tc.err))
+		})
+	}
+}
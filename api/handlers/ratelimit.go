@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/config"
+)
+
+// NewRateLimits parses the rate limits of the configuration.
+func NewRateLimits(cfg config.RateLimitConfig) (domain.RateLimits, error) {
+	limits := domain.RateLimits{Groups: map[string]domain.RateLimit{}}
+	var err error
+	if limits.Default, err = domain.ParseRateLimit(cfg.Default); err != nil {
+		return domain.RateLimits{}, err
+	}
+	for _, entry := range strings.Split(cfg.Groups, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		group, limit, ok := strings.Cut(entry, "=")
+		group = strings.TrimSpace(group)
+		if !ok {
+			return domain.RateLimits{}, fmt.Errorf("invalid rate limit %q, expected group=requests/period", entry)
+		}
+		switch group {
+		case domain.RateLimitGroupAuth, domain.RateLimitGroupSubscription, domain.RateLimitGroupProducts,
+			domain.RateLimitGroupAdmin, domain.RateLimitGroupReports:
+		default:
+			return domain.RateLimits{}, fmt.Errorf("unknown route group %q", group)
+		}
+		if limits.Groups[group], err = domain.ParseRateLimit(limit); err != nil {
+			return domain.RateLimits{}, err
+		}
+	}
+	return limits, nil
+}
+
+// RateLimit limits the requests of every client to a route group. Clients are told apart by
+// their principal, partners by their API key, or by their IP when not authenticated. Limited
+// requests are rejected with 429.
+func RateLimit(limiter ports.RateLimiter, group string, limit domain.RateLimit) gin.HandlerFunc {
+	policy := fmt.Sprintf("%d;w=%d", limit.Requests, int(limit.Per.Seconds()))
+	return func(ctx *gin.Context) {
+		client := "ip:" + ctx.ClientIP()
+		if principal, ok := domain.PrincipalFromContext(ctx.Request.Context()); ok {
+			client = "sub:" + principal.Subject
+		}
+		result, err := limiter.Allow(ctx, group+":"+client, limit, time.Now())
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		ctx.Header("RateLimit-Policy", policy)
+		ctx.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Header("RateLimit-Reset", strconv.Itoa(seconds(result.Reset)))
+		if !result.Allowed {
+			ctx.Header("Retry-After", strconv.Itoa(seconds(result.RetryAfter)))
+			abortWithError(ctx, domain.ErrRateLimited)
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// seconds rounds a duration up to whole seconds, as the rate limit headers expect.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/repositories/memory"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/suite"
+)
+
+type RateLimitTestSuite struct {
+	suite.Suite
+	limiter *ports.MockRateLimiter
+}
+
+func TestRateLimitTestSuite(t *testing.T) {
+	suite.Run(t, new(RateLimitTestSuite))
+}
+
+func (ts *RateLimitTestSuite) SetupTest() {
+	ctrl := gomock.NewController(ts.T())
+	ts.limiter = ports.NewMockRateLimiter(ctrl)
+}
+
+func (ts *RateLimitTestSuite) TestNewRateLimits() {
+	limits, err := NewRateLimits(config.RateLimitConfig{
+		Default: "300/1m",
+		Groups:  "products=60/1m, reports=10/1h,",
+	})
+	ts.Require().Nil(err)
+	ts.Assert().Equal(domain.RateLimit{Requests: 300, Per: time.Minute}, limits.For(domain.RateLimitGroupSubscription))
+	ts.Assert().Equal(domain.RateLimit{Requests: 60, Per: time.Minute}, limits.For(domain.RateLimitGroupProducts))
+	ts.Assert().Equal(domain.RateLimit{Requests: 10, Per: time.Hour}, limits.For(domain.RateLimitGroupReports))
+
+	for _, cfg := range []config.RateLimitConfig{
+		{Default: "300"},
+		{Default: "0/1m"},
+		{Default: "300/forever"},
+		{Default: "300/1m", Groups: "products"},
+		{Default: "300/1m", Groups: "unknown=10/1m"},
+	} {
+		_, err := NewRateLimits(cfg)
+		ts.Assert().NotNil(err, "%+v", cfg)
+	}
+}
+
+func (ts *RateLimitTestSuite) TestRateLimit() {
+	limit := domain.RateLimit{Requests: 60, Per: time.Minute}
+	tt := []struct {
+		name             string
+		principal        *domain.Principal
+		expectedKey      string
+		result           domain.RateLimitResult
+		allowErr         error
+		expectedCode     int
+		expectedResponse string
+		expectedHeaders  map[string]string
+	}{
+		{
+			name:             "Allowed by IP",
+			expectedKey:      "products:ip:192.0.2.1",
+			result:           domain.RateLimitResult{Allowed: true, Limit: 60, Remaining: 59, Reset: 1500 * time.Millisecond},
+			expectedCode:     http.StatusOK,
+			expectedResponse: "ok",
+			expectedHeaders: map[string]string{
+				"RateLimit-Policy":    "60;w=60",
+				"RateLimit-Limit":     "60",
+				"RateLimit-Remaining": "59",
+				"RateLimit-Reset":     "2",
+				"Retry-After":         "",
+			},
+		},
+		{
+			name:             "Allowed by principal",
+			principal:        &domain.Principal{Subject: "api-key:1"},
+			expectedKey:      "products:sub:api-key:1",
+			result:           domain.RateLimitResult{Allowed: true, Limit: 60, Remaining: 10, Reset: time.Minute},
+			expectedCode:     http.StatusOK,
+			expectedResponse: "ok",
+		},
+		{
+			name:             "Limited",
+			expectedKey:      "products:ip:192.0.2.1",
+			result:           domain.RateLimitResult{Limit: 60, Reset: time.Minute, RetryAfter: 200 * time.Millisecond},
+			expectedCode:     http.StatusTooManyRequests,
+			expectedResponse: `{"type":"urn:isildur:problem:rate_limited","title":"rate limit exceeded","status":429,"instance":"/api/products/","code":"rate_limited"}`,
+			expectedHeaders: map[string]string{
+				"RateLimit-Remaining": "0",
+				"RateLimit-Reset":     "60",
+				"Retry-After":         "1",
+			},
+		},
+		{
+			name:             "Limiter failure",
+			expectedKey:      "products:ip:192.0.2.1",
+			allowErr:         errors.New("connection refused"),
+			expectedCode:     http.StatusInternalServerError,
+			expectedResponse: `{"type":"urn:isildur:problem:internal_error","title":"Internal Server Error","status":500,"instance":"/api/products/","code":"internal_error"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			ts.limiter.EXPECT().Allow(gomock.Any(), tc.expectedKey, limit, gomock.Any()).
+				Times(1).
+				Return(tc.result, tc.allowErr)
+
+			r := gin.New()
+			if tc.principal != nil {
+				r.Use(func(ctx *gin.Context) {
+					ctx.Request = ctx.Request.WithContext(domain.ContextWithPrincipal(ctx.Request.Context(), *tc.principal))
+				})
+			}
+			r.Use(RateLimit(ts.limiter, domain.RateLimitGroupProducts, limit))
+			r.GET("/api/products/", func(ctx *gin.Context) {
+				ctx.String(http.StatusOK, "ok")
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/products/", nil))
+
+			ts.Assert().Equal(tc.expectedCode, w.Code)
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Require().Nil(err)
+			ts.Assert().Equal(tc.expectedResponse, string(data))
+			for header, value := range tc.expectedHeaders {
+				ts.Assert().Equal(value, w.Header().Get(header), header)
+			}
+		})
+	}
+}
+
+func (ts *RateLimitTestSuite) TestRateLimit_BeforeAuthenticate() {
+	verifier := ports.NewMockTokenVerifier(gomock.NewController(ts.T()))
+	limits := domain.RateLimits{
+		Default: domain.RateLimit{Requests: 300, Per: time.Minute},
+		Groups:  map[string]domain.RateLimit{domain.RateLimitGroupAuth: {Requests: 1000, Per: time.Minute}},
+	}
+	r := gin.New()
+	// The X-Forwarded-For header of the untrusted clients is ignored
+	ts.Require().Nil(r.SetTrustedProxies(nil))
+	SetupRouter(r, &config.CFG{}, memory.NewRepositories(memory.NewStore()), RouterOptions{
+		Verifier:    verifier,
+		RateLimiter: ts.limiter,
+		RateLimits:  limits,
+	})
+	request := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/products/", nil)
+		req.Header.Set("Authorization", "Bearer invalid-token")
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	// The invalid credentials are counted against the IP of the client
+	ts.limiter.EXPECT().Allow(gomock.Any(), "auth:ip:192.0.2.1", limits.Groups[domain.RateLimitGroupAuth], gomock.Any()).
+		Return(domain.RateLimitResult{Allowed: true, Limit: 1000, Remaining: 999}, nil)
+	verifier.EXPECT().Verify("invalid-token").Return(domain.Principal{}, domain.ErrUnauthenticated)
+	ts.Assert().Equal(http.StatusUnauthorized, request().Code)
+
+	// Once limited, they aren't verified anymore
+	ts.limiter.EXPECT().Allow(gomock.Any(), "auth:ip:192.0.2.1", limits.Groups[domain.RateLimitGroupAuth], gomock.Any()).
+		Return(domain.RateLimitResult{Limit: 1000, RetryAfter: time.Second}, nil)
+	ts.Assert().Equal(http.StatusTooManyRequests, request().Code)
+}
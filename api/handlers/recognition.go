@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/constants"
+	"github.com/google/uuid"
+)
+
+// RecognitionHandler holds dependencies used inside the revenue recognition http handlers.
+type RecognitionHandler struct {
+	Recognition ports.RecognitionService
+}
+
+// NewRecognitionHandler returns a new RecognitionHandler.
+func NewRecognitionHandler(recognition ports.RecognitionService) RecognitionHandler {
+	return RecognitionHandler{
+		Recognition: recognition,
+	}
+}
+
+// RevenueSchedule responds with the monthly revenue entries of a subscription.
+func (h *RecognitionHandler) RevenueSchedule(ctx *gin.Context) {
+	sID, err := uuid.Parse(ctx.Param(constants.SubscriptionIDKey))
+	if err != nil {
+		abortWithError(ctx, fmt.Errorf("%w: %v", domain.ErrSubscriptionIDIsInvalid, err))
+		return
+	}
+	entries, err := h.Recognition.RevenueSchedule(ctx, sID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// RefundSubscription cancels a subscription, refunds the months not over yet and responds
+// with the refunded amount.
+func (h *RecognitionHandler) RefundSubscription(ctx *gin.Context) {
+	sID, err := uuid.Parse(ctx.Param(constants.SubscriptionIDKey))
+	if err != nil {
+		abortWithError(ctx, fmt.Errorf("%w: %v", domain.ErrSubscriptionIDIsInvalid, err))
+		return
+	}
+	refund, err := h.Recognition.RefundSubscription(ctx, sID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, refund)
+}
+
+// PeriodCloseReport responds with the revenue recognized and refunded between the from and
+// to queries, and deferred at to, per product.
+func (h *RecognitionHandler) PeriodCloseReport(ctx *gin.Context) {
+	from, err := parseDateQuery(ctx, "from", time.Time{})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	to, err := parseDateQuery(ctx, "to", time.Time{})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	report, err := h.Recognition.PeriodCloseReport(ctx, from, to)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/constants"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecognitionHttpTestSuite struct {
+	suite.Suite
+	recognition *ports.MockRecognitionService
+}
+
+func TestRecognitionHttpTestSuite(t *testing.T) {
+	suite.Run(t, new(RecognitionHttpTestSuite))
+}
+
+func (ts *RecognitionHttpTestSuite) SetupTest() {
+	ctrl := gomock.NewController(ts.T())
+	ts.recognition = ports.NewMockRecognitionService(ctrl)
+}
+
+func (ts *RecognitionHttpTestSuite) TestRecognitionHandlers_RevenueSchedule() {
+	subsID := uuid.MustParse("0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f")
+	start := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+	entry := domain.RevenueEntry{
+		ID:             uuid.MustParse("9d6f0f1c-2c4e-4e0b-8f3a-1b2c3d4e5f60"),
+		SubscriptionID: subsID,
+		ProductID:      uuid.MustParse("56f79fee-0cb0-4e87-9bca-7b5811cca4ce"),
+		Month:          1,
+		PeriodStart:    start,
+		PeriodEnd:      start.AddDate(0, 1, 0),
+		Amount:         5,
+		Status:         domain.RevenueEntryScheduled,
+		RecognizeOn:    start.AddDate(0, 1, 0),
+		BookedOn:       start,
+	}
+
+	tt := []struct {
+		name             string
+		subscriptionID   string
+		scheduleTimes    int
+		scheduleErr      error
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:           "Revenue schedule",
+			subscriptionID: subsID.String(),
+			scheduleTimes:  1,
+			expectedCode:   http.StatusOK,
+			expectedResponse: `[{"id":"9d6f0f1c-2c4e-4e0b-8f3a-1b2c3d4e5f60","subscription_id":"0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f",` +
+				`"product_id":"56f79fee-0cb0-4e87-9bca-7b5811cca4ce","month":1,"period_start":"2022-06-01T00:00:00Z",` +
+				`"period_end":"2022-07-01T00:00:00Z","amount":5,"status":"scheduled","recognize_on":"2022-07-01T00:00:00Z",` +
+				`"booked_on":"2022-06-01T00:00:00Z"}]`,
+		},
+		{
+			name:             "Revenue schedule: subscription not found",
+			subscriptionID:   subsID.String(),
+			scheduleTimes:    1,
+			scheduleErr:      domain.ErrSubscriptionNotfound,
+			expectedCode:     http.StatusNotFound,
+			expectedResponse: `{"type":"urn:isildur:problem:subscription_not_found","title":"subscription not found","status":404,"instance":"/api/subscription/0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f/revenue","code":"subscription_not_found"}`,
+		},
+		{
+			name:             "Revenue schedule: invalid subscription id",
+			subscriptionID:   "1234",
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_subscription_id","title":"invalid subscription id","status":400,"detail":"invalid subscription id: invalid UUID length: 4","instance":"/api/subscription/1234/revenue","code":"invalid_subscription_id"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/subscription/"+tc.subscriptionID+"/revenue", nil)
+			c.AddParam(constants.SubscriptionIDKey, tc.subscriptionID)
+
+			ts.recognition.EXPECT().RevenueSchedule(gomock.Any(), subsID).
+				Times(tc.scheduleTimes).
+				Return([]domain.RevenueEntry{entry}, tc.scheduleErr)
+
+			hndlr := NewRecognitionHandler(ts.recognition)
+			hndlr.RevenueSchedule(c)
+			ts.Assert().EqualValues(tc.expectedCode, w.Code)
+
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Assert().Nil(err)
+			ts.Assert().Equal(tc.expectedResponse, string(data))
+		})
+	}
+}
+
+func (ts *RecognitionHttpTestSuite) TestRecognitionHandlers_RefundSubscription() {
+	subsID := uuid.MustParse("0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f")
+
+	tt := []struct {
+		name             string
+		refundErr        error
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:         "Refund subscription",
+			expectedCode: http.StatusOK,
+			expectedResponse: `{"subscription_id":"0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f","amount":6.67,"months":2,` +
+				`"refunded_on":"2022-07-10T00:00:00Z"}`,
+		},
+		{
+			name:             "Refund subscription: already cancelled",
+			refundErr:        domain.ErrCannotUpdateCancelledSubscription,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:subscription_cancelled","title":"cannot update cancelled subsciption","status":400,"instance":"/api/admin/subscriptions/0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f/refund","code":"subscription_cancelled"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/admin/subscriptions/"+subsID.String()+"/refund", nil)
+			c.AddParam(constants.SubscriptionIDKey, subsID.String())
+
+			ts.recognition.EXPECT().RefundSubscription(gomock.Any(), subsID).
+				Times(1).
+				Return(domain.Refund{
+					SubscriptionID: subsID,
+					Amount:         6.67,
+					Months:         2,
+					RefundedOn:     time.Date(2022, time.July, 10, 0, 0, 0, 0, time.UTC),
+				}, tc.refundErr)
+
+			hndlr := NewRecognitionHandler(ts.recognition)
+			hndlr.RefundSubscription(c)
+			ts.Assert().EqualValues(tc.expectedCode, w.Code)
+
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Assert().Nil(err)
+			ts.Assert().Equal(tc.expectedResponse, string(data))
+		})
+	}
+}
+
+func (ts *RecognitionHttpTestSuite) TestRecognitionHandlers_PeriodCloseReport() {
+	from := time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name             string
+		query            string
+		reportTimes      int
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:         "Period close report",
+			query:        "from=01-07-2022&to=01-08-2022",
+			reportTimes:  1,
+			expectedCode: http.StatusOK,
+			expectedResponse: `{"from":"2022-07-01T00:00:00Z","to":"2022-08-01T00:00:00Z","recognized":5,"refunded":0,"deferred":10,` +
+				`"products":[{"product_id":"56f79fee-0cb0-4e87-9bca-7b5811cca4ce","product_name":"YOGA L1","recognized":5,"refunded":0,"deferred":10}]}`,
+		},
+		{
+			name:             "Period close report: missing from",
+			query:            "to=01-08-2022",
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_report_period","title":"invalid report period","status":400,"instance":"/api/reports/recognition","code":"invalid_report_period"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/reports/recognition?"+tc.query, nil)
+
+			ts.recognition.EXPECT().PeriodCloseReport(gomock.Any(), from, to).
+				Times(tc.reportTimes).
+				Return(domain.PeriodCloseReport{
+					From:       from,
+					To:         to,
+					Recognized: 5,
+					Deferred:   10,
+					Products: []domain.ProductRecognition{{
+						ProductID:   uuid.MustParse("56f79fee-0cb0-4e87-9bca-7b5811cca4ce"),
+						ProductName: "YOGA L1",
+						Recognized:  5,
+						Deferred:    10,
+					}},
+				}, nil)
+
+			hndlr := NewRecognitionHandler(ts.recognition)
+			hndlr.PeriodCloseReport(c)
+			ts.Assert().EqualValues(tc.expectedCode, w.Code)
+
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Assert().Nil(err)
+			ts.Assert().Equal(tc.expectedResponse, string(data))
+		})
+	}
+}
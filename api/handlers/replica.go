@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+)
+
+// primaryReadsHeader forces the reads of a request on the primary database when true, e.g. for
+// a client reading its own writes while the read replica lags behind.
+const primaryReadsHeader = "X-Read-Primary"
+
+// PrimaryReads sends the reads of the requests passing the X-Read-Primary header to the primary
+// database instead of the read replica, if any.
+func PrimaryReads() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if primary, _ := strconv.ParseBool(ctx.GetHeader(primaryReadsHeader)); primary {
+			ctx.Request = ctx.Request.WithContext(domain.ContextWithPrimaryReads(ctx.Request.Context()))
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReplicaTestSuite struct {
+	suite.Suite
+	r *gin.Engine
+}
+
+func TestReplicaTestSuite(t *testing.T) {
+	suite.Run(t, new(ReplicaTestSuite))
+}
+
+func (ts *ReplicaTestSuite) SetupTest() {
+	ts.r = gin.New()
+	ts.r.Use(PrimaryReads())
+	ts.r.GET("/api/products/", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, strconv.FormatBool(domain.PrimaryReadsFromContext(ctx)))
+	})
+}
+
+func (ts *ReplicaTestSuite) TestPrimaryReads() {
+	tt := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{
+			name:     "Replica by default",
+			expected: "false",
+		},
+		{
+			name:     "Primary when asked",
+			header:   "true",
+			expected: "true",
+		},
+		{
+			name:     "Replica when not a boolean",
+			header:   "please",
+			expected: "false",
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			req := httptest.NewRequest(http.MethodGet, "/api/products/", nil)
+			if tc.header != "" {
+				req.Header.Set(primaryReadsHeader, tc.header)
+			}
+			w := httptest.NewRecorder()
+			ts.r.ServeHTTP(w, req)
+
+			ts.Assert().Equal(http.StatusOK, w.Code)
+			ts.Assert().Equal(tc.expected, w.Body.String())
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/constants"
+)
+
+// ReportsHandler holds dependencies used inside the reporting http handlers.
+type ReportsHandler struct {
+	Reports ports.ReportsService
+}
+
+// NewReportsHandler returns a new ReportsHandler.
+func NewReportsHandler(reports ports.ReportsService) ReportsHandler {
+	return ReportsHandler{
+		Reports: reports,
+	}
+}
+
+// RevenueReport responds with the MRR, the ARR and the active subscribers at the date passed
+// in the as_of query, today when missing.
+func (h *ReportsHandler) RevenueReport(ctx *gin.Context) {
+	asOf, err := parseDateQuery(ctx, "as_of", time.Now().UTC().Truncate(24*time.Hour))
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	report, err := h.Reports.RevenueReport(ctx, asOf)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// ActivityReport responds with the new, churned and paused subscriptions per period between
+// the from and to queries, split by the interval query (day, week or month by default).
+func (h *ReportsHandler) ActivityReport(ctx *gin.Context) {
+	from, err := parseDateQuery(ctx, "from", time.Time{})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	to, err := parseDateQuery(ctx, "to", time.Time{})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	interval := domain.ReportInterval(ctx.DefaultQuery("interval", string(domain.ReportIntervalMonth)))
+
+	report, err := h.Reports.ActivityReport(ctx, from, to, interval)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
+// parseDateQuery parses a date query parameter in the api date format, returning def when
+// the parameter is missing. A zero def makes the parameter required.
+func parseDateQuery(ctx *gin.Context, name string, def time.Time) (time.Time, error) {
+	value := ctx.Query(name)
+	if value == "" {
+		if def.IsZero() {
+			return time.Time{}, domain.ErrInvalidReportPeriod
+		}
+		return def, nil
+	}
+	date, err := time.Parse(constants.DateFormat, value)
+	if err != nil {
+		return time.Time{}, domain.ErrInvalidReportPeriod
+	}
+	return date, nil
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReportsHttpTestSuite struct {
+	suite.Suite
+	reports *ports.MockReportsService
+}
+
+func TestReportsHttpTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportsHttpTestSuite))
+}
+
+func (ts *ReportsHttpTestSuite) SetupTest() {
+	ctrl := gomock.NewController(ts.T())
+	ts.reports = ports.NewMockReportsService(ctrl)
+}
+
+func (ts *ReportsHttpTestSuite) TestReportsHandlers_RevenueReport() {
+	asOf := time.Date(2022, time.July, 15, 0, 0, 0, 0, time.UTC)
+	productID := uuid.MustParse("56f79fee-0cb0-4e87-9bca-7b5811cca4ce")
+
+	tt := []struct {
+		name             string
+		query            string
+		reportTimes      int
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:         "Revenue report",
+			query:        "as_of=15-07-2022",
+			reportTimes:  1,
+			expectedCode: http.StatusOK,
+			expectedResponse: `{"as_of":"2022-07-15T00:00:00Z","mrr":15,"arr":180,"active_subscriptions":3,"active_customers":2,` +
+				`"products":[{"product_id":"56f79fee-0cb0-4e87-9bca-7b5811cca4ce","product_name":"YOGA L1","active_subscriptions":3,"mrr":15,"arr":180}]}`,
+		},
+		{
+			name:             "Revenue report: invalid date",
+			query:            "as_of=2022-07-15",
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_report_period","title":"invalid report period","status":400,"instance":"/api/reports/revenue","code":"invalid_report_period"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/reports/revenue?"+tc.query, nil)
+
+			ts.reports.EXPECT().RevenueReport(gomock.Any(), asOf).
+				Times(tc.reportTimes).
+				Return(domain.RevenueReport{
+					AsOf:                asOf,
+					MRR:                 15,
+					ARR:                 180,
+					ActiveSubscriptions: 3,
+					ActiveCustomers:     2,
+					Products: []domain.ProductRevenue{
+						{ProductID: productID, ProductName: "YOGA L1", ActiveSubscriptions: 3, MRR: 15, ARR: 180},
+					},
+				}, nil)
+
+			hndlr := NewReportsHandler(ts.reports)
+			hndlr.RevenueReport(c)
+			ts.Assert().EqualValues(tc.expectedCode, w.Code)
+
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Assert().Nil(err)
+			ts.Assert().Equal(tc.expectedResponse, string(data))
+		})
+	}
+}
+
+func (ts *ReportsHttpTestSuite) TestReportsHandlers_ActivityReport() {
+	from := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC)
+
+	tt := []struct {
+		name             string
+		query            string
+		interval         domain.ReportInterval
+		reportTimes      int
+		reportErr        error
+		expectedCode     int
+		expectedResponse string
+	}{
+		{
+			name:         "Activity report",
+			query:        "from=01-06-2022&to=01-07-2022",
+			interval:     domain.ReportIntervalMonth,
+			reportTimes:  1,
+			expectedCode: http.StatusOK,
+			expectedResponse: `{"from":"2022-06-01T00:00:00Z","to":"2022-07-01T00:00:00Z","interval":"month","periods":[` +
+				`{"start":"2022-06-01T00:00:00Z","end":"2022-07-01T00:00:00Z","new":1,"churned":0,"paused":0,"products":[]}]}`,
+		},
+		{
+			name:             "Activity report: invalid interval",
+			query:            "from=01-06-2022&to=01-07-2022&interval=year",
+			interval:         "year",
+			reportTimes:      1,
+			reportErr:        domain.ErrInvalidReportPeriod,
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_report_period","title":"invalid report period","status":400,"instance":"/api/reports/activity","code":"invalid_report_period"}`,
+		},
+		{
+			name:             "Activity report: missing to",
+			query:            "from=01-06-2022",
+			expectedCode:     http.StatusBadRequest,
+			expectedResponse: `{"type":"urn:isildur:problem:invalid_report_period","title":"invalid report period","status":400,"instance":"/api/reports/activity","code":"invalid_report_period"}`,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/reports/activity?"+tc.query, nil)
+
+			ts.reports.EXPECT().ActivityReport(gomock.Any(), from, to, tc.interval).
+				Times(tc.reportTimes).
+				Return(domain.ActivityReport{
+					From:     from,
+					To:       to,
+					Interval: domain.ReportIntervalMonth,
+					Periods: []domain.ActivityPeriod{
+						{Start: from, End: to, New: 1, Products: []domain.ProductActivity{}},
+					},
+				}, tc.reportErr)
+
+			hndlr := NewReportsHandler(ts.reports)
+			hndlr.ActivityReport(c)
+			ts.Assert().EqualValues(tc.expectedCode, w.Code)
+
+			data, err := io.ReadAll(w.Result().Body)
+			ts.Assert().Nil(err)
+			ts.Assert().Equal(tc.expectedResponse, string(data))
+		})
+	}
+}
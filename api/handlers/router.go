@@ -4,32 +4,119 @@ import (
 	"fmt"
 
 	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
 	"github.com/goakshit/isildur/platform/config"
 	"github.com/goakshit/isildur/platform/constants"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/goakshit/isildur/platform/metrics"
 	"github.com/goakshit/isildur/repositories"
 	"github.com/goakshit/isildur/services"
-	"gorm.io/gorm"
 )
 
-// SetupRouter intialises services, repositories, sets up routing to correct handlers.
-func SetupRouter(r *gin.Engine, cfg *config.CFG, db *gorm.DB) {
+// RouterOptions holds the optional middlewares dependencies of the router.
+type RouterOptions struct {
+	// Verifier verifies the JWTs. Every api requires a JWT or an API key, unless it is nil.
+	Verifier ports.TokenVerifier
+	// RateLimiter limits the requests of the clients to the RateLimits of every route group,
+	// unless it is nil.
+	RateLimiter ports.RateLimiter
+	RateLimits  domain.RateLimits
+	// Logger logs the requests, which aren't logged if it is nil.
+	Logger ports.Logger
+	// Metrics records the requests and the business metrics, exposed on /metrics unless it is nil.
+	Metrics *metrics.Metrics
+	// Health serves the probes, always ready if it is nil.
+	Health *Health
+}
+
+// SetupRouter intialises services, sets up routing to correct handlers.
+func SetupRouter(r *gin.Engine, cfg *config.CFG, repos repositories.Repositories, opts RouterOptions) {
+	log := opts.Logger
+	if log == nil {
+		log = logger.Nop()
+	}
+	health := opts.Health
+	if health == nil {
+		health = NewHealth()
+	}
+	// The probes are registered first, not to be traced and logged every few seconds
+	r.GET("/healthz", health.Live)
+	r.GET("/readyz", health.Ready)
+
+	// Every request is traced and gets an id first, for the access log and the handlers to log
+	// them
+	r.Use(Trace(), RequestID(), PrimaryReads(), AccessLog(log))
+	m := metrics.Nop()
+	if opts.Metrics != nil {
+		// Timed around the recovery, for the panics to be recorded as server errors
+		r.Use(Instrument(opts.Metrics))
+		r.GET("/metrics", gin.WrapH(opts.Metrics.Handler()))
+		m = opts.Metrics
+	}
+	r.Use(Recovery(log))
+
+	// The docs are public, registered outside of the authenticated group
+	r.GET("/api/openapi.json", OpenAPISpec)
+	r.GET("/api/docs", Docs)
+
 	api := r.Group("/api")
+	if opts.RateLimiter != nil {
+		// The clients are limited by IP before being authenticated, for the invalid credentials
+		// and the lookups of the API keys to be throttled too
+		api.Use(RateLimit(opts.RateLimiter, domain.RateLimitGroupAuth, opts.RateLimits.For(domain.RateLimitGroupAuth)))
+	}
+	apiKeysSvc := services.NewAPIKeyService(repos.APIKeys)
+	if opts.Verifier != nil {
+		api.Use(Authenticate(opts.Verifier, apiKeysSvc))
+	}
+	// The clients are then rate limited per route group, told apart by their principal
+	rateLimit := func(group string) []gin.HandlerFunc {
+		if opts.RateLimiter == nil {
+			return nil
+		}
+		return []gin.HandlerFunc{RateLimit(opts.RateLimiter, group, opts.RateLimits.For(group))}
+	}
 
-	subsRepo := repositories.NewSubscriptionsRepository(db)
-	productsRepo := repositories.NewProductsRepository(db)
-	subsSvc := services.NewSubscriptionService(subsRepo, productsRepo)
-	productsSvc := services.NewProductsService(productsRepo)
+	recognitionSvc := services.NewRecognitionService(repos.RevenueEntries, repos.Subscriptions, repos.Tx, m)
+	subsSvc := services.NewSubscriptionService(repos.Subscriptions, repos.Products, repos.Tx, recognitionSvc, m)
+	productsSvc := services.NewProductsService(repos.Products)
+	importSvc := services.NewImportService(repos.Subscriptions, repos.Products, repos.Tx, recognitionSvc, m)
+	reportsSvc := services.NewReportsService(repos.Reports)
 	handler := NewHTTPHandler(subsSvc, productsSvc)
+	adminHandler := NewAdminHandler(importSvc)
+	reportsHandler := NewReportsHandler(reportsSvc)
+	recognitionHandler := NewRecognitionHandler(recognitionSvc)
+	apiKeysHandler := NewAPIKeysHandler(apiKeysSvc)
 
-	subscriptionAPI := api.Group("/subscription")
+	subscriptionAPI := api.Group("/subscription", rateLimit(domain.RateLimitGroupSubscription)...)
 	{
 		subscriptionAPI.POST("/", handler.CreateSubscription)
+		subscriptionAPI.GET("/", handler.ListSubscriptions)
+		subscriptionAPI.GET("/export", handler.ExportSubscriptions)
 		subscriptionAPI.GET(fmt.Sprintf("/:%s", constants.SubscriptionIDKey), handler.FetchSubscription)
 		subscriptionAPI.PATCH(fmt.Sprintf("/:%s", constants.SubscriptionIDKey), handler.UpdateSubscriptionStatus)
+		subscriptionAPI.GET(fmt.Sprintf("/:%s/revenue", constants.SubscriptionIDKey), recognitionHandler.RevenueSchedule)
 	}
-	productsAPI := api.Group("/products")
+	productsAPI := api.Group("/products", rateLimit(domain.RateLimitGroupProducts)...)
 	{
 		productsAPI.GET("/", handler.FetchAllProducts)
 		productsAPI.GET(fmt.Sprintf("/:%s", constants.ProductIDKey), handler.FetchProduct)
 	}
+	// The staff routes are denied to the customers up front, the services checking the role
+	// required by every operation
+	adminAPI := api.Group("/admin", append(rateLimit(domain.RateLimitGroupAdmin), RequireRole(domain.RoleSupport, domain.RoleAdmin))...)
+	{
+		adminAPI.POST("/subscriptions/import", adminHandler.ImportSubscriptions)
+		adminAPI.POST(fmt.Sprintf("/subscriptions/:%s/refund", constants.SubscriptionIDKey), recognitionHandler.RefundSubscription)
+		adminAPI.POST("/api-keys", apiKeysHandler.CreateAPIKey)
+		adminAPI.GET("/api-keys", apiKeysHandler.ListAPIKeys)
+		adminAPI.DELETE(fmt.Sprintf("/api-keys/:%s", constants.APIKeyIDKey), apiKeysHandler.RevokeAPIKey)
+	}
+	reportsAPI := api.Group("/reports", append(rateLimit(domain.RateLimitGroupReports), RequireRole(domain.RoleAdmin))...)
+	{
+		reportsAPI.GET("/revenue", reportsHandler.RevenueReport)
+		reportsAPI.GET("/activity", reportsHandler.ActivityReport)
+		reportsAPI.GET("/recognition", recognitionHandler.PeriodCloseReport)
+	}
 }
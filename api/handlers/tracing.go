@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName names the tracer of the requests.
+const instrumentationName = "github.com/goakshit/isildur/api/handlers"
+
+// Trace serves every request in a span, child of the span of the caller passed in the W3C
+// traceparent header. The spans of the services and the queries are its children.
+func Trace() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		parent := otel.GetTextMapPropagator().Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
+		route := ctx.FullPath()
+		if route == "" {
+			route = unmatchedRoute
+		}
+		spanCtx, span := otel.Tracer(instrumentationName).Start(parent,
+			fmt.Sprintf("%s %s", ctx.Request.Method, route),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPMethodKey.String(ctx.Request.Method),
+				semconv.HTTPRouteKey.String(route),
+				semconv.HTTPTargetKey.String(ctx.Request.URL.Path),
+				semconv.HTTPClientIPKey.String(ctx.ClientIP()),
+			),
+		)
+		defer span.End()
+		ctx.Request = ctx.Request.WithContext(spanCtx)
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(status))
+		// The client errors are the caller's, not failures of the service
+		if status >= http.StatusInternalServerError {
+			if err := ctx.Errors.Last(); err != nil {
+				span.RecordError(err.Err)
+			}
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
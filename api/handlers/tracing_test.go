@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/tracing"
+	"github.com/goakshit/isildur/repositories/memory"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TracingTestSuite struct {
+	suite.Suite
+	spans  *tracetest.InMemoryExporter
+	router *gin.Engine
+}
+
+func TestTracingTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingTestSuite))
+}
+
+func (ts *TracingTestSuite) SetupTest() {
+	ts.spans = tracetest.NewInMemoryExporter()
+	cfg := &config.CFG{ServiceName: "isildur", Tracing: config.TracingConfig{SampleRatio: 1}}
+	otel.SetTracerProvider(tracing.NewProvider(cfg, sdktrace.WithSyncer(ts.spans)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	ts.router = gin.New()
+	SetupRouter(ts.router, cfg, memory.NewRepositories(memory.NewStore()), RouterOptions{})
+}
+
+// span returns the recorded span with the name.
+func (ts *TracingTestSuite) span(name string) tracetest.SpanStub {
+	for _, span := range ts.spans.GetSpans() {
+		if span.Name == name {
+			return span
+		}
+	}
+	ts.FailNow("span not recorded", name)
+	return tracetest.SpanStub{}
+}
+
+func (ts *TracingTestSuite) TestSpans() {
+	req := httptest.NewRequest(http.MethodGet, "/api/products/0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, req)
+	ts.Require().Equal(http.StatusNotFound, w.Code)
+
+	server := ts.span("GET /api/products/:product-id")
+	ts.Assert().Equal(trace.SpanKindServer, server.SpanKind)
+	ts.Assert().Equal("4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext.TraceID().String())
+	ts.Assert().Equal("00f067aa0ba902b7", server.Parent.SpanID().String())
+	ts.Assert().True(server.Parent.IsRemote())
+	// Client errors aren't failures of the service
+	ts.Assert().Equal(codes.Unset, server.Status.Code)
+	attrs := map[string]string{}
+	for _, attr := range server.Attributes {
+		attrs[string(attr.Key)] = attr.Value.Emit()
+	}
+	ts.Assert().Equal("GET", attrs["http.method"])
+	ts.Assert().Equal("/api/products/:product-id", attrs["http.route"])
+	ts.Assert().Equal("404", attrs["http.status_code"])
+
+	service := ts.span("ProductsService.FetchProduct")
+	ts.Assert().Equal(server.SpanContext.TraceID(), service.SpanContext.TraceID())
+	ts.Assert().Equal(server.SpanContext.SpanID(), service.Parent.SpanID())
+	ts.Assert().Equal(codes.Error, service.Status.Code)
+}
+
+func (ts *TracingTestSuite) TestNewTrace() {
+	w := httptest.NewRecorder()
+	ts.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/products/", nil))
+	ts.Require().Equal(http.StatusOK, w.Code)
+
+	server := ts.span("GET /api/products/")
+	ts.Assert().True(server.SpanContext.IsValid())
+	ts.Assert().False(server.Parent.IsValid())
+	ts.Assert().Equal(codes.Unset, server.Status.Code)
+}
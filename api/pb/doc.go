@@ -0,0 +1,4 @@
+// Package pb holds the protobuf messages and the gRPC services generated from isildur.proto.
+package pb
+
+//go:generate protoc -I ../.. --go_out=../.. --go_opt=paths=source_relative --go-grpc_out=../.. --go-grpc_opt=paths=source_relative api/pb/isildur.proto
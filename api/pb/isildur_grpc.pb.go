@@ -0,0 +1,453 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             (unknown)
+// source: api/pb/isildur.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// SubscriptionServiceClient is the client API for SubscriptionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SubscriptionServiceClient interface {
+	// CreateSubscription creates a subscription to a product for the authenticated customer.
+	CreateSubscription(ctx context.Context, in *CreateSubscriptionRequest, opts ...grpc.CallOption) (*CreateSubscriptionResponse, error)
+	// FetchSubscription fetches a subscription by id.
+	FetchSubscription(ctx context.Context, in *FetchSubscriptionRequest, opts ...grpc.CallOption) (*Subscription, error)
+	// ListSubscriptions lists a page of subscriptions matching the filters.
+	ListSubscriptions(ctx context.Context, in *ListSubscriptionsRequest, opts ...grpc.CallOption) (*ListSubscriptionsResponse, error)
+	// ExportSubscriptions streams every subscription matching the filters, joined with its product.
+	ExportSubscriptions(ctx context.Context, in *ExportSubscriptionsRequest, opts ...grpc.CallOption) (SubscriptionService_ExportSubscriptionsClient, error)
+	// UpdateSubscriptionStatus updates the status of a subscription, if it is still at the
+	// expected version.
+	UpdateSubscriptionStatus(ctx context.Context, in *UpdateSubscriptionStatusRequest, opts ...grpc.CallOption) (*UpdateSubscriptionStatusResponse, error)
+}
+
+type subscriptionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSubscriptionServiceClient(cc grpc.ClientConnInterface) SubscriptionServiceClient {
+	return &subscriptionServiceClient{cc}
+}
+
+func (c *subscriptionServiceClient) CreateSubscription(ctx context.Context, in *CreateSubscriptionRequest, opts ...grpc.CallOption) (*CreateSubscriptionResponse, error) {
+	out := new(CreateSubscriptionResponse)
+	err := c.cc.Invoke(ctx, "/isildur.v1.SubscriptionService/CreateSubscription", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *subscriptionServiceClient) FetchSubscription(ctx context.Context, in *FetchSubscriptionRequest, opts ...grpc.CallOption) (*Subscription, error) {
+	out := new(Subscription)
+	err := c.cc.Invoke(ctx, "/isildur.v1.SubscriptionService/FetchSubscription", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *subscriptionServiceClient) ListSubscriptions(ctx context.Context, in *ListSubscriptionsRequest, opts ...grpc.CallOption) (*ListSubscriptionsResponse, error) {
+	out := new(ListSubscriptionsResponse)
+	err := c.cc.Invoke(ctx, "/isildur.v1.SubscriptionService/ListSubscriptions", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *subscriptionServiceClient) ExportSubscriptions(ctx context.Context, in *ExportSubscriptionsRequest, opts ...grpc.CallOption) (SubscriptionService_ExportSubscriptionsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SubscriptionService_ServiceDesc.Streams[0], "/isildur.v1.SubscriptionService/ExportSubscriptions", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &subscriptionServiceExportSubscriptionsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SubscriptionService_ExportSubscriptionsClient interface {
+	Recv() (*SubscriptionExportRow, error)
+	grpc.ClientStream
+}
+
+type subscriptionServiceExportSubscriptionsClient struct {
+	grpc.ClientStream
+}
+
+func (x *subscriptionServiceExportSubscriptionsClient) Recv() (*SubscriptionExportRow, error) {
+	m := new(SubscriptionExportRow)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *subscriptionServiceClient) UpdateSubscriptionStatus(ctx context.Context, in *UpdateSubscriptionStatusRequest, opts ...grpc.CallOption) (*UpdateSubscriptionStatusResponse, error) {
+	out := new(UpdateSubscriptionStatusResponse)
+	err := c.cc.Invoke(ctx, "/isildur.v1.SubscriptionService/UpdateSubscriptionStatus", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SubscriptionServiceServer is the server API for SubscriptionService service.
+// All implementations must embed UnimplementedSubscriptionServiceServer
+// for forward compatibility
+type SubscriptionServiceServer interface {
+	// CreateSubscription creates a subscription to a product for the authenticated customer.
+	CreateSubscription(context.Context, *CreateSubscriptionRequest) (*CreateSubscriptionResponse, error)
+	// FetchSubscription fetches a subscription by id.
+	FetchSubscription(context.Context, *FetchSubscriptionRequest) (*Subscription, error)
+	// ListSubscriptions lists a page of subscriptions matching the filters.
+	ListSubscriptions(context.Context, *ListSubscriptionsRequest) (*ListSubscriptionsResponse, error)
+	// ExportSubscriptions streams every subscription matching the filters, joined with its product.
+	ExportSubscriptions(*ExportSubscriptionsRequest, SubscriptionService_ExportSubscriptionsServer) error
+	// UpdateSubscriptionStatus updates the status of a subscription, if it is still at the
+	// expected version.
+	UpdateSubscriptionStatus(context.Context, *UpdateSubscriptionStatusRequest) (*UpdateSubscriptionStatusResponse, error)
+	mustEmbedUnimplementedSubscriptionServiceServer()
+}
+
+// UnimplementedSubscriptionServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedSubscriptionServiceServer struct {
+}
+
+func (UnimplementedSubscriptionServiceServer) CreateSubscription(context.Context, *CreateSubscriptionRequest) (*CreateSubscriptionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSubscription not implemented")
+}
+func (UnimplementedSubscriptionServiceServer) FetchSubscription(context.Context, *FetchSubscriptionRequest) (*Subscription, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchSubscription not implemented")
+}
+func (UnimplementedSubscriptionServiceServer) ListSubscriptions(context.Context, *ListSubscriptionsRequest) (*ListSubscriptionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSubscriptions not implemented")
+}
+func (UnimplementedSubscriptionServiceServer) ExportSubscriptions(*ExportSubscriptionsRequest, SubscriptionService_ExportSubscriptionsServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportSubscriptions not implemented")
+}
+func (UnimplementedSubscriptionServiceServer) UpdateSubscriptionStatus(context.Context, *UpdateSubscriptionStatusRequest) (*UpdateSubscriptionStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSubscriptionStatus not implemented")
+}
+func (UnimplementedSubscriptionServiceServer) mustEmbedUnimplementedSubscriptionServiceServer() {}
+
+// UnsafeSubscriptionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SubscriptionServiceServer will
+// result in compilation errors.
+type UnsafeSubscriptionServiceServer interface {
+	mustEmbedUnimplementedSubscriptionServiceServer()
+}
+
+func RegisterSubscriptionServiceServer(s grpc.ServiceRegistrar, srv SubscriptionServiceServer) {
+	s.RegisterService(&SubscriptionService_ServiceDesc, srv)
+}
+
+func _SubscriptionService_CreateSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubscriptionServiceServer).CreateSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/isildur.v1.SubscriptionService/CreateSubscription",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubscriptionServiceServer).CreateSubscription(ctx, req.(*CreateSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SubscriptionService_FetchSubscription_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchSubscriptionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubscriptionServiceServer).FetchSubscription(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/isildur.v1.SubscriptionService/FetchSubscription",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubscriptionServiceServer).FetchSubscription(ctx, req.(*FetchSubscriptionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SubscriptionService_ListSubscriptions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSubscriptionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubscriptionServiceServer).ListSubscriptions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/isildur.v1.SubscriptionService/ListSubscriptions",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubscriptionServiceServer).ListSubscriptions(ctx, req.(*ListSubscriptionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SubscriptionService_ExportSubscriptions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportSubscriptionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SubscriptionServiceServer).ExportSubscriptions(m, &subscriptionServiceExportSubscriptionsServer{stream})
+}
+
+type SubscriptionService_ExportSubscriptionsServer interface {
+	Send(*SubscriptionExportRow) error
+	grpc.ServerStream
+}
+
+type subscriptionServiceExportSubscriptionsServer struct {
+	grpc.ServerStream
+}
+
+func (x *subscriptionServiceExportSubscriptionsServer) Send(m *SubscriptionExportRow) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _SubscriptionService_UpdateSubscriptionStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSubscriptionStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SubscriptionServiceServer).UpdateSubscriptionStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/isildur.v1.SubscriptionService/UpdateSubscriptionStatus",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SubscriptionServiceServer).UpdateSubscriptionStatus(ctx, req.(*UpdateSubscriptionStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// SubscriptionService_ServiceDesc is the grpc.ServiceDesc for SubscriptionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SubscriptionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "isildur.v1.SubscriptionService",
+	HandlerType: (*SubscriptionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateSubscription",
+			Handler:    _SubscriptionService_CreateSubscription_Handler,
+		},
+		{
+			MethodName: "FetchSubscription",
+			Handler:    _SubscriptionService_FetchSubscription_Handler,
+		},
+		{
+			MethodName: "ListSubscriptions",
+			Handler:    _SubscriptionService_ListSubscriptions_Handler,
+		},
+		{
+			MethodName: "UpdateSubscriptionStatus",
+			Handler:    _SubscriptionService_UpdateSubscriptionStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportSubscriptions",
+			Handler:       _SubscriptionService_ExportSubscriptions_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "api/pb/isildur.proto",
+}
+
+// ProductsServiceClient is the client API for ProductsService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ProductsServiceClient interface {
+	// ListProducts lists every product.
+	ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error)
+	// FetchProduct fetches a product by id.
+	FetchProduct(ctx context.Context, in *FetchProductRequest, opts ...grpc.CallOption) (*Product, error)
+	// CreateProduct creates a product.
+	CreateProduct(ctx context.Context, in *CreateProductRequest, opts ...grpc.CallOption) (*Product, error)
+}
+
+type productsServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProductsServiceClient(cc grpc.ClientConnInterface) ProductsServiceClient {
+	return &productsServiceClient{cc}
+}
+
+func (c *productsServiceClient) ListProducts(ctx context.Context, in *ListProductsRequest, opts ...grpc.CallOption) (*ListProductsResponse, error) {
+	out := new(ListProductsResponse)
+	err := c.cc.Invoke(ctx, "/isildur.v1.ProductsService/ListProducts", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productsServiceClient) FetchProduct(ctx context.Context, in *FetchProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	out := new(Product)
+	err := c.cc.Invoke(ctx, "/isildur.v1.ProductsService/FetchProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *productsServiceClient) CreateProduct(ctx context.Context, in *CreateProductRequest, opts ...grpc.CallOption) (*Product, error) {
+	out := new(Product)
+	err := c.cc.Invoke(ctx, "/isildur.v1.ProductsService/CreateProduct", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProductsServiceServer is the server API for ProductsService service.
+// All implementations must embed UnimplementedProductsServiceServer
+// for forward compatibility
+type ProductsServiceServer interface {
+	// ListProducts lists every product.
+	ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error)
+	// FetchProduct fetches a product by id.
+	FetchProduct(context.Context, *FetchProductRequest) (*Product, error)
+	// CreateProduct creates a product.
+	CreateProduct(context.Context, *CreateProductRequest) (*Product, error)
+	mustEmbedUnimplementedProductsServiceServer()
+}
+
+// UnimplementedProductsServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedProductsServiceServer struct {
+}
+
+func (UnimplementedProductsServiceServer) ListProducts(context.Context, *ListProductsRequest) (*ListProductsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListProducts not implemented")
+}
+func (UnimplementedProductsServiceServer) FetchProduct(context.Context, *FetchProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method FetchProduct not implemented")
+}
+func (UnimplementedProductsServiceServer) CreateProduct(context.Context, *CreateProductRequest) (*Product, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateProduct not implemented")
+}
+func (UnimplementedProductsServiceServer) mustEmbedUnimplementedProductsServiceServer() {}
+
+// UnsafeProductsServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProductsServiceServer will
+// result in compilation errors.
+type UnsafeProductsServiceServer interface {
+	mustEmbedUnimplementedProductsServiceServer()
+}
+
+func RegisterProductsServiceServer(s grpc.ServiceRegistrar, srv ProductsServiceServer) {
+	s.RegisterService(&ProductsService_ServiceDesc, srv)
+}
+
+func _ProductsService_ListProducts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListProductsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductsServiceServer).ListProducts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/isildur.v1.ProductsService/ListProducts",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductsServiceServer).ListProducts(ctx, req.(*ListProductsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductsService_FetchProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FetchProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductsServiceServer).FetchProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/isildur.v1.ProductsService/FetchProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductsServiceServer).FetchProduct(ctx, req.(*FetchProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProductsService_CreateProduct_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateProductRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProductsServiceServer).CreateProduct(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/isildur.v1.ProductsService/CreateProduct",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProductsServiceServer).CreateProduct(ctx, req.(*CreateProductRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ProductsService_ServiceDesc is the grpc.ServiceDesc for ProductsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ProductsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "isildur.v1.ProductsService",
+	HandlerType: (*ProductsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListProducts",
+			Handler:    _ProductsService_ListProducts_Handler,
+		},
+		{
+			MethodName: "FetchProduct",
+			Handler:    _ProductsService_FetchProduct_Handler,
+		},
+		{
+			MethodName: "CreateProduct",
+			Handler:    _ProductsService_CreateProduct_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "api/pb/isildur.proto",
+}
@@ -0,0 +1,82 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// apiKeyMetadata carries the API keys of the partners, also accepted as bearer tokens.
+const apiKeyMetadata = "x-api-key"
+
+// UnaryAuthenticate authenticates the unary calls like the http apis, with a JWT or an API key
+// in the authorization metadata or an API key in the x-api-key metadata.
+func UnaryAuthenticate(verifier ports.TokenVerifier, keys ports.APIKeyService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, verifier, keys)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthenticate authenticates the streaming calls, see UnaryAuthenticate.
+func StreamAuthenticate(verifier ports.TokenVerifier, keys ports.APIKeyService) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), verifier, keys)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// authenticate returns the context of a call with the principal of its credentials.
+func authenticate(ctx context.Context, verifier ports.TokenVerifier, keys ports.APIKeyService) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if values := md.Get(key); len(values) > 0 {
+			return values[0]
+		}
+		return ""
+	}
+
+	var principal domain.Principal
+	var err error
+	header := first("authorization")
+	token := strings.TrimSpace(strings.TrimPrefix(header, "Bearer "))
+	switch {
+	case first(apiKeyMetadata) != "":
+		principal, err = keys.AuthenticateAPIKey(ctx, first(apiKeyMetadata))
+	case !strings.HasPrefix(header, "Bearer ") || token == "":
+		err = domain.ErrUnauthenticated
+	case strings.HasPrefix(token, domain.APIKeyPrefix):
+		principal, err = keys.AuthenticateAPIKey(ctx, token)
+	default:
+		principal, err = verifier.Verify(token)
+	}
+	if err != nil {
+		// Don't tell why the credentials were refused
+		if errors.Is(err, domain.ErrUnauthenticated) {
+			err = domain.ErrUnauthenticated
+		}
+		return nil, statusFromError(err)
+	}
+	return domain.ContextWithPrincipal(ctx, principal), nil
+}
+
+// contextStream overrides the context of a stream, e.g. with the authenticated one.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
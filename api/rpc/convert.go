@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/goakshit/isildur/api/pb"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+var statusToPB = map[domain.SubscriptionStatus]pb.SubscriptionStatus{
+	domain.SubscriptionStatusActive:   pb.SubscriptionStatus_SUBSCRIPTION_STATUS_ACTIVE,
+	domain.SubscriptionStatusPaused:   pb.SubscriptionStatus_SUBSCRIPTION_STATUS_PAUSED,
+	domain.SubscriptionStatusCancel:   pb.SubscriptionStatus_SUBSCRIPTION_STATUS_CANCELLED,
+	domain.SubscriptionStatusInactive: pb.SubscriptionStatus_SUBSCRIPTION_STATUS_INACTIVE,
+}
+
+// fromStatus maps a protobuf status to the domain status, empty when unspecified.
+func fromStatus(status pb.SubscriptionStatus) domain.SubscriptionStatus {
+	for s, p := range statusToPB {
+		if p == status {
+			return s
+		}
+	}
+	return ""
+}
+
+// fromFilter maps the protobuf filter to the domain filter.
+func fromFilter(filter *pb.SubscriptionFilter) (domain.SubscriptionFilter, error) {
+	f := domain.SubscriptionFilter{Status: fromStatus(filter.GetStatus())}
+	if filter.GetProductId() != "" {
+		pID, err := uuid.Parse(filter.GetProductId())
+		if err != nil {
+			return f, domain.ErrProductIDIsInvalid
+		}
+		f.ProductID = pID
+	}
+	return f, nil
+}
+
+func toSubscription(sub domain.Subscription) *pb.Subscription {
+	return &pb.Subscription{
+		Id:               sub.ID.String(),
+		CustomerId:       sub.CustomerID,
+		DurationInMonths: int32(sub.DurationInMonths),
+		Tax:              sub.Tax,
+		TotalCost:        sub.TotalCost,
+		Status:           statusToPB[sub.Status],
+		StartDate:        timestamppb.New(sub.StartDate),
+		EndDate:          timestamppb.New(sub.EndDate),
+		Version:          int64(sub.Version),
+		CancelledAt:      toTimestamp(sub.CancelledAt),
+		PausedAt:         toTimestamp(sub.PausedAt),
+		ResumedAt:        toTimestamp(sub.ResumedAt),
+	}
+}
+
+func toExportRow(row domain.SubscriptionExportRow) *pb.SubscriptionExportRow {
+	return &pb.SubscriptionExportRow{
+		Id:                  row.ID.String(),
+		ProductId:           row.ProductID.String(),
+		ProductName:         row.ProductName,
+		ProductMonthlyPrice: row.ProductMonthlyPrice,
+		CustomerId:          row.CustomerID,
+		DurationInMonths:    int32(row.DurationInMonths),
+		Tax:                 row.Tax,
+		TotalCost:           row.TotalCost,
+		Status:              statusToPB[row.Status],
+		StartDate:           timestamppb.New(row.StartDate),
+		EndDate:             timestamppb.New(row.EndDate),
+		Version:             int64(row.Version),
+	}
+}
+
+func toProduct(product domain.Product) *pb.Product {
+	return &pb.Product{
+		Id:             product.ID.String(),
+		Name:           product.Name,
+		Description:    product.Description,
+		MonthlyPrice:   product.MonthlyPrice,
+		InstructorName: product.InstructorName,
+	}
+}
+
+// toTimestamp maps an optional time, nil staying unset.
+func toTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,48 @@
+package rpc
+
+import (
+	"github.com/goakshit/isildur/core/domain"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// codeByKind maps the kinds of the domain errors to the gRPC status codes.
+var codeByKind = map[domain.ErrorKind]codes.Code{
+	domain.KindNotFound:           codes.NotFound,
+	domain.KindInvalid:            codes.InvalidArgument,
+	domain.KindFailedPrecondition: codes.FailedPrecondition,
+	domain.KindVersionRequired:    codes.FailedPrecondition,
+	// The caller should fetch the subscription again and retry
+	domain.KindConflict:        codes.Aborted,
+	domain.KindUnauthenticated: codes.Unauthenticated,
+	domain.KindForbidden:       codes.PermissionDenied,
+	domain.KindRateLimited:     codes.ResourceExhausted,
+}
+
+// statusFromError maps the domain errors to the gRPC status codes. Unknown errors are internal,
+// their message kept for the logs but not sent to the caller.
+func statusFromError(err error) error {
+	code, ok := codeByKind[domain.KindOf(err)]
+	if !ok {
+		return internalError{err: err}
+	}
+	return status.Error(code, err.Error())
+}
+
+// internalError is an unknown error sent to the caller as a generic internal status.
+type internalError struct {
+	err error
+}
+
+func (e internalError) Error() string {
+	return e.err.Error()
+}
+
+func (e internalError) Unwrap() error {
+	return e.err
+}
+
+// GRPCStatus returns the status sent to the caller.
+func (e internalError) GRPCStatus() *status.Status {
+	return status.New(codes.Internal, "internal error")
+}
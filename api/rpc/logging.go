@@ -0,0 +1,65 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDMetadata carries the id correlating the logs of a call, like the X-Request-ID header
+// of the http apis.
+const requestIDMetadata = "x-request-id"
+
+// UnaryLog puts the request id in the context of the unary calls, and logs them once served.
+func UnaryLog(log ports.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		ctx = withRequestID(ctx)
+		resp, err := handler(ctx, req)
+		logCall(ctx, log, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+// StreamLog puts the request id in the context of the streaming calls, see UnaryLog.
+func StreamLog(log ports.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx := withRequestID(stream.Context())
+		err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+		logCall(ctx, log, info.FullMethod, start, err)
+		return err
+	}
+}
+
+// withRequestID returns the context of a call with its request id, also sent back in the
+// response headers.
+func withRequestID(ctx context.Context) context.Context {
+	var passed string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadata); len(values) > 0 {
+			passed = values[0]
+		}
+	}
+	id := domain.NewRequestID(passed)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+	return domain.ContextWithRequestID(ctx, id)
+}
+
+// logCall logs a served call, as an error when the server failed.
+func logCall(ctx context.Context, log ports.Logger, method string, start time.Time, err error) {
+	code := status.Code(err)
+	fields := []interface{}{"method", method, "code", code.String(), "duration_ms", time.Since(start)}
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		log.Error(ctx, "call failed", append(fields, "error", err)...)
+	default:
+		log.Info(ctx, "call served", fields...)
+	}
+}
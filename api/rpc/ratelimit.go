@@ -0,0 +1,92 @@
+package rpc
+
+import (
+	"context"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goakshit/isildur/api/pb"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// serviceGroups maps the services to the route groups of the matching http apis, sharing their
+// rate limits.
+var serviceGroups = map[string]string{
+	pb.SubscriptionService_ServiceDesc.ServiceName: domain.RateLimitGroupSubscription,
+	pb.ProductsService_ServiceDesc.ServiceName:     domain.RateLimitGroupProducts,
+}
+
+// UnaryRateLimit limits the unary calls of every client like the http apis, in the route group
+// returned by group for the called method. Clients are told apart by their principal, or by the
+// IP they connect from when not authenticated. Limited calls fail with ResourceExhausted, the
+// retry-after trailer telling in how many seconds to retry.
+func UnaryRateLimit(limiter ports.RateLimiter, limits domain.RateLimits, group func(method string) string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if trailer, err := allow(ctx, limiter, limits, group(info.FullMethod)); err != nil {
+			_ = grpc.SetTrailer(ctx, trailer)
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamRateLimit limits the streaming calls of every client, see UnaryRateLimit.
+func StreamRateLimit(limiter ports.RateLimiter, limits domain.RateLimits, group func(method string) string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if trailer, err := allow(stream.Context(), limiter, limits, group(info.FullMethod)); err != nil {
+			stream.SetTrailer(trailer)
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// authGroup limits every call by IP before it is authenticated.
+func authGroup(string) string {
+	return domain.RateLimitGroupAuth
+}
+
+// methodGroup returns the route group of the service of a method, e.g.
+// /isildur.v1.ProductsService/ListProducts.
+func methodGroup(method string) string {
+	service, _, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	return serviceGroups[service]
+}
+
+// allow takes a token from the bucket of the caller in a group, returning the trailer of the
+// limited calls with their error.
+func allow(ctx context.Context, limiter ports.RateLimiter, limits domain.RateLimits, group string) (metadata.MD, error) {
+	client := "ip:" + peerIP(ctx)
+	if principal, ok := domain.PrincipalFromContext(ctx); ok {
+		client = "sub:" + principal.Subject
+	}
+	result, err := limiter.Allow(ctx, group+":"+client, limits.For(group), time.Now())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	if !result.Allowed {
+		retryAfter := int(math.Ceil(result.RetryAfter.Seconds()))
+		return metadata.Pairs("retry-after", strconv.Itoa(retryAfter)), statusFromError(domain.ErrRateLimited)
+	}
+	return nil, nil
+}
+
+// peerIP returns the IP of the client of a call, or its address if it isn't an IP address.
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	addr := p.Addr.String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
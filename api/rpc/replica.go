@@ -0,0 +1,41 @@
+package rpc
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/goakshit/isildur/core/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// primaryReadsMetadata forces the reads of a call on the primary database when true, like the
+// X-Read-Primary header of the http apis.
+const primaryReadsMetadata = "x-read-primary"
+
+// UnaryPrimaryReads sends the reads of the unary calls passing x-read-primary to the primary
+// database instead of the read replica, if any.
+func UnaryPrimaryReads() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withPrimaryReads(ctx), req)
+	}
+}
+
+// StreamPrimaryReads sends the reads of the streaming calls to the primary database, see
+// UnaryPrimaryReads.
+func StreamPrimaryReads() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &contextStream{ServerStream: stream, ctx: withPrimaryReads(stream.Context())})
+	}
+}
+
+// withPrimaryReads returns the context of a call forcing its reads on the primary, if asked.
+func withPrimaryReads(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(primaryReadsMetadata); len(values) > 0 {
+		if primary, _ := strconv.ParseBool(values[0]); primary {
+			return domain.ContextWithPrimaryReads(ctx)
+		}
+	}
+	return ctx
+}
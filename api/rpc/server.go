@@ -0,0 +1,228 @@
+// Package rpc serves the subscriptions and products services over gRPC, next to the http apis.
+package rpc
+
+import (
+	"context"
+	"math"
+
+	"github.com/goakshit/isildur/api/pb"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/goakshit/isildur/platform/metrics"
+	"github.com/goakshit/isildur/repositories"
+	"github.com/goakshit/isildur/services"
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// ServerOptions holds the optional interceptors dependencies of the server.
+type ServerOptions struct {
+	// Verifier verifies the JWTs. Every call requires a JWT or an API key, unless it is nil.
+	Verifier ports.TokenVerifier
+	// RateLimiter limits the calls of the clients to the RateLimits of the route groups of the
+	// http apis, unless it is nil.
+	RateLimiter ports.RateLimiter
+	RateLimits  domain.RateLimits
+	// Logger logs the calls, which aren't logged if it is nil.
+	Logger ports.Logger
+	// Metrics records the business metrics, which aren't exposed if it is nil.
+	Metrics *metrics.Metrics
+	// Credentials secures the connections, served in plaintext if it is nil.
+	Credentials credentials.TransportCredentials
+}
+
+// NewServer intialises the services and registers them on a new gRPC server.
+func NewServer(repos repositories.Repositories, opts ServerOptions) *grpc.Server {
+	log := opts.Logger
+	if log == nil {
+		log = logger.Nop()
+	}
+	// Every call gets a request id first, for the authentication failures to be logged with it
+	unary := []grpc.UnaryServerInterceptor{UnaryLog(log), UnaryPrimaryReads()}
+	stream := []grpc.StreamServerInterceptor{StreamLog(log), StreamPrimaryReads()}
+	// The clients are limited by IP before being authenticated, like the http apis, then per
+	// service once told apart by their principal
+	if opts.RateLimiter != nil {
+		unary = append(unary, UnaryRateLimit(opts.RateLimiter, opts.RateLimits, authGroup))
+		stream = append(stream, StreamRateLimit(opts.RateLimiter, opts.RateLimits, authGroup))
+	}
+	if opts.Verifier != nil {
+		keys := services.NewAPIKeyService(repos.APIKeys)
+		unary = append(unary, UnaryAuthenticate(opts.Verifier, keys))
+		stream = append(stream, StreamAuthenticate(opts.Verifier, keys))
+	}
+	if opts.RateLimiter != nil {
+		unary = append(unary, UnaryRateLimit(opts.RateLimiter, opts.RateLimits, methodGroup))
+		stream = append(stream, StreamRateLimit(opts.RateLimiter, opts.RateLimits, methodGroup))
+	}
+	serverOpts := []grpc.ServerOption{grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...)}
+	if opts.Credentials != nil {
+		serverOpts = append(serverOpts, grpc.Creds(opts.Credentials))
+	}
+	server := grpc.NewServer(serverOpts...)
+
+	m := metrics.Nop()
+	if opts.Metrics != nil {
+		m = opts.Metrics
+	}
+
+	recognitionSvc := services.NewRecognitionService(repos.RevenueEntries, repos.Subscriptions, repos.Tx, m)
+	subsSvc := services.NewSubscriptionService(repos.Subscriptions, repos.Products, repos.Tx, recognitionSvc, m)
+	productsSvc := services.NewProductsService(repos.Products)
+	pb.RegisterSubscriptionServiceServer(server, NewSubscriptionServer(subsSvc))
+	pb.RegisterProductsServiceServer(server, NewProductsServer(productsSvc))
+	return server
+}
+
+// SubscriptionServer serves the subscriptions service.
+type SubscriptionServer struct {
+	pb.UnimplementedSubscriptionServiceServer
+	Subs ports.SubscriptionService
+}
+
+// NewSubscriptionServer returns a new SubscriptionServer.
+func NewSubscriptionServer(subs ports.SubscriptionService) *SubscriptionServer {
+	return &SubscriptionServer{
+		Subs: subs,
+	}
+}
+
+// CreateSubscription creates a subscription for a given product.
+func (s *SubscriptionServer) CreateSubscription(ctx context.Context, req *pb.CreateSubscriptionRequest) (*pb.CreateSubscriptionResponse, error) {
+	pID, err := uuid.Parse(req.GetProductId())
+	if err != nil {
+		return nil, statusFromError(domain.ErrProductIDIsInvalid)
+	}
+	if req.GetStartDate() == nil {
+		return nil, statusFromError(domain.ErrInvalidStartDate)
+	}
+	if req.GetDurationInMonths() <= 0 || req.GetDurationInMonths() > math.MaxInt8 {
+		return nil, statusFromError(domain.ErrInvalidSubscriptionDuration)
+	}
+	err = s.Subs.CreateSubscription(ctx, pID, int8(req.GetDurationInMonths()), req.GetStartDate().AsTime())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return &pb.CreateSubscriptionResponse{}, nil
+}
+
+// FetchSubscription fetches subscription details for given id.
+func (s *SubscriptionServer) FetchSubscription(ctx context.Context, req *pb.FetchSubscriptionRequest) (*pb.Subscription, error) {
+	sID, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, statusFromError(domain.ErrSubscriptionIDIsInvalid)
+	}
+	sub, err := s.Subs.FetchSubscription(ctx, sID)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return toSubscription(sub), nil
+}
+
+// ListSubscriptions lists a page of subscriptions matching the filter.
+func (s *SubscriptionServer) ListSubscriptions(ctx context.Context, req *pb.ListSubscriptionsRequest) (*pb.ListSubscriptionsResponse, error) {
+	filter, err := fromFilter(req.GetFilter())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	filter.Limit, filter.Offset = int(req.GetLimit()), int(req.GetOffset())
+	subs, err := s.Subs.ListSubscriptions(ctx, filter)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	resp := &pb.ListSubscriptionsResponse{Subscriptions: make([]*pb.Subscription, 0, len(subs))}
+	for _, sub := range subs {
+		resp.Subscriptions = append(resp.Subscriptions, toSubscription(sub))
+	}
+	return resp, nil
+}
+
+// ExportSubscriptions streams every subscription matching the filter, joined with its product.
+func (s *SubscriptionServer) ExportSubscriptions(req *pb.ExportSubscriptionsRequest, stream pb.SubscriptionService_ExportSubscriptionsServer) error {
+	filter, err := fromFilter(req.GetFilter())
+	if err != nil {
+		return statusFromError(err)
+	}
+	err = s.Subs.ExportSubscriptions(stream.Context(), filter, func(row domain.SubscriptionExportRow) error {
+		return stream.Send(toExportRow(row))
+	})
+	if err != nil {
+		return statusFromError(err)
+	}
+	return nil
+}
+
+// UpdateSubscriptionStatus updates the status of a subscription at the expected version and
+// responds with its new version.
+func (s *SubscriptionServer) UpdateSubscriptionStatus(ctx context.Context, req *pb.UpdateSubscriptionStatusRequest) (*pb.UpdateSubscriptionStatusResponse, error) {
+	sID, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, statusFromError(domain.ErrSubscriptionIDIsInvalid)
+	}
+	status := fromStatus(req.GetStatus())
+	if status == "" {
+		return nil, statusFromError(domain.ErrInvalidSubscriptionStatusPassed)
+	}
+	if req.GetVersion() <= 0 {
+		return nil, statusFromError(domain.ErrSubscriptionVersionRequired)
+	}
+	if err := s.Subs.UpdateSubscriptionStatus(ctx, sID, int(req.GetVersion()), status); err != nil {
+		return nil, statusFromError(err)
+	}
+	return &pb.UpdateSubscriptionStatusResponse{Version: req.GetVersion() + 1}, nil
+}
+
+// ProductsServer serves the products service.
+type ProductsServer struct {
+	pb.UnimplementedProductsServiceServer
+	Products ports.ProductsService
+}
+
+// NewProductsServer returns a new ProductsServer.
+func NewProductsServer(products ports.ProductsService) *ProductsServer {
+	return &ProductsServer{
+		Products: products,
+	}
+}
+
+// ListProducts lists all the available products.
+func (s *ProductsServer) ListProducts(ctx context.Context, _ *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
+	products, err := s.Products.FetchAllProducts(ctx)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	resp := &pb.ListProductsResponse{Products: make([]*pb.Product, 0, len(products))}
+	for _, product := range products {
+		resp.Products = append(resp.Products, toProduct(product))
+	}
+	return resp, nil
+}
+
+// FetchProduct fetches product details for given id.
+func (s *ProductsServer) FetchProduct(ctx context.Context, req *pb.FetchProductRequest) (*pb.Product, error) {
+	pID, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, statusFromError(domain.ErrProductIDIsInvalid)
+	}
+	product, err := s.Products.FetchProduct(ctx, pID)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return toProduct(product), nil
+}
+
+// CreateProduct creates a product and responds with it.
+func (s *ProductsServer) CreateProduct(ctx context.Context, req *pb.CreateProductRequest) (*pb.Product, error) {
+	product, err := s.Products.CreateProduct(ctx, domain.Product{
+		Name:           req.GetName(),
+		Description:    req.GetDescription(),
+		MonthlyPrice:   req.GetMonthlyPrice(),
+		InstructorName: req.GetInstructorName(),
+	})
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return toProduct(product), nil
+}
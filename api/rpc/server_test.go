@@ -0,0 +1,436 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/api/pb"
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/goakshit/isildur/repositories"
+	"github.com/goakshit/isildur/repositories/memory"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// principals are the callers authenticated by the test tokens.
+var principals = map[string]domain.Principal{
+	"customer-1": {Subject: "customer-1", Roles: []domain.Role{domain.RoleCustomer}},
+	"customer-2": {Subject: "customer-2", Roles: []domain.Role{domain.RoleCustomer}},
+	"support":    {Subject: "support-1", Roles: []domain.Role{domain.RoleSupport}},
+	"admin":      {Subject: "admin-1", Roles: []domain.Role{domain.RoleAdmin}},
+}
+
+type ServerTestSuite struct {
+	suite.Suite
+	product  domain.Product
+	server   *grpc.Server
+	conn     *grpc.ClientConn
+	subs     pb.SubscriptionServiceClient
+	products pb.ProductsServiceClient
+	logs     *bytes.Buffer
+	repos    repositories.Repositories
+	verifier ports.TokenVerifier
+}
+
+func TestServerTestSuite(t *testing.T) {
+	suite.Run(t, new(ServerTestSuite))
+}
+
+func (ts *ServerTestSuite) SetupTest() {
+	ts.product = domain.Product{
+		ID:             uuid.New(),
+		Name:           "YOGA 1",
+		Description:    "BASIC YOGA",
+		MonthlyPrice:   5,
+		InstructorName: "A. Dhar",
+	}
+	store := memory.NewStore()
+	store.Seed(memory.Fixtures{Products: []domain.Product{ts.product}})
+
+	verifier := ports.NewMockTokenVerifier(gomock.NewController(ts.T()))
+	verifier.EXPECT().Verify(gomock.Any()).AnyTimes().DoAndReturn(func(token string) (domain.Principal, error) {
+		if p, ok := principals[token]; ok {
+			return p, nil
+		}
+		return domain.Principal{}, domain.ErrUnauthenticated
+	})
+
+	ts.logs = &bytes.Buffer{}
+	ts.repos = memory.NewRepositories(store)
+	ts.verifier = verifier
+	ts.start(ServerOptions{
+		Verifier: verifier,
+		Logger:   logger.New(logger.LevelRelease, ts.logs),
+	})
+}
+
+// start serves the services with opts and connects the clients to them.
+func (ts *ServerTestSuite) start(opts ServerOptions) {
+	lis := bufconn.Listen(1024 * 1024)
+	ts.server = NewServer(ts.repos, opts)
+	go func() { _ = ts.server.Serve(lis) }()
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	ts.Require().Nil(err)
+	ts.conn = conn
+	ts.subs = pb.NewSubscriptionServiceClient(conn)
+	ts.products = pb.NewProductsServiceClient(conn)
+}
+
+func (ts *ServerTestSuite) TearDownTest() {
+	ts.conn.Close()
+	ts.server.Stop()
+}
+
+// as returns a context authenticating the calls with a test token.
+func as(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+// createSubscription creates a subscription for a customer and returns it.
+func (ts *ServerTestSuite) createSubscription(customer string) *pb.Subscription {
+	_, err := ts.subs.CreateSubscription(as(customer), &pb.CreateSubscriptionRequest{
+		ProductId:        ts.product.ID.String(),
+		StartDate:        timestamppb.New(time.Now().AddDate(0, 0, 2)),
+		DurationInMonths: 3,
+	})
+	ts.Require().Nil(err)
+	resp, err := ts.subs.ListSubscriptions(as(customer), &pb.ListSubscriptionsRequest{})
+	ts.Require().Nil(err)
+	ts.Require().NotEmpty(resp.Subscriptions)
+	return resp.Subscriptions[len(resp.Subscriptions)-1]
+}
+
+func (ts *ServerTestSuite) TestSubscriptionLifecycle() {
+	created := ts.createSubscription("customer-1")
+	ts.Assert().Equal("customer-1", created.CustomerId)
+	ts.Assert().Equal(pb.SubscriptionStatus_SUBSCRIPTION_STATUS_INACTIVE, created.Status)
+	ts.Assert().EqualValues(3, created.DurationInMonths)
+	ts.Assert().EqualValues(1, created.Version)
+
+	fetched, err := ts.subs.FetchSubscription(as("customer-1"), &pb.FetchSubscriptionRequest{Id: created.Id})
+	ts.Require().Nil(err)
+	ts.Assert().Equal(created.Id, fetched.Id)
+	ts.Assert().Nil(fetched.CancelledAt)
+
+	updated, err := ts.subs.UpdateSubscriptionStatus(as("customer-1"), &pb.UpdateSubscriptionStatusRequest{
+		Id:      created.Id,
+		Version: 1,
+		Status:  pb.SubscriptionStatus_SUBSCRIPTION_STATUS_CANCELLED,
+	})
+	ts.Require().Nil(err)
+	ts.Assert().EqualValues(2, updated.Version)
+
+	fetched, err = ts.subs.FetchSubscription(as("customer-1"), &pb.FetchSubscriptionRequest{Id: created.Id})
+	ts.Require().Nil(err)
+	ts.Assert().Equal(pb.SubscriptionStatus_SUBSCRIPTION_STATUS_CANCELLED, fetched.Status)
+	ts.Assert().NotNil(fetched.CancelledAt)
+}
+
+func (ts *ServerTestSuite) TestListSubscriptions_ScopedToCustomer() {
+	ts.createSubscription("customer-1")
+	ts.createSubscription("customer-2")
+
+	resp, err := ts.subs.ListSubscriptions(as("customer-1"), &pb.ListSubscriptionsRequest{})
+	ts.Require().Nil(err)
+	ts.Assert().Len(resp.Subscriptions, 1)
+
+	resp, err = ts.subs.ListSubscriptions(as("support"), &pb.ListSubscriptionsRequest{
+		Filter: &pb.SubscriptionFilter{
+			Status:    pb.SubscriptionStatus_SUBSCRIPTION_STATUS_INACTIVE,
+			ProductId: ts.product.ID.String(),
+		},
+		Limit: 10,
+	})
+	ts.Require().Nil(err)
+	ts.Assert().Len(resp.Subscriptions, 2)
+}
+
+func (ts *ServerTestSuite) TestExportSubscriptions() {
+	ts.createSubscription("customer-1")
+	ts.createSubscription("customer-2")
+
+	stream, err := ts.subs.ExportSubscriptions(as("support"), &pb.ExportSubscriptionsRequest{})
+	ts.Require().Nil(err)
+	var rows []*pb.SubscriptionExportRow
+	for {
+		row, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		ts.Require().Nil(err)
+		rows = append(rows, row)
+	}
+	ts.Require().Len(rows, 2)
+	ts.Assert().Equal(ts.product.Name, rows[0].ProductName)
+	ts.Assert().Equal(ts.product.ID.String(), rows[0].ProductId)
+
+	// Customers can't export, the error comes with the first message
+	stream, err = ts.subs.ExportSubscriptions(as("customer-1"), &pb.ExportSubscriptionsRequest{})
+	ts.Require().Nil(err)
+	_, err = stream.Recv()
+	ts.Assert().Equal(codes.PermissionDenied, status.Code(err))
+}
+
+func (ts *ServerTestSuite) TestProducts() {
+	list, err := ts.products.ListProducts(as("customer-1"), &pb.ListProductsRequest{})
+	ts.Require().Nil(err)
+	ts.Require().Len(list.Products, 1)
+	ts.Assert().Equal(ts.product.Name, list.Products[0].Name)
+
+	created, err := ts.products.CreateProduct(as("admin"), &pb.CreateProductRequest{
+		Name:           "PILATES",
+		MonthlyPrice:   12,
+		InstructorName: "B. Kaur",
+	})
+	ts.Require().Nil(err)
+	ts.Assert().NotEmpty(created.Id)
+
+	fetched, err := ts.products.FetchProduct(as("customer-1"), &pb.FetchProductRequest{Id: created.Id})
+	ts.Require().Nil(err)
+	ts.Assert().Equal("PILATES", fetched.Name)
+	ts.Assert().Equal(12.0, fetched.MonthlyPrice)
+}
+
+func (ts *ServerTestSuite) TestRequestID() {
+	var header metadata.MD
+	ctx := metadata.AppendToOutgoingContext(as("customer-1"), "x-request-id", "req-1")
+	_, err := ts.products.ListProducts(ctx, &pb.ListProductsRequest{}, grpc.Header(&header))
+	ts.Require().Nil(err)
+	ts.Assert().Equal([]string{"req-1"}, header.Get("x-request-id"))
+
+	entry := map[string]interface{}{}
+	ts.Require().Nil(json.Unmarshal(ts.logs.Bytes(), &entry))
+	ts.Assert().Equal("call served", entry["message"])
+	ts.Assert().Equal("req-1", entry["request_id"])
+	ts.Assert().Equal("/isildur.v1.ProductsService/ListProducts", entry["method"])
+	ts.Assert().Equal("OK", entry["code"])
+
+	// Generated when missing, even for the calls failing authentication
+	_, err = ts.products.ListProducts(context.Background(), &pb.ListProductsRequest{}, grpc.Header(&header))
+	ts.Assert().Equal(codes.Unauthenticated, status.Code(err))
+	_, err = uuid.Parse(header.Get("x-request-id")[0])
+	ts.Assert().Nil(err)
+}
+
+func (ts *ServerTestSuite) TestErrorCodes() {
+	sub := ts.createSubscription("customer-1")
+
+	tt := []struct {
+		name         string
+		call         func() error
+		expectedCode codes.Code
+	}{
+		{
+			name: "Missing credentials",
+			call: func() error {
+				_, err := ts.products.ListProducts(context.Background(), &pb.ListProductsRequest{})
+				return err
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name: "Invalid token",
+			call: func() error {
+				_, err := ts.products.ListProducts(as("someone"), &pb.ListProductsRequest{})
+				return err
+			},
+			expectedCode: codes.Unauthenticated,
+		},
+		{
+			name: "Unknown product",
+			call: func() error {
+				_, err := ts.products.FetchProduct(as("customer-1"), &pb.FetchProductRequest{Id: uuid.NewString()})
+				return err
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name: "Subscription of another customer",
+			call: func() error {
+				_, err := ts.subs.FetchSubscription(as("customer-2"), &pb.FetchSubscriptionRequest{Id: sub.Id})
+				return err
+			},
+			expectedCode: codes.NotFound,
+		},
+		{
+			name: "Invalid subscription id",
+			call: func() error {
+				_, err := ts.subs.FetchSubscription(as("customer-1"), &pb.FetchSubscriptionRequest{Id: "not-a-uuid"})
+				return err
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "Invalid duration",
+			call: func() error {
+				_, err := ts.subs.CreateSubscription(as("customer-1"), &pb.CreateSubscriptionRequest{
+					ProductId:        ts.product.ID.String(),
+					StartDate:        timestamppb.New(time.Now().AddDate(0, 0, 2)),
+					DurationInMonths: 200,
+				})
+				return err
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "Missing start date",
+			call: func() error {
+				_, err := ts.subs.CreateSubscription(as("customer-1"), &pb.CreateSubscriptionRequest{
+					ProductId:        ts.product.ID.String(),
+					DurationInMonths: 3,
+				})
+				return err
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "Missing status",
+			call: func() error {
+				_, err := ts.subs.UpdateSubscriptionStatus(as("customer-1"), &pb.UpdateSubscriptionStatusRequest{
+					Id:      sub.Id,
+					Version: 1,
+				})
+				return err
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+		{
+			name: "Missing version",
+			call: func() error {
+				_, err := ts.subs.UpdateSubscriptionStatus(as("customer-1"), &pb.UpdateSubscriptionStatusRequest{
+					Id:     sub.Id,
+					Status: pb.SubscriptionStatus_SUBSCRIPTION_STATUS_CANCELLED,
+				})
+				return err
+			},
+			expectedCode: codes.FailedPrecondition,
+		},
+		{
+			name: "Stale version",
+			call: func() error {
+				_, err := ts.subs.UpdateSubscriptionStatus(as("customer-1"), &pb.UpdateSubscriptionStatusRequest{
+					Id:      sub.Id,
+					Version: 5,
+					Status:  pb.SubscriptionStatus_SUBSCRIPTION_STATUS_CANCELLED,
+				})
+				return err
+			},
+			expectedCode: codes.Aborted,
+		},
+		{
+			name: "Product created by a customer",
+			call: func() error {
+				_, err := ts.products.CreateProduct(as("customer-1"), &pb.CreateProductRequest{Name: "PILATES", MonthlyPrice: 12})
+				return err
+			},
+			expectedCode: codes.PermissionDenied,
+		},
+		{
+			name: "Invalid product",
+			call: func() error {
+				_, err := ts.products.CreateProduct(as("admin"), &pb.CreateProductRequest{Name: "PILATES"})
+				return err
+			},
+			expectedCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			ts.Assert().Equal(tc.expectedCode, status.Code(tc.call()))
+		})
+	}
+}
+
+func (ts *ServerTestSuite) TestRateLimit() {
+	ts.TearDownTest()
+	ts.start(ServerOptions{
+		Verifier:    ts.verifier,
+		RateLimiter: memory.NewRateLimiter(),
+		RateLimits: domain.RateLimits{
+			Default: domain.RateLimit{Requests: 2, Per: time.Minute},
+			Groups:  map[string]domain.RateLimit{domain.RateLimitGroupAuth: {Requests: 7, Per: time.Minute}},
+		},
+	})
+
+	// The callers are limited per service once authenticated
+	for i := 0; i < 2; i++ {
+		_, err := ts.products.ListProducts(as("customer-1"), &pb.ListProductsRequest{})
+		ts.Require().Nil(err)
+	}
+	var trailer metadata.MD
+	_, err := ts.products.ListProducts(as("customer-1"), &pb.ListProductsRequest{}, grpc.Trailer(&trailer))
+	ts.Assert().Equal(codes.ResourceExhausted, status.Code(err))
+	ts.Assert().Equal([]string{"30"}, trailer.Get("retry-after"))
+
+	// The streams are limited too
+	for i := 0; i < 3; i++ {
+		stream, err := ts.subs.ExportSubscriptions(as("support"), &pb.ExportSubscriptionsRequest{})
+		ts.Require().Nil(err)
+		_, err = stream.Recv()
+		if i < 2 {
+			ts.Assert().ErrorIs(err, io.EOF)
+		} else {
+			ts.Assert().Equal(codes.ResourceExhausted, status.Code(err))
+		}
+	}
+
+	// The invalid credentials are limited by IP before being verified, with the calls above
+	_, err = ts.products.ListProducts(as("invalid"), &pb.ListProductsRequest{})
+	ts.Assert().Equal(codes.Unauthenticated, status.Code(err))
+	_, err = ts.products.ListProducts(as("invalid"), &pb.ListProductsRequest{})
+	ts.Assert().Equal(codes.ResourceExhausted, status.Code(err))
+}
+
+func (ts *ServerTestSuite) TestInternalError() {
+	verifier := ports.NewMockTokenVerifier(gomock.NewController(ts.T()))
+	verifier.EXPECT().Verify("customer-1").Return(domain.Principal{}, errors.New("keys unavailable"))
+	ts.TearDownTest()
+	ts.start(ServerOptions{
+		Verifier: verifier,
+		Logger:   logger.New(logger.LevelRelease, ts.logs),
+	})
+
+	// The callers aren't told the cause, only logged
+	_, err := ts.products.ListProducts(as("customer-1"), &pb.ListProductsRequest{})
+	ts.Assert().Equal(codes.Internal, status.Code(err))
+	ts.Assert().Equal("internal error", status.Convert(err).Message())
+
+	entry := map[string]interface{}{}
+	ts.Require().Nil(json.Unmarshal(ts.logs.Bytes(), &entry))
+	ts.Assert().Equal("call failed", entry["message"])
+	ts.Assert().Equal("keys unavailable", entry["error"])
+}
+
+func (ts *ServerTestSuite) TestStatusFromError() {
+	err := statusFromError(errors.New("connection refused"))
+	ts.Assert().Equal(codes.Internal, status.Code(err))
+	ts.Assert().Equal("internal error", status.Convert(err).Message())
+	ts.Assert().Equal("connection refused", err.Error())
+
+	ts.Assert().Equal(codes.FailedPrecondition, status.Code(statusFromError(domain.ErrSubscriptionVersionRequired)))
+	ts.Assert().Equal(codes.Aborted, status.Code(statusFromError(fmt.Errorf("update: %w", domain.ErrSubscriptionVersionMismatch))))
+
+	ts.Assert().Equal(codes.ResourceExhausted, status.Code(statusFromError(domain.ErrRateLimited)))
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/constants"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/goakshit/isildur/services"
+	"github.com/google/uuid"
+)
+
+// apiKeys runs the api keys admin commands.
+func apiKeys(cfg *config.CFG, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("api-keys: missing action, create, list or revoke")
+	}
+	action, args := args[0], args[1:]
+
+	repos, _, err := setupRepositories(context.Background(), cfg, logger.Nop())
+	if err != nil {
+		return err
+	}
+	svc := services.NewAPIKeyService(repos.APIKeys)
+	ctx := context.Background()
+
+	var output string
+	switch action {
+	case "create":
+		var name, scopes, expires string
+		fs := newFlagSet("api-keys create", &output)
+		fs.StringVar(&name, "name", "", "name of the partner using the key")
+		fs.StringVar(&scopes, "scopes", string(domain.RoleCustomer), "comma separated roles granted to the key")
+		fs.StringVar(&expires, "expires", "", "expiry date of the key, dd-mm-yyyy, never by default")
+		if _, err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		var expiresAt *time.Time
+		if expires != "" {
+			date, err := time.Parse(constants.DateFormat, expires)
+			if err != nil {
+				return fmt.Errorf("%w: invalid expiry date", domain.ErrInvalidAPIKey)
+			}
+			expiresAt = &date
+		}
+		var roles []domain.Role
+		for _, scope := range strings.Split(scopes, ",") {
+			roles = append(roles, domain.Role(strings.TrimSpace(scope)))
+		}
+		created, err := svc.CreateAPIKey(ctx, name, roles, expiresAt)
+		if err != nil {
+			return err
+		}
+		if output == outputTable {
+			fmt.Printf("%s\nThe key is only shown once, store it safely.\n", created.Key)
+			return nil
+		}
+		return renderAPIKeys(output, created, created.APIKey)
+	case "list":
+		fs := newFlagSet("api-keys list", &output)
+		if _, err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		list, err := svc.ListAPIKeys(ctx)
+		if err != nil {
+			return err
+		}
+		return renderAPIKeys(output, list, list...)
+	case "revoke":
+		fs := newFlagSet("api-keys revoke", &output)
+		positional, err := parseFlags(fs, args)
+		if err != nil {
+			return err
+		}
+		if len(positional) != 1 {
+			return usageErrorf("api-keys revoke: expected a single api key id")
+		}
+		id, err := uuid.Parse(positional[0])
+		if err != nil {
+			return domain.ErrAPIKeyNotfound
+		}
+		return svc.RevokeAPIKey(ctx, id)
+	default:
+		return usageErrorf("api-keys: unknown action %q", action)
+	}
+}
+
+// renderAPIKeys writes v in the output format, using the api keys as table rows.
+func renderAPIKeys(output string, v interface{}, keys ...domain.APIKey) error {
+	header := "ID\tNAME\tPREFIX\tSCOPES\tCREATED\tEXPIRES\tLAST USED\tREVOKED"
+	return render(output, v, header, func(w io.Writer) {
+		for _, k := range keys {
+			scopes := make([]string, 0, len(k.Scopes))
+			for _, scope := range k.Scopes {
+				scopes = append(scopes, string(scope))
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", k.ID, k.Name, k.Prefix, strings.Join(scopes, ","),
+				k.CreatedAt.Format(constants.DateFormat), formatOptionalDate(k.ExpiresAt),
+				formatOptionalDate(k.LastUsedAt), formatOptionalDate(k.RevokedAt))
+		}
+	})
+}
+
+// formatOptionalDate formats a date for the tables, "-" when not set.
+func formatOptionalDate(date *time.Time) string {
+	if date == nil {
+		return "-"
+	}
+	return date.Format(constants.DateFormat)
+}
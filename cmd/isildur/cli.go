@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/goakshit/isildur/core/domain"
+)
+
+// Exit codes of the admin commands.
+const (
+	exitFailure  = 1
+	exitUsage    = 2
+	exitNotFound = 3
+	exitInvalid  = 4
+	exitConflict = 5
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// stdout is where the admin commands write their output, replaced by the tests.
+var stdout io.Writer = os.Stdout
+
+// usageError is returned when a command is invoked with invalid arguments.
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...interface{}) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// exitCodeByKind maps the kinds of the domain errors to the process exit codes.
+var exitCodeByKind = map[domain.ErrorKind]int{
+	domain.KindNotFound:           exitNotFound,
+	domain.KindInvalid:            exitInvalid,
+	domain.KindFailedPrecondition: exitInvalid,
+	domain.KindVersionRequired:    exitConflict,
+	domain.KindConflict:           exitConflict,
+}
+
+// exitCode maps an error to the process exit code.
+func exitCode(err error) int {
+	var uErr usageError
+	if errors.As(err, &uErr) {
+		return exitUsage
+	}
+	if code, ok := exitCodeByKind[domain.KindOf(err)]; ok {
+		return code
+	}
+	return exitFailure
+}
+
+// newFlagSet returns a flag set for a command with the output flag registered.
+func newFlagSet(name string, output *string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(output, "o", outputTable, "output format, table or json")
+	return fs
+}
+
+// parseFlags parses the flags of a command, allowing them before and after
+// the positional arguments, and returns the positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usageErrorf("%s: %v", fs.Name(), err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// render writes v as json, or as a table with the given header and rows.
+func render(output string, v interface{}, header string, rows func(w io.Writer)) error {
+	switch output {
+	case outputJSON:
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case outputTable:
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, header)
+		rows(w)
+		return w.Flush()
+	default:
+		return usageErrorf("unknown output format %q", output)
+	}
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	tc := []struct {
+		err  error
+		code int
+	}{
+		{usageErrorf("unknown command"), exitUsage},
+		{domain.ErrSubscriptionNotfound, exitNotFound},
+		{domain.ErrAPIKeyNotfound, exitNotFound},
+		{fmt.Errorf("%w: name is required", domain.ErrInvalidProduct), exitInvalid},
+		{domain.ErrCannotUpdateCancelledSubscription, exitInvalid},
+		{domain.ErrSubscriptionVersionMismatch, exitConflict},
+		{domain.ErrSubscriptionVersionRequired, exitConflict},
+		{domain.ErrInvalidReportPeriod, exitInvalid},
+		{errors.New("connection refused"), exitFailure},
+	}
+	for _, tt := range tc {
+		assert.Equal(t, tt.code, exitCode(tt.err), tt.err.Error())
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	var output string
+	fs := newFlagSet("subscriptions get", &output)
+	positional, err := parseFlags(fs, []string{"some-id", "-o", "json"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"some-id"}, positional)
+	assert.Equal(t, outputJSON, output)
+
+	_, err = parseFlags(fs, []string{"--unknown"})
+	assert.Equal(t, exitUsage, exitCode(err))
+}
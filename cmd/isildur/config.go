@@ -0,0 +1,20 @@
+package main
+
+import (
+	"os"
+
+	"github.com/goakshit/isildur/platform/config"
+)
+
+// showConfig runs the config commands.
+func showConfig(cfg *config.CFG, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("config: missing action, print")
+	}
+	switch args[0] {
+	case "print":
+		return cfg.Print(os.Stdout)
+	default:
+		return usageErrorf("config: unknown action %q", args[0])
+	}
+}
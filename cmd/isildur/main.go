@@ -1,27 +1,100 @@
-// Entrypoint of subscripton application. Loads the config from env,
-// initialises the gorm db client and starts serving the requests.
+// Entrypoint of subscripton application. Loads the config from the file, the env and the
+// flags preceding the command, and runs one of the commands below, serving the requests by
+// default.
+//
+//	isildur [--config file] [--db.host ...] <command>   overrides the config
+//	isildur [serve]                                     starts the http server
+//	isildur migrate [up|down [n]|status]                manages the schema migrations
+//	isildur seed                                        inserts the seed data
+//	isildur products list|create                        operates on products
+//	isildur subscriptions get|cancel|pause|refund|list  operates on subscriptions
+//	isildur subscriptions import --file                 imports subscriptions in bulk
+//	isildur api-keys create|list|revoke                 manages the partner api keys
+//	isildur config print                                shows the config, secrets redacted
+//
+// The admin commands go through the same services as the http api, so the
+// business rules apply, and exit with a code matching the domain error.
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"os"
 
-	"github.com/gin-gonic/gin"
-	"github.com/goakshit/isildur/api/handlers"
 	"github.com/goakshit/isildur/platform/config"
-	"github.com/goakshit/isildur/platform/database"
 )
 
+const usage = `usage: isildur [--config <file>] [--<setting> <value> ...] <command> [arguments]
+
+The config is loaded from the yaml file, then the env vars, then the flags, named
+after the yaml path of the settings, e.g. --db.host. See isildur config print.
+
+commands:
+  serve                                  start the http server (default)
+  migrate [up|down [n]|status]           manage the schema migrations
+  seed                                   insert the seed data
+  products list                          list the products
+  products create --name --price ...     create a product
+  subscriptions get <id>                 show a subscription
+  subscriptions list [--status ...]      list the subscriptions
+  subscriptions cancel <id>              cancel a subscription
+  subscriptions pause <id>               pause a subscription
+  subscriptions refund <id>              cancel a subscription and refund its remaining months
+  subscriptions import --file <path>     import subscriptions from a csv or jsonl file
+                 [--dry-run] [--allow-past-start] [--report <path>]
+  api-keys create --name --scopes ...    create an api key, shown once
+                 [--expires <dd-mm-yyyy>]
+  api-keys list                          list the api keys
+  api-keys revoke <id>                   revoke an api key
+  config print                           show the effective config, secrets redacted
+
+admin commands accept -o table|json to select the output format.
+`
+
 func main() {
 
-	cfg := config.LoadFromEnv()
-	db := database.GetGormClient(cfg)
+	cfg, args, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Print(usage)
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(exitUsage)
+	}
+
+	cmd := "serve"
+	if len(args) > 0 {
+		cmd, args = args[0], args[1:]
+	}
 
-	// Set gin mode in different environment
-	gin.SetMode(cfg.ServiceLevel)
-	r := gin.Default()
-	handlers.SetupRouter(r, cfg, db)
-	if err := r.Run(fmt.Sprintf(":%s", cfg.ServicePort)); err != nil {
-		log.Fatalln("failed to setup router")
+	switch cmd {
+	case "serve":
+		err = serve(cfg)
+	case "migrate":
+		err = migrate(cfg, args)
+	case "seed":
+		err = seed(cfg)
+	case "products":
+		err = products(cfg, args)
+	case "subscriptions":
+		err = subscriptions(cfg, args)
+	case "api-keys":
+		err = apiKeys(cfg, args)
+	case "config":
+		err = showConfig(cfg, args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		err = usageErrorf("unknown command %q", cmd)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		var uErr usageError
+		if errors.As(err, &uErr) {
+			fmt.Fprint(os.Stderr, usage)
+		}
+		os.Exit(exitCode(err))
 	}
 }
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/database"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/goakshit/isildur/platform/migrations"
+)
+
+// migrate applies, reverts or reports the schema migrations.
+func migrate(cfg *config.CFG, args []string) error {
+	action := "up"
+	if len(args) > 0 {
+		action, args = args[0], args[1:]
+	}
+
+	ctx := context.Background()
+	db, err := database.Connect(ctx, cfg, logger.Nop())
+	if err != nil {
+		return err
+	}
+	m, err := migrations.New(db, logger.New(cfg.ServiceLevel, os.Stderr))
+	if err != nil {
+		return err
+	}
+
+	switch action {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, migration := range applied {
+			fmt.Printf("applied %04d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 0 {
+			if steps, err = strconv.Atoi(args[0]); err != nil || steps <= 0 {
+				return fmt.Errorf("invalid number of migrations to revert %q", args[0])
+			}
+		}
+		reverted, err := m.Down(ctx, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		return err
+	case "status":
+		statuses, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+		for _, s := range statuses {
+			status, appliedAt := "pending", ""
+			if s.Applied {
+				status, appliedAt = "applied", s.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, status, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return fmt.Errorf("unknown migrate action %q", action)
+	}
+}
+
+// seed inserts the seed data into the migrated database.
+func seed(cfg *config.CFG) error {
+	ctx := context.Background()
+	db, err := database.Connect(ctx, cfg, logger.Nop())
+	if err != nil {
+		return err
+	}
+	return database.Seed(ctx, db)
+}
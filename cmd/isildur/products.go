@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/goakshit/isildur/services"
+)
+
+// products runs the products admin commands.
+func products(cfg *config.CFG, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("products: missing action, list or create")
+	}
+	action, args := args[0], args[1:]
+
+	repos, db, err := setupRepositories(context.Background(), cfg, logger.Nop())
+	if err != nil {
+		return err
+	}
+	// The products created invalidate the cache shared with the servers, if any
+	if cfg.Cache.Enabled {
+		repos.Products = cacheProducts(cfg, repos.Products, db, nil, logger.Nop())
+	}
+	svc := services.NewProductsService(repos.Products)
+	ctx := context.Background()
+
+	var output string
+	switch action {
+	case "list":
+		fs := newFlagSet("products list", &output)
+		if _, err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		list, err := svc.FetchAllProducts(ctx)
+		if err != nil {
+			return err
+		}
+		return renderProducts(output, list, list...)
+	case "create":
+		var product domain.Product
+		fs := newFlagSet("products create", &output)
+		fs.StringVar(&product.Name, "name", "", "name of the product")
+		fs.StringVar(&product.Description, "description", "", "description of the product")
+		fs.Float64Var(&product.MonthlyPrice, "price", 0, "monthly price of the product")
+		fs.StringVar(&product.InstructorName, "instructor", "", "name of the instructor")
+		if _, err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		created, err := svc.CreateProduct(ctx, product)
+		if err != nil {
+			return err
+		}
+		return renderProducts(output, created, created)
+	default:
+		return usageErrorf("products: unknown action %q", action)
+	}
+}
+
+// renderProducts writes v in the output format, using the products as table rows.
+func renderProducts(output string, v interface{}, products ...domain.Product) error {
+	return render(output, v, "ID\tNAME\tMONTHLY PRICE\tINSTRUCTOR\tDESCRIPTION", func(w io.Writer) {
+		for _, p := range products {
+			fmt.Fprintf(w, "%s\t%s\t%.2f\t%s\t%s\n", p.ID, p.Name, p.MonthlyPrice, p.InstructorName, p.Description)
+		}
+	})
+}
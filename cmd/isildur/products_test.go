@@ -0,0 +1,67 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryConfig returns the config of the commands run against the in-memory storage seeded from
+// the fixtures, writing their output to the returned buffer.
+func memoryConfig(t *testing.T) (*config.CFG, *bytes.Buffer) {
+	cfg := config.Default()
+	cfg.Storage = config.StorageMemory
+	cfg.FixturesFile = "../../build/fixtures/fixtures.json"
+	cfg.MetricsEnabled = false
+
+	out := &bytes.Buffer{}
+	stdout = out
+	t.Cleanup(func() { stdout = os.Stdout })
+	return cfg, out
+}
+
+func TestProducts_List(t *testing.T) {
+	cfg, out := memoryConfig(t)
+
+	require.Nil(t, products(cfg, []string{"list", "-o", "json"}))
+	var list []domain.Product
+	require.Nil(t, json.Unmarshal(out.Bytes(), &list))
+	require.Len(t, list, 2)
+	assert.Equal(t, "YOGA L1", list[0].Name)
+	assert.Equal(t, "YOGA L2", list[1].Name)
+
+	out.Reset()
+	require.Nil(t, products(cfg, []string{"list"}))
+	assert.Contains(t, out.String(), "ID")
+	assert.Contains(t, out.String(), "YOGA L2")
+}
+
+func TestProducts_Create(t *testing.T) {
+	cfg, out := memoryConfig(t)
+
+	args := []string{"create", "--name", "PILATES", "--price", "9.5", "--instructor", "B. Kaur", "-o", "json"}
+	require.Nil(t, products(cfg, args))
+	var created domain.Product
+	require.Nil(t, json.Unmarshal(out.Bytes(), &created))
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "PILATES", created.Name)
+	assert.Equal(t, 9.5, created.MonthlyPrice)
+
+	err := products(cfg, []string{"create", "--price", "9.5"})
+	assert.ErrorIs(t, err, domain.ErrInvalidProduct)
+	assert.Equal(t, exitInvalid, exitCode(err))
+}
+
+func TestProducts_Usage(t *testing.T) {
+	cfg, _ := memoryConfig(t)
+
+	for _, args := range [][]string{nil, {"delete"}, {"list", "--unknown"}, {"list", "-o", "yaml"}} {
+		assert.Equal(t, exitUsage, exitCode(products(cfg, args)), args)
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/api/handlers"
+	"github.com/goakshit/isildur/api/rpc"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/auth"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/database"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/goakshit/isildur/platform/metrics"
+	"github.com/goakshit/isildur/platform/migrations"
+	"github.com/goakshit/isildur/platform/tracing"
+	"github.com/goakshit/isildur/repositories"
+	"github.com/goakshit/isildur/repositories/cached"
+	"github.com/goakshit/isildur/repositories/memory"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"gorm.io/gorm"
+)
+
+// serve initialises the storage and serves the requests until SIGINT or SIGTERM, then drains
+// the requests in flight before closing the database.
+func serve(cfg *config.CFG) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	log := logger.New(cfg.ServiceLevel, os.Stdout).With("service", cfg.ServiceName)
+	shutdownTracing, err := tracing.Setup(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("failed to setup tracing: %w", err)
+	}
+	// Flushes the spans still pending once the servers are stopped
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error(context.Background(), "failed to flush spans", "error", err)
+		}
+	}()
+
+	repos, db, err := setupRepositories(ctx, cfg, log)
+	if err != nil {
+		return fmt.Errorf("failed to setup storage: %w", err)
+	}
+	health := handlers.NewHealth()
+	if db != nil {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return fmt.Errorf("failed to setup storage: %w", err)
+		}
+		// Closed last, once the requests in flight are drained
+		defer func() {
+			if err := sqlDB.Close(); err != nil {
+				log.Error(context.Background(), "failed to close database", "error", err)
+			}
+		}()
+		health.AddCheck("database", sqlDB.PingContext)
+
+		if cfg.Tracing.Exporter != config.TracingNone {
+			if err := db.Use(tracing.GormPlugin()); err != nil {
+				return fmt.Errorf("failed to setup tracing: %w", err)
+			}
+		}
+		if err := prepareDatabase(ctx, cfg, db, log); err != nil {
+			return fmt.Errorf("failed to prepare database: %w", err)
+		}
+		// Registered once migrated, the migrations reading the schema of the primary
+		if cfg.DB.ReplicaDSN != "" {
+			if err := database.UseReplica(db, cfg); err != nil {
+				return fmt.Errorf("failed to setup storage: %w", err)
+			}
+		}
+	}
+
+	opts, err := routerOptions(cfg, db, log)
+	if err != nil {
+		return err
+	}
+	opts.Health = health
+	if cfg.MetricsEnabled {
+		if opts.Metrics, err = setupMetrics(cfg, db); err != nil {
+			return fmt.Errorf("failed to setup metrics: %w", err)
+		}
+	}
+	if cfg.Cache.Enabled {
+		repos.Products = cacheProducts(cfg, repos.Products, db, opts.Metrics, log)
+	}
+
+	// The gRPC api is served on its own port, next to the http apis
+	errs := make(chan error, 2)
+	var grpcServer *grpc.Server
+	if cfg.GRPCPort != 0 {
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPCPort))
+		if err != nil {
+			return fmt.Errorf("failed to listen for grpc: %w", err)
+		}
+		grpcOpts := rpc.ServerOptions{
+			Verifier:    opts.Verifier,
+			RateLimiter: opts.RateLimiter,
+			RateLimits:  opts.RateLimits,
+			Logger:      log,
+			Metrics:     opts.Metrics,
+		}
+		if cfg.TLS.CertFile != "" {
+			if grpcOpts.Credentials, err = credentials.NewServerTLSFromFile(cfg.TLS.CertFile, cfg.TLS.KeyFile); err != nil {
+				return fmt.Errorf("failed to load tls certificate: %w", err)
+			}
+		}
+		grpcServer = rpc.NewServer(repos, grpcOpts)
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				errs <- fmt.Errorf("failed to serve grpc: %w", err)
+			}
+		}()
+	}
+
+	// Set gin mode in different environment
+	gin.SetMode(cfg.ServiceLevel)
+	r := gin.New()
+	// The client IP, e.g. rate limiting the clients, is only read from the headers of the
+	// trusted proxies, not to be forged by the clients
+	if err := r.SetTrustedProxies(cfg.TrustedProxyList()); err != nil {
+		return fmt.Errorf("failed to setup router: %w", err)
+	}
+	handlers.SetupRouter(r, cfg, repos, opts)
+	httpServer := &http.Server{
+		Addr:    fmt.Sprintf(":%d", cfg.ServicePort),
+		Handler: r,
+	}
+	go func() {
+		var err error
+		if cfg.TLS.CertFile != "" {
+			err = httpServer.ListenAndServeTLS(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("failed to setup router: %w", err)
+		}
+	}()
+
+	var serveErr error
+	select {
+	case serveErr = <-errs:
+	case <-ctx.Done():
+		log.Info(context.Background(), "shutting down, draining the requests in flight")
+	}
+	// A second signal kills the service right away
+	stop()
+	shutdown(cfg, health, httpServer, grpcServer, log)
+	return serveErr
+}
+
+// shutdown stops the servers: the readiness probe fails first, the servers keep serving the
+// requests still routed to them for the drain delay, then the requests in flight are drained.
+func shutdown(cfg *config.CFG, health *handlers.Health, httpServer *http.Server, grpcServer *grpc.Server, log ports.Logger) {
+	health.Drain()
+	if cfg.ShutdownDrainDelay > 0 {
+		log.Info(context.Background(), "waiting for the load balancers to stop routing requests",
+			"delay_ms", cfg.ShutdownDrainDelay)
+		time.Sleep(cfg.ShutdownDrainDelay)
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Error(shutdownCtx, "failed to drain the http requests", "error", err)
+	}
+	if grpcServer != nil {
+		stopGRPC(shutdownCtx, grpcServer)
+	}
+}
+
+// stopGRPC waits for the gRPC calls in flight until ctx is done, then cancels them.
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		server.Stop()
+	}
+}
+
+// setupMetrics initialises the metrics, timing the queries and exposing the connection pool
+// statistics for the database storage.
+func setupMetrics(cfg *config.CFG, db *gorm.DB) (*metrics.Metrics, error) {
+	m := metrics.New()
+	if db == nil {
+		return m, nil
+	}
+	if err := db.Use(m.GormPlugin()); err != nil {
+		return nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	return m, m.RegisterDB(cfg.DB.Name, sqlDB)
+}
+
+// cacheProducts caches the products read from repo in memory, and in the database shared by the
+// replicas if configured. The lookups are only counted in m if set.
+func cacheProducts(
+	cfg *config.CFG,
+	repo ports.ProductsRepository,
+	db *gorm.DB,
+	m *metrics.Metrics,
+	log ports.Logger,
+) ports.ProductsRepository {
+	opts := cached.Options{
+		Local:    memory.NewCache(cfg.Cache.Size),
+		LocalTTL: cfg.Cache.TTL,
+		Metrics:  metrics.Nop(),
+		Logger:   log,
+	}
+	if m != nil {
+		opts.Metrics = m
+	}
+	if cfg.Cache.Shared == config.CacheSharedDatabase && db != nil {
+		opts.Shared, opts.SharedTTL = repositories.NewCache(db), cfg.Cache.SharedTTL
+	}
+	return cached.NewProductsRepository(repo, opts)
+}
+
+// routerOptions initialises the authentication and the rate limiting of the apis.
+// The rate limit buckets can only be kept in the database for the database storage.
+func routerOptions(cfg *config.CFG, db *gorm.DB, log ports.Logger) (handlers.RouterOptions, error) {
+	opts := handlers.RouterOptions{Logger: log}
+	if cfg.Auth.Disabled {
+		log.Warn(context.Background(), "authentication is disabled, every api is public")
+	} else {
+		verifier, err := auth.NewVerifier(cfg.Auth)
+		if err != nil {
+			return opts, fmt.Errorf("failed to setup authentication: %w", err)
+		}
+		opts.Verifier = verifier
+	}
+
+	if !cfg.RateLimit.Enabled {
+		return opts, nil
+	}
+	limits, err := handlers.NewRateLimits(cfg.RateLimit)
+	if err != nil {
+		return opts, fmt.Errorf("failed to setup rate limiting: %w", err)
+	}
+	opts.RateLimits = limits
+	switch cfg.RateLimit.Backend {
+	case config.RateLimitMemory:
+		opts.RateLimiter = memory.NewRateLimiter()
+	case config.RateLimitDatabase:
+		if db == nil {
+			return opts, fmt.Errorf("rate limit backend %q requires the database storage", cfg.RateLimit.Backend)
+		}
+		opts.RateLimiter = repositories.NewRateLimiter(db)
+	default:
+		return opts, fmt.Errorf("unknown rate limit backend %q", cfg.RateLimit.Backend)
+	}
+	return opts, nil
+}
+
+// setupRepositories initialises the repositories for the configured storage.
+// The db client is only returned for the database storage, logging the queries to log, once
+// the database accepts connections.
+func setupRepositories(
+	ctx context.Context,
+	cfg *config.CFG,
+	log ports.Logger,
+) (repositories.Repositories, *gorm.DB, error) {
+	switch cfg.Storage {
+	case config.StorageMemory:
+		store := memory.NewStore()
+		if cfg.FixturesFile != "" {
+			if err := store.LoadFixtures(cfg.FixturesFile); err != nil {
+				return repositories.Repositories{}, nil, err
+			}
+		}
+		return memory.NewRepositories(store), nil, nil
+	case config.StorageDatabase:
+		db, err := database.Connect(ctx, cfg, log)
+		if err != nil {
+			return repositories.Repositories{}, nil, err
+		}
+		return repositories.NewGormRepositories(db), db, nil
+	default:
+		return repositories.Repositories{}, nil, fmt.Errorf("unknown storage %q", cfg.Storage)
+	}
+}
+
+// prepareDatabase applies the migrations and inserts the seed data, if enabled.
+func prepareDatabase(ctx context.Context, cfg *config.CFG, db *gorm.DB, log ports.Logger) error {
+	if cfg.DB.MigrateOnStart {
+		m, err := migrations.New(db, log)
+		if err != nil {
+			return err
+		}
+		applied, err := m.Up(ctx)
+		if err != nil {
+			return err
+		}
+		for _, migration := range applied {
+			log.Info(ctx, "applied migration", "version", migration.Version, "name", migration.Name)
+		}
+	}
+	if cfg.DB.SeedOnStart {
+		return database.Seed(ctx, db)
+	}
+	return nil
+}
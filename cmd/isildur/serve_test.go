@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goakshit/isildur/api/handlers"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown_DrainDelay(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	health := handlers.NewHealth()
+	r := gin.New()
+	r.GET("/readyz", health.Ready)
+	r.GET("/ping", func(ctx *gin.Context) { ctx.Status(http.StatusOK) })
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	server := &http.Server{Handler: r}
+	go func() { _ = server.Serve(lis) }()
+	url := "http://" + lis.Addr().String()
+
+	cfg := config.Default()
+	cfg.ShutdownDrainDelay = 500 * time.Millisecond
+	done := make(chan struct{})
+	go func() {
+		shutdown(cfg, health, server, nil, logger.Nop())
+		close(done)
+	}()
+
+	// Not ready first, still serving the requests routed meanwhile
+	assert.Eventually(t, func() bool {
+		resp, err := http.Get(url + "/readyz")
+		if err != nil {
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusServiceUnavailable
+	}, time.Second, 10*time.Millisecond)
+	resp, err := http.Get(url + "/ping")
+	require.Nil(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Then shut down once the delay is over
+	select {
+	case <-done:
+		t.Fatal("shut down before the drain delay")
+	default:
+	}
+	<-done
+	_, err = http.Get(url + "/ping")
+	assert.NotNil(t, err)
+}
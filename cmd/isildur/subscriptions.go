@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/constants"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/goakshit/isildur/platform/metrics"
+	"github.com/goakshit/isildur/services"
+	"github.com/google/uuid"
+)
+
+// subscriptions runs the subscriptions admin commands.
+func subscriptions(cfg *config.CFG, args []string) error {
+	if len(args) == 0 {
+		return usageErrorf("subscriptions: missing action, get, list, cancel, pause, refund or import")
+	}
+	action, args := args[0], args[1:]
+
+	repos, _, err := setupRepositories(context.Background(), cfg, logger.Nop())
+	if err != nil {
+		return err
+	}
+	// The commands aren't scraped, their metrics are discarded
+	m := metrics.Nop()
+	recognition := services.NewRecognitionService(repos.RevenueEntries, repos.Subscriptions, repos.Tx, m)
+	svc := services.NewSubscriptionService(repos.Subscriptions, repos.Products, repos.Tx, recognition, m)
+	ctx := context.Background()
+
+	var output string
+	switch action {
+	case "get":
+		fs := newFlagSet("subscriptions get", &output)
+		id, err := parseSubscriptionID(fs, args)
+		if err != nil {
+			return err
+		}
+		sub, err := svc.FetchSubscription(ctx, id)
+		if err != nil {
+			return err
+		}
+		return renderSubscriptions(output, sub, sub)
+	case "list":
+		var filter domain.SubscriptionFilter
+		var status, productID string
+		fs := newFlagSet("subscriptions list", &output)
+		fs.StringVar(&status, "status", "", "only list subscriptions with this status")
+		fs.StringVar(&productID, "product-id", "", "only list subscriptions of this product")
+		fs.IntVar(&filter.Limit, "limit", constants.DefaultPageSize, "maximum number of subscriptions")
+		fs.IntVar(&filter.Offset, "offset", 0, "number of subscriptions to skip")
+		if _, err := parseFlags(fs, args); err != nil {
+			return err
+		}
+		filter.Status = domain.SubscriptionStatus(status)
+		if productID != "" {
+			if filter.ProductID, err = uuid.Parse(productID); err != nil {
+				return domain.ErrProductIDIsInvalid
+			}
+		}
+		list, err := svc.ListSubscriptions(ctx, filter)
+		if err != nil {
+			return err
+		}
+		return renderSubscriptions(output, list, list...)
+	case "cancel", "pause":
+		status := domain.SubscriptionStatusCancel
+		if action == "pause" {
+			status = domain.SubscriptionStatusPaused
+		}
+		fs := newFlagSet("subscriptions "+action, &output)
+		id, err := parseSubscriptionID(fs, args)
+		if err != nil {
+			return err
+		}
+		sub, err := svc.FetchSubscription(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := svc.UpdateSubscriptionStatus(ctx, id, sub.Version, status); err != nil {
+			return err
+		}
+		if sub, err = svc.FetchSubscription(ctx, id); err != nil {
+			return err
+		}
+		return renderSubscriptions(output, sub, sub)
+	case "refund":
+		fs := newFlagSet("subscriptions refund", &output)
+		id, err := parseSubscriptionID(fs, args)
+		if err != nil {
+			return err
+		}
+		refund, err := recognition.RefundSubscription(ctx, id)
+		if err != nil {
+			return err
+		}
+		return render(output, refund, "SUBSCRIPTION ID\tMONTHS\tAMOUNT\tREFUNDED ON", func(w io.Writer) {
+			fmt.Fprintf(w, "%s\t%d\t%.2f\t%s\n", refund.SubscriptionID, refund.Months, refund.Amount,
+				refund.RefundedOn.Format(constants.DateFormat))
+		})
+	case "import":
+		return importSubscriptions(ctx, services.NewImportService(repos.Subscriptions, repos.Products, repos.Tx, recognition, m), args)
+	default:
+		return usageErrorf("subscriptions: unknown action %q", action)
+	}
+}
+
+// importSubscriptions imports the subscriptions from a csv or jsonl file and prints a summary.
+// The failed rows are written to the report file, if any.
+func importSubscriptions(ctx context.Context, importer ports.SubscriptionImporter, args []string) error {
+	var output, file, reportFile string
+	var opts domain.ImportOptions
+	fs := newFlagSet("subscriptions import", &output)
+	fs.StringVar(&file, "file", "", "csv or jsonl file to import")
+	fs.StringVar(&opts.Format, "format", "", "format of the file, csv or jsonl, guessed from the extension by default")
+	fs.BoolVar(&opts.DryRun, "dry-run", false, "only validate the rows")
+	fs.BoolVar(&opts.AllowPastStartDate, "allow-past-start", false, "skip the past start date check")
+	fs.IntVar(&opts.BatchSize, "batch-size", 500, "number of subscriptions inserted per transaction")
+	fs.StringVar(&reportFile, "report", "", "csv file the failed rows are written to")
+	if _, err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	if file == "" {
+		return usageErrorf("subscriptions import: --file is required")
+	}
+	if opts.Format == "" {
+		opts.Format = domain.ImportFormatCSV
+		if strings.HasSuffix(file, ".jsonl") || strings.HasSuffix(file, ".ndjson") {
+			opts.Format = domain.ImportFormatJSONL
+		}
+	}
+
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	report, err := importer.ImportSubscriptions(ctx, f, opts)
+	if err != nil {
+		return err
+	}
+	if reportFile != "" {
+		rf, err := os.Create(reportFile)
+		if err != nil {
+			return err
+		}
+		defer rf.Close()
+		if err := services.WriteImportErrors(rf, report); err != nil {
+			return err
+		}
+	}
+	return render(output, report, "DRY RUN\tTOTAL\tIMPORTED\tFAILED", func(w io.Writer) {
+		fmt.Fprintf(w, "%t\t%d\t%d\t%d\n", report.DryRun, report.Total, report.Imported, report.Failed)
+	})
+}
+
+// parseSubscriptionID parses the flags and the single subscription id argument.
+func parseSubscriptionID(fs *flag.FlagSet, args []string) (uuid.UUID, error) {
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if len(positional) != 1 {
+		return uuid.Nil, usageErrorf("%s: expected a single subscription id", fs.Name())
+	}
+	id, err := uuid.Parse(positional[0])
+	if err != nil {
+		return uuid.Nil, domain.ErrSubscriptionIDIsInvalid
+	}
+	return id, nil
+}
+
+// renderSubscriptions writes v in the output format, using the subscriptions as table rows.
+func renderSubscriptions(output string, v interface{}, subscriptions ...domain.Subscription) error {
+	header := "ID\tPRODUCT ID\tSTATUS\tSTART DATE\tEND DATE\tMONTHS\tTOTAL COST\tVERSION"
+	return render(output, v, header, func(w io.Writer) {
+		for _, s := range subscriptions {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%.2f\t%d\n",
+				s.ID, s.ProductID, s.Status,
+				s.StartDate.Format(constants.DateFormat), s.EndDate.Format(constants.DateFormat),
+				s.DurationInMonths, s.TotalCost, s.Version)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixtureSubscriptionID is the active subscription of the fixtures.
+const fixtureSubscriptionID = "0b7c2a8e-5f0e-4a8c-9d0f-3a1b2c3d4e5f"
+
+func TestSubscriptions_Get(t *testing.T) {
+	cfg, out := memoryConfig(t)
+
+	require.Nil(t, subscriptions(cfg, []string{"get", fixtureSubscriptionID, "-o", "json"}))
+	var sub domain.Subscription
+	require.Nil(t, json.Unmarshal(out.Bytes(), &sub))
+	assert.Equal(t, fixtureSubscriptionID, sub.ID.String())
+	assert.Equal(t, domain.SubscriptionStatusActive, sub.Status)
+
+	err := subscriptions(cfg, []string{"get", "0b7c2a8e-5f0e-4a8c-9d0f-000000000000"})
+	assert.Equal(t, exitNotFound, exitCode(err))
+	err = subscriptions(cfg, []string{"get", "1234"})
+	assert.Equal(t, exitInvalid, exitCode(err))
+	err = subscriptions(cfg, []string{"get"})
+	assert.Equal(t, exitUsage, exitCode(err))
+}
+
+func TestSubscriptions_List(t *testing.T) {
+	cfg, out := memoryConfig(t)
+
+	require.Nil(t, subscriptions(cfg, []string{"list", "--status", "active", "-o", "json"}))
+	var list []domain.Subscription
+	require.Nil(t, json.Unmarshal(out.Bytes(), &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, fixtureSubscriptionID, list[0].ID.String())
+
+	out.Reset()
+	require.Nil(t, subscriptions(cfg, []string{"list", "--status", "paused", "-o", "json"}))
+	require.Nil(t, json.Unmarshal(out.Bytes(), &list))
+	assert.Empty(t, list)
+
+	err := subscriptions(cfg, []string{"list", "--product-id", "1234"})
+	assert.Equal(t, exitInvalid, exitCode(err))
+}
+
+func TestSubscriptions_UpdateStatus(t *testing.T) {
+	tc := []struct {
+		action string
+		status domain.SubscriptionStatus
+	}{
+		{"pause", domain.SubscriptionStatusPaused},
+		{"cancel", domain.SubscriptionStatusCancel},
+	}
+	for _, tt := range tc {
+		t.Run(tt.action, func(t *testing.T) {
+			cfg, out := memoryConfig(t)
+
+			require.Nil(t, subscriptions(cfg, []string{tt.action, fixtureSubscriptionID, "-o", "json"}))
+			var sub domain.Subscription
+			require.Nil(t, json.Unmarshal(out.Bytes(), &sub))
+			assert.Equal(t, tt.status, sub.Status)
+			assert.Equal(t, 2, sub.Version)
+		})
+	}
+}
+
+func TestSubscriptions_Usage(t *testing.T) {
+	cfg, _ := memoryConfig(t)
+
+	for _, args := range [][]string{nil, {"renew"}, {"import"}} {
+		assert.Equal(t, exitUsage, exitCode(subscriptions(cfg, args)), args)
+	}
+}
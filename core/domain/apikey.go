@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix starts every API key, telling them apart from JWTs in bearer tokens.
+const APIKeyPrefix = "isk_"
+
+// APIKey represents a key authenticating a partner server. Only the hash of the key is stored,
+// the key itself is returned once when it is created.
+type APIKey struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	// Prefix is the public part of the key, used to look it up.
+	Prefix string `json:"prefix"`
+	Hash   string `json:"-"`
+	// Scopes are the roles granted to the callers using the key.
+	Scopes     []Role     `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the key can still authenticate requests at a given time.
+func (k APIKey) Active(at time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || k.ExpiresAt.After(at))
+}
+
+// NewAPIKey represents a key just created, along with the key itself.
+type NewAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
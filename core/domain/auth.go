@@ -0,0 +1,57 @@
+package domain
+
+import "context"
+
+// Role grants a principal access to some operations.
+type Role string
+
+const (
+	// RoleCustomer is the role of the customers, only accessing their own subscriptions.
+	RoleCustomer Role = "customer"
+	// RoleSupport is the role of the support staff, managing the subscriptions of any customer.
+	RoleSupport Role = "support"
+	// RoleAdmin is the role of the administrators, also managing the products and the finances.
+	RoleAdmin Role = "admin"
+)
+
+// MapStringToRole maps string literal to Role type, unknown roles being mapped to "".
+func MapStringToRole(role string) Role {
+	switch Role(role) {
+	case RoleCustomer, RoleSupport, RoleAdmin:
+		return Role(role)
+	}
+	return ""
+}
+
+// Principal represents the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller, the customer id for customers.
+	Subject string
+	Roles   []Role
+}
+
+// HasRole reports whether the principal has any of the roles.
+func (p Principal) HasRole(roles ...Role) bool {
+	for _, have := range p.Roles {
+		for _, want := range roles {
+			if have == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// principalKey is the context key of the principal.
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying the principal.
+func ContextWithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal carried by ctx, if any.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
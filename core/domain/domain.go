@@ -55,14 +55,70 @@ type Product struct {
 	InstructorName string    `json:"instructor_name"` // This should ideally be fk to instructor or users table
 }
 
+// ProductSort is a field the products can be sorted by.
+type ProductSort string
+
+const (
+	ProductSortName  ProductSort = "name"
+	ProductSortPrice ProductSort = "price"
+)
+
+// ProductQuery represents the criteria used to search and list products.
+// Zero values are ignored. Without Sort, the products matching Search are ordered by relevance
+// where the storage supports it, then by name.
+type ProductQuery struct {
+	// Search matches the products having every term in their name or description.
+	Search string
+	// Instructor matches the instructor name, ignoring the case.
+	Instructor string
+	// MinPrice and MaxPrice bound the monthly price, inclusive.
+	MinPrice   float64
+	MaxPrice   float64
+	Sort       ProductSort
+	Descending bool
+	Limit      int
+	Offset     int
+}
+
 // Subscription represents structure for subscription entity in db.
 type Subscription struct {
 	ID               uuid.UUID          `json:"id" gorm:"type:uuid;primary_key;"`
 	ProductID        uuid.UUID          `json:"-"`
+	CustomerID       string             `json:"customer_id"`
 	DurationInMonths int8               `json:"duration_in_months"`
 	Tax              float64            `json:"tax"`
 	TotalCost        float64            `json:"total_cost"`
 	Status           SubscriptionStatus `json:"status"`
 	StartDate        time.Time          `json:"start_date"`
 	EndDate          time.Time          `json:"end_date"`
+	Version          int                `json:"version"` // Incremented on every update, used for optimistic locking
+	CancelledAt      *time.Time         `json:"cancelled_at,omitempty"`
+	PausedAt         *time.Time         `json:"paused_at,omitempty"`  // Start of the latest pause
+	ResumedAt        *time.Time         `json:"resumed_at,omitempty"` // End of the latest pause, if before PausedAt the subscription is still paused
+}
+
+// SubscriptionFilter represents the criteria used to list subscriptions.
+// Zero values are ignored.
+type SubscriptionFilter struct {
+	ProductID  uuid.UUID
+	CustomerID string
+	Status     SubscriptionStatus
+	Limit      int
+	Offset     int
+}
+
+// SubscriptionExportRow represents a subscription joined with its product, as exported.
+type SubscriptionExportRow struct {
+	ID                  uuid.UUID          `json:"id"`
+	ProductID           uuid.UUID          `json:"product_id"`
+	ProductName         string             `json:"product_name"`
+	ProductMonthlyPrice float64            `json:"product_monthly_price"`
+	CustomerID          string             `json:"customer_id"`
+	DurationInMonths    int8               `json:"duration_in_months"`
+	Tax                 float64            `json:"tax"`
+	TotalCost           float64            `json:"total_cost"`
+	Status              SubscriptionStatus `json:"status"`
+	StartDate           time.Time          `json:"start_date"`
+	EndDate             time.Time          `json:"end_date"`
+	Version             int                `json:"version"`
 }
@@ -2,26 +2,129 @@ package domain
 
 import "errors"
 
+// ErrorKind classifies the domain errors, for the apis and the commands to map them to their
+// status codes in one place.
+type ErrorKind int
+
+const (
+	// KindInternal is the kind of the errors which aren't domain errors.
+	KindInternal ErrorKind = iota
+	// KindNotFound is the kind of the errors telling a resource doesn't exist.
+	KindNotFound
+	// KindInvalid is the kind of the errors telling the input is invalid.
+	KindInvalid
+	// KindFailedPrecondition is the kind of the errors telling the resource isn't in a state
+	// allowing the operation, whatever the input.
+	KindFailedPrecondition
+	// KindVersionRequired is the kind of the errors telling an update doesn't carry the version
+	// of the resource it's based on.
+	KindVersionRequired
+	// KindConflict is the kind of the errors telling the resource has been modified since the
+	// version an update is based on.
+	KindConflict
+	// KindUnauthenticated is the kind of the errors telling the credentials are missing or invalid.
+	KindUnauthenticated
+	// KindForbidden is the kind of the errors telling the caller isn't allowed the operation.
+	KindForbidden
+	// KindRateLimited is the kind of the errors telling the caller sent too many requests.
+	KindRateLimited
+)
+
+// Error is a domain error, with a stable machine code for the clients not to parse its message.
+// The errors are compared with errors.Is, the code of a wrapped error read with errors.As.
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func newError(kind ErrorKind, code, message string) error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// KindOf returns the kind of the domain error an error wraps, KindInternal if it doesn't wrap any.
+func KindOf(err error) ErrorKind {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+	return KindInternal
+}
+
 var (
+	// ErrInvalidRequest is the error used when a request can't be decoded or its fields are invalid.
+	ErrInvalidRequest = newError(KindInvalid, "invalid_request", "invalid request")
+
 	// ErrProductNotfound is the error used when a product doesn't exist for a given id.
-	ErrProductNotfound = errors.New("product not found")
+	ErrProductNotfound = newError(KindNotFound, "product_not_found", "product not found")
 
 	// ErrSubscriptionNotfound is the error used when a subscriptuon doesn't exist for a given id.
-	ErrSubscriptionNotfound = errors.New("subscription not found")
+	ErrSubscriptionNotfound = newError(KindNotFound, "subscription_not_found", "subscription not found")
 
 	// ErrProductIDIsInvalid is the error used when a given product id is an invalid uuid.
-	ErrProductIDIsInvalid = errors.New("invalid product id")
+	ErrProductIDIsInvalid = newError(KindInvalid, "invalid_product_id", "invalid product id")
 
 	// ErrSubscriptionIDIsInvalid is the error used when a given subscription id is an invalid uuid.
-	ErrSubscriptionIDIsInvalid = errors.New("invalid subscription id")
+	ErrSubscriptionIDIsInvalid = newError(KindInvalid, "invalid_subscription_id", "invalid subscription id")
 
 	// ErrInvalidStartDate is the error used when a given start date passed is invalid.
-	ErrInvalidStartDate = errors.New("invalid start date")
+	ErrInvalidStartDate = newError(KindInvalid, "invalid_start_date", "invalid start date")
 
 	// ErrCannotUpdateCancelledSubscription is the error used when a given subscription is cancelled and
 	// we are trying to change its status.
-	ErrCannotUpdateCancelledSubscription = errors.New("cannot update cancelled subsciption")
+	ErrCannotUpdateCancelledSubscription = newError(KindFailedPrecondition, "subscription_cancelled", "cannot update cancelled subsciption")
 
 	// ErrInvalidSubscriptionStatusPassed is the error used when an invalid subscription status is passed.
-	ErrInvalidSubscriptionStatusPassed = errors.New("invalid subscription status passed")
+	ErrInvalidSubscriptionStatusPassed = newError(KindInvalid, "invalid_subscription_status", "invalid subscription status passed")
+
+	// ErrSubscriptionVersionMismatch is the error used when a subscription has been modified
+	// since the version the caller based its update on.
+	ErrSubscriptionVersionMismatch = newError(KindConflict, "subscription_version_mismatch", "subscription has been modified")
+
+	// ErrSubscriptionVersionRequired is the error used when an update doesn't carry the
+	// expected subscription version.
+	ErrSubscriptionVersionRequired = newError(KindVersionRequired, "subscription_version_required", "subscription version is required")
+
+	// ErrInvalidProduct is the error used when the product data passed is invalid.
+	ErrInvalidProduct = newError(KindInvalid, "invalid_product", "invalid product")
+
+	// ErrInvalidPagination is the error used when the limit or offset passed is invalid.
+	ErrInvalidPagination = newError(KindInvalid, "invalid_pagination", "invalid pagination")
+
+	// ErrInvalidQuery is the error used when a search, filter or sort parameter is invalid.
+	ErrInvalidQuery = newError(KindInvalid, "invalid_query", "invalid query")
+
+	// ErrInvalidSubscriptionDuration is the error used when a given subscription duration is invalid.
+	ErrInvalidSubscriptionDuration = newError(KindInvalid, "invalid_subscription_duration", "invalid subscription duration")
+
+	// ErrInvalidImportFormat is the error used when an import is requested in an unknown format.
+	ErrInvalidImportFormat = newError(KindInvalid, "invalid_import_format", "invalid import format")
+
+	// ErrInvalidExportFormat is the error used when an export is requested in an unknown format.
+	ErrInvalidExportFormat = newError(KindInvalid, "invalid_export_format", "invalid export format")
+
+	// ErrInvalidCustomerID is the error used when a given customer id is invalid.
+	ErrInvalidCustomerID = newError(KindInvalid, "invalid_customer_id", "invalid customer id")
+
+	// ErrInvalidReportPeriod is the error used when the range or the interval of a report is invalid.
+	ErrInvalidReportPeriod = newError(KindInvalid, "invalid_report_period", "invalid report period")
+
+	// ErrUnauthenticated is the error used when a request doesn't carry valid credentials.
+	ErrUnauthenticated = newError(KindUnauthenticated, "unauthenticated", "authentication required")
+
+	// ErrForbidden is the error used when the caller's roles don't allow an operation.
+	ErrForbidden = newError(KindForbidden, "forbidden", "operation not allowed")
+
+	// ErrAPIKeyNotfound is the error used when an API key doesn't exist.
+	ErrAPIKeyNotfound = newError(KindNotFound, "api_key_not_found", "api key not found")
+
+	// ErrInvalidAPIKey is the error used when an API key can't be created as requested.
+	ErrInvalidAPIKey = newError(KindInvalid, "invalid_api_key", "invalid api key")
+
+	// ErrRateLimited is the error used when a client sent too many requests.
+	ErrRateLimited = newError(KindRateLimited, "rate_limited", "rate limit exceeded")
 )
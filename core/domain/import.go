@@ -0,0 +1,36 @@
+package domain
+
+const (
+	// ImportFormatCSV represents comma separated rows with a header line.
+	ImportFormatCSV = "csv"
+	// ImportFormatJSONL represents one json object per line.
+	ImportFormatJSONL = "jsonl"
+)
+
+// ImportOptions represents the options of a bulk subscriptions import.
+type ImportOptions struct {
+	// Format of the rows, csv or jsonl.
+	Format string
+	// DryRun validates the rows without writing anything.
+	DryRun bool
+	// AllowPastStartDate skips the past start date check, e.g. for legacy subscriptions.
+	AllowPastStartDate bool
+	// BatchSize is the number of subscriptions inserted per transaction.
+	BatchSize int
+}
+
+// ImportRowError represents a row which couldn't be imported.
+type ImportRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+// ImportReport represents the outcome of a bulk subscriptions import.
+type ImportReport struct {
+	DryRun bool `json:"dry_run"`
+	Total  int  `json:"total"`
+	// Imported is the number of valid rows in a dry run.
+	Imported int              `json:"imported"`
+	Failed   int              `json:"failed"`
+	Errors   []ImportRowError `json:"errors"`
+}
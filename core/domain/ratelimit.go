@@ -0,0 +1,123 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RateLimit allows a number of requests per period, as a token bucket holding up to Requests
+// tokens and refilled at Requests per Per. Bursts up to Requests are allowed.
+type RateLimit struct {
+	Requests int
+	Per      time.Duration
+}
+
+// ParseRateLimit parses a rate limit written as requests/period, e.g. 100/1m.
+func ParseRateLimit(s string) (RateLimit, error) {
+	requests, period, ok := strings.Cut(strings.TrimSpace(s), "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q, expected requests/period", s)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q, requests must be a positive number", s)
+	}
+	per, err := time.ParseDuration(period)
+	if err != nil || per <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q, period must be a positive duration", s)
+	}
+	return RateLimit{Requests: n, Per: per}, nil
+}
+
+// String formats the rate limit like ParseRateLimit expects it.
+func (l RateLimit) String() string {
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+// Route groups the rate limits are configured for, shared by the http and gRPC apis. Every
+// client is limited by IP in the auth group before being authenticated, then by principal in the
+// group of the called api.
+const (
+	RateLimitGroupAuth         = "auth"
+	RateLimitGroupSubscription = "subscription"
+	RateLimitGroupProducts     = "products"
+	RateLimitGroupAdmin        = "admin"
+	RateLimitGroupReports      = "reports"
+)
+
+// RateLimits holds the rate limit of every route group.
+type RateLimits struct {
+	Default RateLimit
+	Groups  map[string]RateLimit
+}
+
+// For returns the rate limit of a route group.
+func (l RateLimits) For(group string) RateLimit {
+	if limit, ok := l.Groups[group]; ok {
+		return limit
+	}
+	return l.Default
+}
+
+// TokenBucket represents the tokens left to a client at a given time.
+type TokenBucket struct {
+	Tokens    float64
+	UpdatedAt time.Time
+}
+
+// RateLimitResult represents the outcome of a request against a rate limit.
+type RateLimitResult struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset is the time until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, zero when allowed.
+	RetryAfter time.Duration
+}
+
+// NewBucket returns a full bucket.
+func (l RateLimit) NewBucket(now time.Time) TokenBucket {
+	return TokenBucket{Tokens: float64(l.Requests), UpdatedAt: now}
+}
+
+// Take refills the bucket for the time elapsed since its last update and takes a token from
+// it if there is one left. The updated bucket is returned with the outcome.
+func (l RateLimit) Take(b TokenBucket, now time.Time) (TokenBucket, RateLimitResult) {
+	capacity := float64(l.Requests)
+	perToken := l.perToken()
+	b = l.refill(b, now)
+
+	result := RateLimitResult{Limit: l.Requests}
+	if b.Tokens >= 1 {
+		b.Tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - b.Tokens) * float64(perToken))
+	}
+	result.Remaining = int(b.Tokens)
+	result.Reset = time.Duration((capacity - b.Tokens) * float64(perToken))
+	return b, result
+}
+
+// Full reports whether the bucket is full again at a given time, so that it can be forgotten.
+func (l RateLimit) Full(b TokenBucket, now time.Time) bool {
+	return l.refill(b, now).Tokens >= float64(l.Requests)
+}
+
+// refill adds the tokens earned since the last update of the bucket, up to its capacity.
+func (l RateLimit) refill(b TokenBucket, now time.Time) TokenBucket {
+	if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = math.Min(float64(l.Requests), b.Tokens+float64(elapsed)/float64(l.perToken()))
+		b.UpdatedAt = now
+	}
+	return b
+}
+
+// perToken is the time to earn a token.
+func (l RateLimit) perToken() time.Duration {
+	return l.Per / time.Duration(l.Requests)
+}
@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RevenueEntryStatus represents the state of a monthly revenue entry.
+type RevenueEntryStatus string
+
+const (
+	// RevenueEntryScheduled entries are deferred until their recognition date, recognized after.
+	RevenueEntryScheduled RevenueEntryStatus = "scheduled"
+	// RevenueEntrySuspended entries are deferred while the subscription is paused, and
+	// rescheduled when it is resumed.
+	RevenueEntrySuspended RevenueEntryStatus = "suspended"
+	// RevenueEntryRefunded entries were paid back and are never recognized.
+	RevenueEntryRefunded RevenueEntryStatus = "refunded"
+)
+
+// RevenueEntry represents the revenue of one month of a subscription, booked when the
+// subscription is created and recognized once the month is over.
+type RevenueEntry struct {
+	ID             uuid.UUID          `json:"id" gorm:"type:uuid;primary_key;"`
+	SubscriptionID uuid.UUID          `json:"subscription_id"`
+	ProductID      uuid.UUID          `json:"product_id"`
+	Month          int8               `json:"month"` // 1 for the first month of the subscription
+	PeriodStart    time.Time          `json:"period_start"`
+	PeriodEnd      time.Time          `json:"period_end"`
+	Amount         float64            `json:"amount"` // Before tax
+	Status         RevenueEntryStatus `json:"status"`
+	RecognizeOn    time.Time          `json:"recognize_on"`
+	BookedOn       time.Time          `json:"booked_on"`
+	RefundedOn     *time.Time         `json:"refunded_on,omitempty"`
+}
+
+// Refund represents the amount paid back when a subscription is refunded.
+type Refund struct {
+	SubscriptionID uuid.UUID `json:"subscription_id"`
+	Amount         float64   `json:"amount"`
+	Months         int       `json:"months"`
+	RefundedOn     time.Time `json:"refunded_on"`
+}
+
+// PeriodCloseReport represents the revenue recognized and refunded over a period, and the
+// revenue still deferred at its end.
+type PeriodCloseReport struct {
+	From       time.Time            `json:"from"`
+	To         time.Time            `json:"to"`
+	Recognized float64              `json:"recognized"`
+	Refunded   float64              `json:"refunded"`
+	Deferred   float64              `json:"deferred"`
+	Products   []ProductRecognition `json:"products"`
+}
+
+// ProductRecognition represents the period close amounts of a single product.
+type ProductRecognition struct {
+	ProductID   uuid.UUID `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	Recognized  float64   `json:"recognized"`
+	Refunded    float64   `json:"refunded"`
+	Deferred    float64   `json:"deferred"`
+}
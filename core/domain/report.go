@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ReportInterval represents the length of the periods an activity report is split into.
+type ReportInterval string
+
+const (
+	// ReportIntervalDay splits a report in days.
+	ReportIntervalDay ReportInterval = "day"
+	// ReportIntervalWeek splits a report in weeks.
+	ReportIntervalWeek ReportInterval = "week"
+	// ReportIntervalMonth splits a report in calendar months.
+	ReportIntervalMonth ReportInterval = "month"
+)
+
+// RevenueReport represents the recurring revenue of the subscriptions active at a given date.
+// Amounts exclude tax, and multi-month subscriptions are normalized to a monthly amount.
+type RevenueReport struct {
+	AsOf                time.Time        `json:"as_of"`
+	MRR                 float64          `json:"mrr"`
+	ARR                 float64          `json:"arr"`
+	ActiveSubscriptions int              `json:"active_subscriptions"`
+	ActiveCustomers     int              `json:"active_customers"`
+	Products            []ProductRevenue `json:"products"`
+}
+
+// ProductRevenue represents the recurring revenue of a single product.
+type ProductRevenue struct {
+	ProductID           uuid.UUID `json:"product_id"`
+	ProductName         string    `json:"product_name"`
+	ActiveSubscriptions int       `json:"active_subscriptions"`
+	MRR                 float64   `json:"mrr"`
+	ARR                 float64   `json:"arr"`
+}
+
+// ActivityReport represents the subscriptions started, cancelled and paused over consecutive periods.
+type ActivityReport struct {
+	From     time.Time        `json:"from"`
+	To       time.Time        `json:"to"`
+	Interval ReportInterval   `json:"interval"`
+	Periods  []ActivityPeriod `json:"periods"`
+}
+
+// ActivityPeriod represents the activity of a period, from its start date included to its
+// end date excluded.
+type ActivityPeriod struct {
+	Start    time.Time         `json:"start"`
+	End      time.Time         `json:"end"`
+	New      int               `json:"new"`
+	Churned  int               `json:"churned"`
+	Paused   int               `json:"paused"`
+	Products []ProductActivity `json:"products"`
+}
+
+// ProductActivity represents the activity of a single product over a period.
+type ProductActivity struct {
+	ProductID   uuid.UUID `json:"product_id"`
+	ProductName string    `json:"product_name"`
+	New         int       `json:"new"`
+	Churned     int       `json:"churned"`
+	Paused      int       `json:"paused"`
+}
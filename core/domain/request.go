@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// requestIDKey is the context key of the request id.
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the id correlating the logs of a request.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request id carried by ctx, empty if none.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// maxRequestIDLength bounds the request ids passed by the clients.
+const maxRequestIDLength = 128
+
+// NewRequestID returns the request id passed by a client, or a new one unless it is short and
+// printable, not to forge log entries.
+func NewRequestID(passed string) string {
+	if passed == "" || len(passed) > maxRequestIDLength {
+		return uuid.NewString()
+	}
+	for _, c := range passed {
+		if c < '!' || c > '~' {
+			return uuid.NewString()
+		}
+	}
+	return passed
+}
+
+// primaryReadsKey is the context key forcing the reads on the primary database.
+type primaryReadsKey struct{}
+
+// ContextWithPrimaryReads returns a copy of ctx whose reads go to the primary database instead
+// of a replica, e.g. for a client reading its own writes.
+func ContextWithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryReadsKey{}, true)
+}
+
+// PrimaryReadsFromContext reports whether the reads of ctx must go to the primary database.
+func PrimaryReadsFromContext(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryReadsKey{}).(bool)
+	return primary
+}
@@ -6,6 +6,7 @@ package ports
 
 import (
 	context "context"
+	io "io"
 	reflect "reflect"
 	time "time"
 
@@ -37,6 +38,20 @@ func (m *MockProductsRepository) EXPECT() *MockProductsRepositoryMockRecorder {
 	return m.recorder
 }
 
+// Create mocks base method.
+func (m *MockProductsRepository) Create(ctx context.Context, product domain.Product) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", ctx, product)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockProductsRepositoryMockRecorder) Create(ctx, product interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockProductsRepository)(nil).Create), ctx, product)
+}
+
 // GetAll mocks base method.
 func (m *MockProductsRepository) GetAll(ctx context.Context) ([]domain.Product, error) {
 	m.ctrl.T.Helper()
@@ -67,6 +82,21 @@ func (mr *MockProductsRepositoryMockRecorder) GetByID(ctx, id interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockProductsRepository)(nil).GetByID), ctx, id)
 }
 
+// Search mocks base method.
+func (m *MockProductsRepository) Search(ctx context.Context, query domain.ProductQuery) ([]domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query)
+	ret0, _ := ret[0].([]domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockProductsRepositoryMockRecorder) Search(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockProductsRepository)(nil).Search), ctx, query)
+}
+
 // MockSubscriptionsRepository is a mock of SubscriptionsRepository interface.
 type MockSubscriptionsRepository struct {
 	ctrl     *gomock.Controller
@@ -104,6 +134,20 @@ func (mr *MockSubscriptionsRepositoryMockRecorder) Create(ctx, sub interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSubscriptionsRepository)(nil).Create), ctx, sub)
 }
 
+// CreateBatch mocks base method.
+func (m *MockSubscriptionsRepository) CreateBatch(ctx context.Context, subs []domain.Subscription) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBatch", ctx, subs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateBatch indicates an expected call of CreateBatch.
+func (mr *MockSubscriptionsRepositoryMockRecorder) CreateBatch(ctx, subs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBatch", reflect.TypeOf((*MockSubscriptionsRepository)(nil).CreateBatch), ctx, subs)
+}
+
 // GetByID mocks base method.
 func (m *MockSubscriptionsRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.Subscription, error) {
 	m.ctrl.T.Helper()
@@ -119,135 +163,1140 @@ func (mr *MockSubscriptionsRepositoryMockRecorder) GetByID(ctx, id interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByID", reflect.TypeOf((*MockSubscriptionsRepository)(nil).GetByID), ctx, id)
 }
 
+// List mocks base method.
+func (m *MockSubscriptionsRepository) List(ctx context.Context, filter domain.SubscriptionFilter) ([]domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockSubscriptionsRepositoryMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockSubscriptionsRepository)(nil).List), ctx, filter)
+}
+
 // Patch mocks base method.
-func (m *MockSubscriptionsRepository) Patch(ctx context.Context, id uuid.UUID, update map[string]interface{}) error {
+func (m *MockSubscriptionsRepository) Patch(ctx context.Context, id uuid.UUID, version int, update map[string]interface{}) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Patch", ctx, id, update)
+	ret := m.ctrl.Call(m, "Patch", ctx, id, version, update)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Patch indicates an expected call of Patch.
-func (mr *MockSubscriptionsRepositoryMockRecorder) Patch(ctx, id, update interface{}) *gomock.Call {
+func (mr *MockSubscriptionsRepositoryMockRecorder) Patch(ctx, id, version, update interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockSubscriptionsRepository)(nil).Patch), ctx, id, update)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Patch", reflect.TypeOf((*MockSubscriptionsRepository)(nil).Patch), ctx, id, version, update)
 }
 
-// MockSubscriptionService is a mock of SubscriptionService interface.
-type MockSubscriptionService struct {
+// Stream mocks base method.
+func (m *MockSubscriptionsRepository) Stream(ctx context.Context, filter domain.SubscriptionFilter, fn func(domain.SubscriptionExportRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stream", ctx, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Stream indicates an expected call of Stream.
+func (mr *MockSubscriptionsRepositoryMockRecorder) Stream(ctx, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stream", reflect.TypeOf((*MockSubscriptionsRepository)(nil).Stream), ctx, filter, fn)
+}
+
+// MockReportsRepository is a mock of ReportsRepository interface.
+type MockReportsRepository struct {
 	ctrl     *gomock.Controller
-	recorder *MockSubscriptionServiceMockRecorder
+	recorder *MockReportsRepositoryMockRecorder
 }
 
-// MockSubscriptionServiceMockRecorder is the mock recorder for MockSubscriptionService.
-type MockSubscriptionServiceMockRecorder struct {
-	mock *MockSubscriptionService
+// MockReportsRepositoryMockRecorder is the mock recorder for MockReportsRepository.
+type MockReportsRepositoryMockRecorder struct {
+	mock *MockReportsRepository
 }
 
-// NewMockSubscriptionService creates a new mock instance.
-func NewMockSubscriptionService(ctrl *gomock.Controller) *MockSubscriptionService {
-	mock := &MockSubscriptionService{ctrl: ctrl}
-	mock.recorder = &MockSubscriptionServiceMockRecorder{mock}
+// NewMockReportsRepository creates a new mock instance.
+func NewMockReportsRepository(ctrl *gomock.Controller) *MockReportsRepository {
+	mock := &MockReportsRepository{ctrl: ctrl}
+	mock.recorder = &MockReportsRepositoryMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockSubscriptionService) EXPECT() *MockSubscriptionServiceMockRecorder {
+func (m *MockReportsRepository) EXPECT() *MockReportsRepositoryMockRecorder {
 	return m.recorder
 }
 
-// CreateSubscription mocks base method.
-func (m *MockSubscriptionService) CreateSubscription(ctx context.Context, pID uuid.UUID, durationInMonths int8, startDate time.Time) error {
+// ActiveCustomers mocks base method.
+func (m *MockReportsRepository) ActiveCustomers(ctx context.Context, asOf time.Time) (int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateSubscription", ctx, pID, durationInMonths, startDate)
+	ret := m.ctrl.Call(m, "ActiveCustomers", ctx, asOf)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActiveCustomers indicates an expected call of ActiveCustomers.
+func (mr *MockReportsRepositoryMockRecorder) ActiveCustomers(ctx, asOf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActiveCustomers", reflect.TypeOf((*MockReportsRepository)(nil).ActiveCustomers), ctx, asOf)
+}
+
+// Activity mocks base method.
+func (m *MockReportsRepository) Activity(ctx context.Context, from, to time.Time) ([]domain.ProductActivity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Activity", ctx, from, to)
+	ret0, _ := ret[0].([]domain.ProductActivity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Activity indicates an expected call of Activity.
+func (mr *MockReportsRepositoryMockRecorder) Activity(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Activity", reflect.TypeOf((*MockReportsRepository)(nil).Activity), ctx, from, to)
+}
+
+// Revenue mocks base method.
+func (m *MockReportsRepository) Revenue(ctx context.Context, asOf time.Time) ([]domain.ProductRevenue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revenue", ctx, asOf)
+	ret0, _ := ret[0].([]domain.ProductRevenue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Revenue indicates an expected call of Revenue.
+func (mr *MockReportsRepositoryMockRecorder) Revenue(ctx, asOf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revenue", reflect.TypeOf((*MockReportsRepository)(nil).Revenue), ctx, asOf)
+}
+
+// MockRevenueEntriesRepository is a mock of RevenueEntriesRepository interface.
+type MockRevenueEntriesRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockRevenueEntriesRepositoryMockRecorder
+}
+
+// MockRevenueEntriesRepositoryMockRecorder is the mock recorder for MockRevenueEntriesRepository.
+type MockRevenueEntriesRepositoryMockRecorder struct {
+	mock *MockRevenueEntriesRepository
+}
+
+// NewMockRevenueEntriesRepository creates a new mock instance.
+func NewMockRevenueEntriesRepository(ctrl *gomock.Controller) *MockRevenueEntriesRepository {
+	mock := &MockRevenueEntriesRepository{ctrl: ctrl}
+	mock.recorder = &MockRevenueEntriesRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRevenueEntriesRepository) EXPECT() *MockRevenueEntriesRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateBatch mocks base method.
+func (m *MockRevenueEntriesRepository) CreateBatch(ctx context.Context, entries []domain.RevenueEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateBatch", ctx, entries)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// CreateSubscription indicates an expected call of CreateSubscription.
-func (mr *MockSubscriptionServiceMockRecorder) CreateSubscription(ctx, pID, durationInMonths, startDate interface{}) *gomock.Call {
+// CreateBatch indicates an expected call of CreateBatch.
+func (mr *MockRevenueEntriesRepositoryMockRecorder) CreateBatch(ctx, entries interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockSubscriptionService)(nil).CreateSubscription), ctx, pID, durationInMonths, startDate)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateBatch", reflect.TypeOf((*MockRevenueEntriesRepository)(nil).CreateBatch), ctx, entries)
 }
 
-// FetchSubscription mocks base method.
-func (m *MockSubscriptionService) FetchSubscription(ctx context.Context, id uuid.UUID) (domain.Subscription, error) {
+// ListBySubscription mocks base method.
+func (m *MockRevenueEntriesRepository) ListBySubscription(ctx context.Context, subscriptionID uuid.UUID) ([]domain.RevenueEntry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FetchSubscription", ctx, id)
-	ret0, _ := ret[0].(domain.Subscription)
+	ret := m.ctrl.Call(m, "ListBySubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].([]domain.RevenueEntry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FetchSubscription indicates an expected call of FetchSubscription.
-func (mr *MockSubscriptionServiceMockRecorder) FetchSubscription(ctx, id interface{}) *gomock.Call {
+// ListBySubscription indicates an expected call of ListBySubscription.
+func (mr *MockRevenueEntriesRepositoryMockRecorder) ListBySubscription(ctx, subscriptionID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchSubscription", reflect.TypeOf((*MockSubscriptionService)(nil).FetchSubscription), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBySubscription", reflect.TypeOf((*MockRevenueEntriesRepository)(nil).ListBySubscription), ctx, subscriptionID)
 }
 
-// UpdateSubscriptionStatus mocks base method.
-func (m *MockSubscriptionService) UpdateSubscriptionStatus(ctx context.Context, id uuid.UUID, status domain.SubscriptionStatus) error {
+// PeriodClose mocks base method.
+func (m *MockRevenueEntriesRepository) PeriodClose(ctx context.Context, from, to time.Time) ([]domain.ProductRecognition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeriodClose", ctx, from, to)
+	ret0, _ := ret[0].([]domain.ProductRecognition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeriodClose indicates an expected call of PeriodClose.
+func (mr *MockRevenueEntriesRepositoryMockRecorder) PeriodClose(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeriodClose", reflect.TypeOf((*MockRevenueEntriesRepository)(nil).PeriodClose), ctx, from, to)
+}
+
+// Update mocks base method.
+func (m *MockRevenueEntriesRepository) Update(ctx context.Context, entries []domain.RevenueEntry) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateSubscriptionStatus", ctx, id, status)
+	ret := m.ctrl.Call(m, "Update", ctx, entries)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdateSubscriptionStatus indicates an expected call of UpdateSubscriptionStatus.
-func (mr *MockSubscriptionServiceMockRecorder) UpdateSubscriptionStatus(ctx, id, status interface{}) *gomock.Call {
+// Update indicates an expected call of Update.
+func (mr *MockRevenueEntriesRepositoryMockRecorder) Update(ctx, entries interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscriptionStatus", reflect.TypeOf((*MockSubscriptionService)(nil).UpdateSubscriptionStatus), ctx, id, status)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockRevenueEntriesRepository)(nil).Update), ctx, entries)
 }
 
-// MockProductsService is a mock of ProductsService interface.
-type MockProductsService struct {
+// MockAPIKeysRepository is a mock of APIKeysRepository interface.
+type MockAPIKeysRepository struct {
 	ctrl     *gomock.Controller
-	recorder *MockProductsServiceMockRecorder
+	recorder *MockAPIKeysRepositoryMockRecorder
 }
 
-// MockProductsServiceMockRecorder is the mock recorder for MockProductsService.
-type MockProductsServiceMockRecorder struct {
-	mock *MockProductsService
+// MockAPIKeysRepositoryMockRecorder is the mock recorder for MockAPIKeysRepository.
+type MockAPIKeysRepositoryMockRecorder struct {
+	mock *MockAPIKeysRepository
 }
 
-// NewMockProductsService creates a new mock instance.
-func NewMockProductsService(ctrl *gomock.Controller) *MockProductsService {
-	mock := &MockProductsService{ctrl: ctrl}
-	mock.recorder = &MockProductsServiceMockRecorder{mock}
+// NewMockAPIKeysRepository creates a new mock instance.
+func NewMockAPIKeysRepository(ctrl *gomock.Controller) *MockAPIKeysRepository {
+	mock := &MockAPIKeysRepository{ctrl: ctrl}
+	mock.recorder = &MockAPIKeysRepositoryMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockProductsService) EXPECT() *MockProductsServiceMockRecorder {
+func (m *MockAPIKeysRepository) EXPECT() *MockAPIKeysRepositoryMockRecorder {
 	return m.recorder
 }
 
-// FetchAllProducts mocks base method.
-func (m *MockProductsService) FetchAllProducts(ctx context.Context) ([]domain.Product, error) {
+// Create mocks base method.
+func (m *MockAPIKeysRepository) Create(ctx context.Context, key domain.APIKey) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FetchAllProducts", ctx)
-	ret0, _ := ret[0].([]domain.Product)
+	ret := m.ctrl.Call(m, "Create", ctx, key)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockAPIKeysRepositoryMockRecorder) Create(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockAPIKeysRepository)(nil).Create), ctx, key)
+}
+
+// GetByPrefix mocks base method.
+func (m *MockAPIKeysRepository) GetByPrefix(ctx context.Context, prefix string) (domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetByPrefix", ctx, prefix)
+	ret0, _ := ret[0].(domain.APIKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FetchAllProducts indicates an expected call of FetchAllProducts.
-func (mr *MockProductsServiceMockRecorder) FetchAllProducts(ctx interface{}) *gomock.Call {
+// GetByPrefix indicates an expected call of GetByPrefix.
+func (mr *MockAPIKeysRepositoryMockRecorder) GetByPrefix(ctx, prefix interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAllProducts", reflect.TypeOf((*MockProductsService)(nil).FetchAllProducts), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByPrefix", reflect.TypeOf((*MockAPIKeysRepository)(nil).GetByPrefix), ctx, prefix)
 }
 
-// FetchProduct mocks base method.
-func (m *MockProductsService) FetchProduct(ctx context.Context, id uuid.UUID) (domain.Product, error) {
+// List mocks base method.
+func (m *MockAPIKeysRepository) List(ctx context.Context) ([]domain.APIKey, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FetchProduct", ctx, id)
-	ret0, _ := ret[0].(domain.Product)
+	ret := m.ctrl.Call(m, "List", ctx)
+	ret0, _ := ret[0].([]domain.APIKey)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FetchProduct indicates an expected call of FetchProduct.
-func (mr *MockProductsServiceMockRecorder) FetchProduct(ctx, id interface{}) *gomock.Call {
+// List indicates an expected call of List.
+func (mr *MockAPIKeysRepositoryMockRecorder) List(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchProduct", reflect.TypeOf((*MockProductsService)(nil).FetchProduct), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockAPIKeysRepository)(nil).List), ctx)
+}
+
+// Revoke mocks base method.
+func (m *MockAPIKeysRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Revoke", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Revoke indicates an expected call of Revoke.
+func (mr *MockAPIKeysRepositoryMockRecorder) Revoke(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Revoke", reflect.TypeOf((*MockAPIKeysRepository)(nil).Revoke), ctx, id, at)
+}
+
+// Touch mocks base method.
+func (m *MockAPIKeysRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Touch", ctx, id, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Touch indicates an expected call of Touch.
+func (mr *MockAPIKeysRepositoryMockRecorder) Touch(ctx, id, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Touch", reflect.TypeOf((*MockAPIKeysRepository)(nil).Touch), ctx, id, at)
+}
+
+// MockRateLimiter is a mock of RateLimiter interface.
+type MockRateLimiter struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateLimiterMockRecorder
+}
+
+// MockRateLimiterMockRecorder is the mock recorder for MockRateLimiter.
+type MockRateLimiterMockRecorder struct {
+	mock *MockRateLimiter
+}
+
+// NewMockRateLimiter creates a new mock instance.
+func NewMockRateLimiter(ctrl *gomock.Controller) *MockRateLimiter {
+	mock := &MockRateLimiter{ctrl: ctrl}
+	mock.recorder = &MockRateLimiterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateLimiter) EXPECT() *MockRateLimiterMockRecorder {
+	return m.recorder
+}
+
+// Allow mocks base method.
+func (m *MockRateLimiter) Allow(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (domain.RateLimitResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Allow", ctx, key, limit, now)
+	ret0, _ := ret[0].(domain.RateLimitResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Allow indicates an expected call of Allow.
+func (mr *MockRateLimiterMockRecorder) Allow(ctx, key, limit, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Allow", reflect.TypeOf((*MockRateLimiter)(nil).Allow), ctx, key, limit, now)
+}
+
+// MockCache is a mock of Cache interface.
+type MockCache struct {
+	ctrl     *gomock.Controller
+	recorder *MockCacheMockRecorder
+}
+
+// MockCacheMockRecorder is the mock recorder for MockCache.
+type MockCacheMockRecorder struct {
+	mock *MockCache
+}
+
+// NewMockCache creates a new mock instance.
+func NewMockCache(ctrl *gomock.Controller) *MockCache {
+	mock := &MockCache{ctrl: ctrl}
+	mock.recorder = &MockCacheMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCache) EXPECT() *MockCacheMockRecorder {
+	return m.recorder
+}
+
+// Delete mocks base method.
+func (m *MockCache) Delete(ctx context.Context, keys ...string) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Delete", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCacheMockRecorder) Delete(ctx interface{}, keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, keys...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCache)(nil).Delete), varargs...)
+}
+
+// Get mocks base method.
+func (m *MockCache) Get(ctx context.Context, key string, now time.Time) ([]byte, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx, key, now)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockCacheMockRecorder) Get(ctx, key, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockCache)(nil).Get), ctx, key, now)
+}
+
+// Set mocks base method.
+func (m *MockCache) Set(ctx context.Context, key string, value []byte, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", ctx, key, value, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockCacheMockRecorder) Set(ctx, key, value, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockCache)(nil).Set), ctx, key, value, expiresAt)
+}
+
+// MockTxManager is a mock of TxManager interface.
+type MockTxManager struct {
+	ctrl     *gomock.Controller
+	recorder *MockTxManagerMockRecorder
+}
+
+// MockTxManagerMockRecorder is the mock recorder for MockTxManager.
+type MockTxManagerMockRecorder struct {
+	mock *MockTxManager
+}
+
+// NewMockTxManager creates a new mock instance.
+func NewMockTxManager(ctrl *gomock.Controller) *MockTxManager {
+	mock := &MockTxManager{ctrl: ctrl}
+	mock.recorder = &MockTxManagerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTxManager) EXPECT() *MockTxManagerMockRecorder {
+	return m.recorder
+}
+
+// WithinTransaction mocks base method.
+func (m *MockTxManager) WithinTransaction(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTransaction", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTransaction indicates an expected call of WithinTransaction.
+func (mr *MockTxManagerMockRecorder) WithinTransaction(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTransaction", reflect.TypeOf((*MockTxManager)(nil).WithinTransaction), ctx, fn)
+}
+
+// MockTokenVerifier is a mock of TokenVerifier interface.
+type MockTokenVerifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockTokenVerifierMockRecorder
+}
+
+// MockTokenVerifierMockRecorder is the mock recorder for MockTokenVerifier.
+type MockTokenVerifierMockRecorder struct {
+	mock *MockTokenVerifier
+}
+
+// NewMockTokenVerifier creates a new mock instance.
+func NewMockTokenVerifier(ctrl *gomock.Controller) *MockTokenVerifier {
+	mock := &MockTokenVerifier{ctrl: ctrl}
+	mock.recorder = &MockTokenVerifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTokenVerifier) EXPECT() *MockTokenVerifierMockRecorder {
+	return m.recorder
+}
+
+// Verify mocks base method.
+func (m *MockTokenVerifier) Verify(token string) (domain.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Verify", token)
+	ret0, _ := ret[0].(domain.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Verify indicates an expected call of Verify.
+func (mr *MockTokenVerifierMockRecorder) Verify(token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Verify", reflect.TypeOf((*MockTokenVerifier)(nil).Verify), token)
+}
+
+// MockMetrics is a mock of Metrics interface.
+type MockMetrics struct {
+	ctrl     *gomock.Controller
+	recorder *MockMetricsMockRecorder
+}
+
+// MockMetricsMockRecorder is the mock recorder for MockMetrics.
+type MockMetricsMockRecorder struct {
+	mock *MockMetrics
+}
+
+// NewMockMetrics creates a new mock instance.
+func NewMockMetrics(ctrl *gomock.Controller) *MockMetrics {
+	mock := &MockMetrics{ctrl: ctrl}
+	mock.recorder = &MockMetricsMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMetrics) EXPECT() *MockMetricsMockRecorder {
+	return m.recorder
+}
+
+// CacheLookup mocks base method.
+func (m *MockMetrics) CacheLookup(cache, tier string, hit bool) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "CacheLookup", cache, tier, hit)
+}
+
+// CacheLookup indicates an expected call of CacheLookup.
+func (mr *MockMetricsMockRecorder) CacheLookup(cache, tier, hit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CacheLookup", reflect.TypeOf((*MockMetrics)(nil).CacheLookup), cache, tier, hit)
+}
+
+// SubscriptionStatusChanged mocks base method.
+func (m *MockMetrics) SubscriptionStatusChanged(from, to domain.SubscriptionStatus) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubscriptionStatusChanged", from, to)
+}
+
+// SubscriptionStatusChanged indicates an expected call of SubscriptionStatusChanged.
+func (mr *MockMetricsMockRecorder) SubscriptionStatusChanged(from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionStatusChanged", reflect.TypeOf((*MockMetrics)(nil).SubscriptionStatusChanged), from, to)
+}
+
+// SubscriptionsCreated mocks base method.
+func (m *MockMetrics) SubscriptionsCreated(productID uuid.UUID, status domain.SubscriptionStatus, count int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SubscriptionsCreated", productID, status, count)
+}
+
+// SubscriptionsCreated indicates an expected call of SubscriptionsCreated.
+func (mr *MockMetricsMockRecorder) SubscriptionsCreated(productID, status, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscriptionsCreated", reflect.TypeOf((*MockMetrics)(nil).SubscriptionsCreated), productID, status, count)
+}
+
+// MockLogger is a mock of Logger interface.
+type MockLogger struct {
+	ctrl     *gomock.Controller
+	recorder *MockLoggerMockRecorder
+}
+
+// MockLoggerMockRecorder is the mock recorder for MockLogger.
+type MockLoggerMockRecorder struct {
+	mock *MockLogger
+}
+
+// NewMockLogger creates a new mock instance.
+func NewMockLogger(ctrl *gomock.Controller) *MockLogger {
+	mock := &MockLogger{ctrl: ctrl}
+	mock.recorder = &MockLoggerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLogger) EXPECT() *MockLoggerMockRecorder {
+	return m.recorder
+}
+
+// Debug mocks base method.
+func (m *MockLogger) Debug(ctx context.Context, msg string, fields ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, msg}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Debug", varargs...)
+}
+
+// Debug indicates an expected call of Debug.
+func (mr *MockLoggerMockRecorder) Debug(ctx, msg interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, msg}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Debug", reflect.TypeOf((*MockLogger)(nil).Debug), varargs...)
+}
+
+// Error mocks base method.
+func (m *MockLogger) Error(ctx context.Context, msg string, fields ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, msg}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Error", varargs...)
+}
+
+// Error indicates an expected call of Error.
+func (mr *MockLoggerMockRecorder) Error(ctx, msg interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, msg}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockLogger)(nil).Error), varargs...)
+}
+
+// Info mocks base method.
+func (m *MockLogger) Info(ctx context.Context, msg string, fields ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, msg}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Info", varargs...)
+}
+
+// Info indicates an expected call of Info.
+func (mr *MockLoggerMockRecorder) Info(ctx, msg interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, msg}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Info", reflect.TypeOf((*MockLogger)(nil).Info), varargs...)
+}
+
+// Warn mocks base method.
+func (m *MockLogger) Warn(ctx context.Context, msg string, fields ...interface{}) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, msg}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "Warn", varargs...)
+}
+
+// Warn indicates an expected call of Warn.
+func (mr *MockLoggerMockRecorder) Warn(ctx, msg interface{}, fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, msg}, fields...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Warn", reflect.TypeOf((*MockLogger)(nil).Warn), varargs...)
+}
+
+// With mocks base method.
+func (m *MockLogger) With(fields ...interface{}) Logger {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range fields {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "With", varargs...)
+	ret0, _ := ret[0].(Logger)
+	return ret0
+}
+
+// With indicates an expected call of With.
+func (mr *MockLoggerMockRecorder) With(fields ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "With", reflect.TypeOf((*MockLogger)(nil).With), fields...)
+}
+
+// MockSubscriptionService is a mock of SubscriptionService interface.
+type MockSubscriptionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubscriptionServiceMockRecorder
+}
+
+// MockSubscriptionServiceMockRecorder is the mock recorder for MockSubscriptionService.
+type MockSubscriptionServiceMockRecorder struct {
+	mock *MockSubscriptionService
+}
+
+// NewMockSubscriptionService creates a new mock instance.
+func NewMockSubscriptionService(ctrl *gomock.Controller) *MockSubscriptionService {
+	mock := &MockSubscriptionService{ctrl: ctrl}
+	mock.recorder = &MockSubscriptionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubscriptionService) EXPECT() *MockSubscriptionServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateSubscription mocks base method.
+func (m *MockSubscriptionService) CreateSubscription(ctx context.Context, pID uuid.UUID, durationInMonths int8, startDate time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSubscription", ctx, pID, durationInMonths, startDate)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSubscription indicates an expected call of CreateSubscription.
+func (mr *MockSubscriptionServiceMockRecorder) CreateSubscription(ctx, pID, durationInMonths, startDate interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSubscription", reflect.TypeOf((*MockSubscriptionService)(nil).CreateSubscription), ctx, pID, durationInMonths, startDate)
+}
+
+// ExportSubscriptions mocks base method.
+func (m *MockSubscriptionService) ExportSubscriptions(ctx context.Context, filter domain.SubscriptionFilter, fn func(domain.SubscriptionExportRow) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportSubscriptions", ctx, filter, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportSubscriptions indicates an expected call of ExportSubscriptions.
+func (mr *MockSubscriptionServiceMockRecorder) ExportSubscriptions(ctx, filter, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportSubscriptions", reflect.TypeOf((*MockSubscriptionService)(nil).ExportSubscriptions), ctx, filter, fn)
+}
+
+// FetchSubscription mocks base method.
+func (m *MockSubscriptionService) FetchSubscription(ctx context.Context, id uuid.UUID) (domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchSubscription", ctx, id)
+	ret0, _ := ret[0].(domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchSubscription indicates an expected call of FetchSubscription.
+func (mr *MockSubscriptionServiceMockRecorder) FetchSubscription(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchSubscription", reflect.TypeOf((*MockSubscriptionService)(nil).FetchSubscription), ctx, id)
+}
+
+// ListSubscriptions mocks base method.
+func (m *MockSubscriptionService) ListSubscriptions(ctx context.Context, filter domain.SubscriptionFilter) ([]domain.Subscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSubscriptions", ctx, filter)
+	ret0, _ := ret[0].([]domain.Subscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSubscriptions indicates an expected call of ListSubscriptions.
+func (mr *MockSubscriptionServiceMockRecorder) ListSubscriptions(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSubscriptions", reflect.TypeOf((*MockSubscriptionService)(nil).ListSubscriptions), ctx, filter)
+}
+
+// UpdateSubscriptionStatus mocks base method.
+func (m *MockSubscriptionService) UpdateSubscriptionStatus(ctx context.Context, id uuid.UUID, version int, status domain.SubscriptionStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSubscriptionStatus", ctx, id, version, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSubscriptionStatus indicates an expected call of UpdateSubscriptionStatus.
+func (mr *MockSubscriptionServiceMockRecorder) UpdateSubscriptionStatus(ctx, id, version, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSubscriptionStatus", reflect.TypeOf((*MockSubscriptionService)(nil).UpdateSubscriptionStatus), ctx, id, version, status)
+}
+
+// MockSubscriptionImporter is a mock of SubscriptionImporter interface.
+type MockSubscriptionImporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubscriptionImporterMockRecorder
+}
+
+// MockSubscriptionImporterMockRecorder is the mock recorder for MockSubscriptionImporter.
+type MockSubscriptionImporterMockRecorder struct {
+	mock *MockSubscriptionImporter
+}
+
+// NewMockSubscriptionImporter creates a new mock instance.
+func NewMockSubscriptionImporter(ctrl *gomock.Controller) *MockSubscriptionImporter {
+	mock := &MockSubscriptionImporter{ctrl: ctrl}
+	mock.recorder = &MockSubscriptionImporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubscriptionImporter) EXPECT() *MockSubscriptionImporterMockRecorder {
+	return m.recorder
+}
+
+// ImportSubscriptions mocks base method.
+func (m *MockSubscriptionImporter) ImportSubscriptions(ctx context.Context, r io.Reader, opts domain.ImportOptions) (domain.ImportReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ImportSubscriptions", ctx, r, opts)
+	ret0, _ := ret[0].(domain.ImportReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ImportSubscriptions indicates an expected call of ImportSubscriptions.
+func (mr *MockSubscriptionImporterMockRecorder) ImportSubscriptions(ctx, r, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ImportSubscriptions", reflect.TypeOf((*MockSubscriptionImporter)(nil).ImportSubscriptions), ctx, r, opts)
+}
+
+// MockReportsService is a mock of ReportsService interface.
+type MockReportsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportsServiceMockRecorder
+}
+
+// MockReportsServiceMockRecorder is the mock recorder for MockReportsService.
+type MockReportsServiceMockRecorder struct {
+	mock *MockReportsService
+}
+
+// NewMockReportsService creates a new mock instance.
+func NewMockReportsService(ctrl *gomock.Controller) *MockReportsService {
+	mock := &MockReportsService{ctrl: ctrl}
+	mock.recorder = &MockReportsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportsService) EXPECT() *MockReportsServiceMockRecorder {
+	return m.recorder
+}
+
+// ActivityReport mocks base method.
+func (m *MockReportsService) ActivityReport(ctx context.Context, from, to time.Time, interval domain.ReportInterval) (domain.ActivityReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActivityReport", ctx, from, to, interval)
+	ret0, _ := ret[0].(domain.ActivityReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActivityReport indicates an expected call of ActivityReport.
+func (mr *MockReportsServiceMockRecorder) ActivityReport(ctx, from, to, interval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActivityReport", reflect.TypeOf((*MockReportsService)(nil).ActivityReport), ctx, from, to, interval)
+}
+
+// RevenueReport mocks base method.
+func (m *MockReportsService) RevenueReport(ctx context.Context, asOf time.Time) (domain.RevenueReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevenueReport", ctx, asOf)
+	ret0, _ := ret[0].(domain.RevenueReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevenueReport indicates an expected call of RevenueReport.
+func (mr *MockReportsServiceMockRecorder) RevenueReport(ctx, asOf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevenueReport", reflect.TypeOf((*MockReportsService)(nil).RevenueReport), ctx, asOf)
+}
+
+// MockRevenueRecognizer is a mock of RevenueRecognizer interface.
+type MockRevenueRecognizer struct {
+	ctrl     *gomock.Controller
+	recorder *MockRevenueRecognizerMockRecorder
+}
+
+// MockRevenueRecognizerMockRecorder is the mock recorder for MockRevenueRecognizer.
+type MockRevenueRecognizerMockRecorder struct {
+	mock *MockRevenueRecognizer
+}
+
+// NewMockRevenueRecognizer creates a new mock instance.
+func NewMockRevenueRecognizer(ctrl *gomock.Controller) *MockRevenueRecognizer {
+	mock := &MockRevenueRecognizer{ctrl: ctrl}
+	mock.recorder = &MockRevenueRecognizerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRevenueRecognizer) EXPECT() *MockRevenueRecognizerMockRecorder {
+	return m.recorder
+}
+
+// AdjustRevenue mocks base method.
+func (m *MockRevenueRecognizer) AdjustRevenue(ctx context.Context, sub domain.Subscription, status domain.SubscriptionStatus, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustRevenue", ctx, sub, status, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustRevenue indicates an expected call of AdjustRevenue.
+func (mr *MockRevenueRecognizerMockRecorder) AdjustRevenue(ctx, sub, status, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustRevenue", reflect.TypeOf((*MockRevenueRecognizer)(nil).AdjustRevenue), ctx, sub, status, at)
+}
+
+// ScheduleRevenue mocks base method.
+func (m *MockRevenueRecognizer) ScheduleRevenue(ctx context.Context, subs []domain.Subscription, bookedOn time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleRevenue", ctx, subs, bookedOn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleRevenue indicates an expected call of ScheduleRevenue.
+func (mr *MockRevenueRecognizerMockRecorder) ScheduleRevenue(ctx, subs, bookedOn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleRevenue", reflect.TypeOf((*MockRevenueRecognizer)(nil).ScheduleRevenue), ctx, subs, bookedOn)
+}
+
+// MockRecognitionService is a mock of RecognitionService interface.
+type MockRecognitionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecognitionServiceMockRecorder
+}
+
+// MockRecognitionServiceMockRecorder is the mock recorder for MockRecognitionService.
+type MockRecognitionServiceMockRecorder struct {
+	mock *MockRecognitionService
+}
+
+// NewMockRecognitionService creates a new mock instance.
+func NewMockRecognitionService(ctrl *gomock.Controller) *MockRecognitionService {
+	mock := &MockRecognitionService{ctrl: ctrl}
+	mock.recorder = &MockRecognitionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecognitionService) EXPECT() *MockRecognitionServiceMockRecorder {
+	return m.recorder
+}
+
+// AdjustRevenue mocks base method.
+func (m *MockRecognitionService) AdjustRevenue(ctx context.Context, sub domain.Subscription, status domain.SubscriptionStatus, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustRevenue", ctx, sub, status, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AdjustRevenue indicates an expected call of AdjustRevenue.
+func (mr *MockRecognitionServiceMockRecorder) AdjustRevenue(ctx, sub, status, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustRevenue", reflect.TypeOf((*MockRecognitionService)(nil).AdjustRevenue), ctx, sub, status, at)
+}
+
+// PeriodCloseReport mocks base method.
+func (m *MockRecognitionService) PeriodCloseReport(ctx context.Context, from, to time.Time) (domain.PeriodCloseReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PeriodCloseReport", ctx, from, to)
+	ret0, _ := ret[0].(domain.PeriodCloseReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PeriodCloseReport indicates an expected call of PeriodCloseReport.
+func (mr *MockRecognitionServiceMockRecorder) PeriodCloseReport(ctx, from, to interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PeriodCloseReport", reflect.TypeOf((*MockRecognitionService)(nil).PeriodCloseReport), ctx, from, to)
+}
+
+// RefundSubscription mocks base method.
+func (m *MockRecognitionService) RefundSubscription(ctx context.Context, subscriptionID uuid.UUID) (domain.Refund, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefundSubscription", ctx, subscriptionID)
+	ret0, _ := ret[0].(domain.Refund)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefundSubscription indicates an expected call of RefundSubscription.
+func (mr *MockRecognitionServiceMockRecorder) RefundSubscription(ctx, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefundSubscription", reflect.TypeOf((*MockRecognitionService)(nil).RefundSubscription), ctx, subscriptionID)
+}
+
+// RevenueSchedule mocks base method.
+func (m *MockRecognitionService) RevenueSchedule(ctx context.Context, subscriptionID uuid.UUID) ([]domain.RevenueEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevenueSchedule", ctx, subscriptionID)
+	ret0, _ := ret[0].([]domain.RevenueEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevenueSchedule indicates an expected call of RevenueSchedule.
+func (mr *MockRecognitionServiceMockRecorder) RevenueSchedule(ctx, subscriptionID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevenueSchedule", reflect.TypeOf((*MockRecognitionService)(nil).RevenueSchedule), ctx, subscriptionID)
+}
+
+// ScheduleRevenue mocks base method.
+func (m *MockRecognitionService) ScheduleRevenue(ctx context.Context, subs []domain.Subscription, bookedOn time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleRevenue", ctx, subs, bookedOn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleRevenue indicates an expected call of ScheduleRevenue.
+func (mr *MockRecognitionServiceMockRecorder) ScheduleRevenue(ctx, subs, bookedOn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleRevenue", reflect.TypeOf((*MockRecognitionService)(nil).ScheduleRevenue), ctx, subs, bookedOn)
+}
+
+// MockProductsService is a mock of ProductsService interface.
+type MockProductsService struct {
+	ctrl     *gomock.Controller
+	recorder *MockProductsServiceMockRecorder
+}
+
+// MockProductsServiceMockRecorder is the mock recorder for MockProductsService.
+type MockProductsServiceMockRecorder struct {
+	mock *MockProductsService
+}
+
+// NewMockProductsService creates a new mock instance.
+func NewMockProductsService(ctrl *gomock.Controller) *MockProductsService {
+	mock := &MockProductsService{ctrl: ctrl}
+	mock.recorder = &MockProductsServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProductsService) EXPECT() *MockProductsServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateProduct mocks base method.
+func (m *MockProductsService) CreateProduct(ctx context.Context, product domain.Product) (domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateProduct", ctx, product)
+	ret0, _ := ret[0].(domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateProduct indicates an expected call of CreateProduct.
+func (mr *MockProductsServiceMockRecorder) CreateProduct(ctx, product interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateProduct", reflect.TypeOf((*MockProductsService)(nil).CreateProduct), ctx, product)
+}
+
+// FetchAllProducts mocks base method.
+func (m *MockProductsService) FetchAllProducts(ctx context.Context) ([]domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchAllProducts", ctx)
+	ret0, _ := ret[0].([]domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchAllProducts indicates an expected call of FetchAllProducts.
+func (mr *MockProductsServiceMockRecorder) FetchAllProducts(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchAllProducts", reflect.TypeOf((*MockProductsService)(nil).FetchAllProducts), ctx)
+}
+
+// FetchProduct mocks base method.
+func (m *MockProductsService) FetchProduct(ctx context.Context, id uuid.UUID) (domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FetchProduct", ctx, id)
+	ret0, _ := ret[0].(domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FetchProduct indicates an expected call of FetchProduct.
+func (mr *MockProductsServiceMockRecorder) FetchProduct(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchProduct", reflect.TypeOf((*MockProductsService)(nil).FetchProduct), ctx, id)
+}
+
+// SearchProducts mocks base method.
+func (m *MockProductsService) SearchProducts(ctx context.Context, query domain.ProductQuery) ([]domain.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SearchProducts", ctx, query)
+	ret0, _ := ret[0].([]domain.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchProducts indicates an expected call of SearchProducts.
+func (mr *MockProductsServiceMockRecorder) SearchProducts(ctx, query interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchProducts", reflect.TypeOf((*MockProductsService)(nil).SearchProducts), ctx, query)
+}
+
+// MockAPIKeyService is a mock of APIKeyService interface.
+type MockAPIKeyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyServiceMockRecorder
+}
+
+// MockAPIKeyServiceMockRecorder is the mock recorder for MockAPIKeyService.
+type MockAPIKeyServiceMockRecorder struct {
+	mock *MockAPIKeyService
+}
+
+// NewMockAPIKeyService creates a new mock instance.
+func NewMockAPIKeyService(ctrl *gomock.Controller) *MockAPIKeyService {
+	mock := &MockAPIKeyService{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyService) EXPECT() *MockAPIKeyServiceMockRecorder {
+	return m.recorder
+}
+
+// AuthenticateAPIKey mocks base method.
+func (m *MockAPIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (domain.Principal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuthenticateAPIKey", ctx, key)
+	ret0, _ := ret[0].(domain.Principal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AuthenticateAPIKey indicates an expected call of AuthenticateAPIKey.
+func (mr *MockAPIKeyServiceMockRecorder) AuthenticateAPIKey(ctx, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthenticateAPIKey", reflect.TypeOf((*MockAPIKeyService)(nil).AuthenticateAPIKey), ctx, key)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockAPIKeyService) CreateAPIKey(ctx context.Context, name string, scopes []domain.Role, expiresAt *time.Time) (domain.NewAPIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, name, scopes, expiresAt)
+	ret0, _ := ret[0].(domain.NewAPIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockAPIKeyServiceMockRecorder) CreateAPIKey(ctx, name, scopes, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockAPIKeyService)(nil).CreateAPIKey), ctx, name, scopes, expiresAt)
+}
+
+// ListAPIKeys mocks base method.
+func (m *MockAPIKeyService) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAPIKeys", ctx)
+	ret0, _ := ret[0].([]domain.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAPIKeys indicates an expected call of ListAPIKeys.
+func (mr *MockAPIKeyServiceMockRecorder) ListAPIKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAPIKeys", reflect.TypeOf((*MockAPIKeyService)(nil).ListAPIKeys), ctx)
+}
+
+// RevokeAPIKey mocks base method.
+func (m *MockAPIKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeAPIKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeAPIKey indicates an expected call of RevokeAPIKey.
+func (mr *MockAPIKeyServiceMockRecorder) RevokeAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeAPIKey", reflect.TypeOf((*MockAPIKeyService)(nil).RevokeAPIKey), ctx, id)
 }
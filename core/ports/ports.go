@@ -4,6 +4,7 @@ package ports
 
 import (
 	"context"
+	"io"
 	"time"
 
 	"github.com/goakshit/isildur/core/domain"
@@ -14,18 +15,120 @@ import (
 type ProductsRepository interface {
 	// GetAll fetches all the products in the database.
 	GetAll(ctx context.Context) ([]domain.Product, error)
+	// Search fetches a page of the products matching the query.
+	Search(ctx context.Context, query domain.ProductQuery) ([]domain.Product, error)
 	// GetByID fetches product for a given id.
 	GetByID(ctx context.Context, id uuid.UUID) (domain.Product, error)
+	// Create is used to create a product in the db.
+	Create(ctx context.Context, product domain.Product) error
 }
 
 // SubscriptionsRepository describers database operations on subscriptions entity.
 type SubscriptionsRepository interface {
 	// Create is used to create a subscription in the db.
 	Create(ctx context.Context, sub domain.Subscription) error
+	// CreateBatch is used to create several subscriptions in the db at once.
+	CreateBatch(ctx context.Context, subs []domain.Subscription) error
 	// GetByID fetches subscription for a given id.
 	GetByID(ctx context.Context, id uuid.UUID) (domain.Subscription, error)
-	// Patch updates the data in subscription for a given id.
-	Patch(ctx context.Context, id uuid.UUID, update map[string]interface{}) error
+	// List fetches the subscriptions matching the filter, ordered by start date.
+	List(ctx context.Context, filter domain.SubscriptionFilter) ([]domain.Subscription, error)
+	// Stream calls fn for every subscription matching the filter, joined with its product and
+	// ordered by start date, without loading them all in memory. It stops at the first error.
+	Stream(ctx context.Context, filter domain.SubscriptionFilter, fn func(domain.SubscriptionExportRow) error) error
+	// Patch updates the data in subscription for a given id, if it is still at the
+	// expected version. The version is incremented on every successful patch.
+	Patch(ctx context.Context, id uuid.UUID, version int, update map[string]interface{}) error
+}
+
+// ReportsRepository describes the aggregates computed over the subscriptions.
+type ReportsRepository interface {
+	// Revenue sums the monthly amount, before tax, of the subscriptions active at asOf per product.
+	Revenue(ctx context.Context, asOf time.Time) ([]domain.ProductRevenue, error)
+	// ActiveCustomers counts the distinct customers with a subscription active at asOf.
+	ActiveCustomers(ctx context.Context, asOf time.Time) (int, error)
+	// Activity counts the subscriptions started, cancelled and paused in [from, to) per product.
+	// Products without any activity are left out.
+	Activity(ctx context.Context, from, to time.Time) ([]domain.ProductActivity, error)
+}
+
+// RevenueEntriesRepository describes database operations on revenue entries.
+type RevenueEntriesRepository interface {
+	// CreateBatch is used to create several revenue entries in the db at once.
+	CreateBatch(ctx context.Context, entries []domain.RevenueEntry) error
+	// ListBySubscription fetches the revenue entries of a subscription, ordered by month.
+	ListBySubscription(ctx context.Context, subscriptionID uuid.UUID) ([]domain.RevenueEntry, error)
+	// Update saves the status and the dates of the given revenue entries.
+	Update(ctx context.Context, entries []domain.RevenueEntry) error
+	// PeriodClose sums per product the revenue recognized and refunded in [from, to), and the
+	// revenue booked before to but still deferred at to.
+	PeriodClose(ctx context.Context, from, to time.Time) ([]domain.ProductRecognition, error)
+}
+
+// APIKeysRepository describes database operations on API keys.
+type APIKeysRepository interface {
+	// Create is used to create an API key in the db.
+	Create(ctx context.Context, key domain.APIKey) error
+	// GetByPrefix fetches the API key with a given prefix.
+	GetByPrefix(ctx context.Context, prefix string) (domain.APIKey, error)
+	// List fetches every API key, ordered by creation date.
+	List(ctx context.Context) ([]domain.APIKey, error)
+	// Revoke records when an API key got revoked, keeping the first date if already revoked.
+	Revoke(ctx context.Context, id uuid.UUID, at time.Time) error
+	// Touch records when an API key was last used.
+	Touch(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// RateLimiter describes the storage of the token buckets limiting the requests of the clients.
+type RateLimiter interface {
+	// Allow takes a token at now from the bucket of a key, created full for the limit if missing.
+	Allow(ctx context.Context, key string, limit domain.RateLimit, now time.Time) (domain.RateLimitResult, error)
+}
+
+// Cache describes a store of encoded values expiring at a given time, e.g. in front of a
+// repository.
+type Cache interface {
+	// Get returns the value of a key, unless it is missing or expired at now.
+	Get(ctx context.Context, key string, now time.Time) ([]byte, bool, error)
+	// Set stores the value of a key until expiresAt, replacing the previous one.
+	Set(ctx context.Context, key string, value []byte, expiresAt time.Time) error
+	// Delete removes the keys, missing or not.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// TxManager describes a unit of work spanning several repository calls.
+type TxManager interface {
+	// WithinTransaction runs fn inside a transaction carried in the context passed to fn.
+	// The transaction is committed when fn returns nil and rolled back otherwise.
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// TokenVerifier describes the verification of the bearer tokens sent by the clients.
+type TokenVerifier interface {
+	// Verify checks the signature and the claims of a token and returns its principal.
+	Verify(token string) (domain.Principal, error)
+}
+
+// Metrics describes the business metrics recorded by the services, once the changes are
+// committed.
+type Metrics interface {
+	// SubscriptionsCreated counts the subscriptions created for a product in a status.
+	SubscriptionsCreated(productID uuid.UUID, status domain.SubscriptionStatus, count int)
+	// SubscriptionStatusChanged counts a status transition of a subscription.
+	SubscriptionStatusChanged(from, to domain.SubscriptionStatus)
+	// CacheLookup counts a lookup of a cache tier, e.g. local or shared, hit or missed.
+	CacheLookup(cache, tier string, hit bool)
+}
+
+// Logger describes the leveled, structured logs. The fields are key-value pairs, and every entry
+// carries the request id of the context, if any.
+type Logger interface {
+	Debug(ctx context.Context, msg string, fields ...interface{})
+	Info(ctx context.Context, msg string, fields ...interface{})
+	Warn(ctx context.Context, msg string, fields ...interface{})
+	Error(ctx context.Context, msg string, fields ...interface{})
+	// With returns a logger adding the fields to every entry.
+	With(fields ...interface{}) Logger
 }
 
 // SubscriptionService describes main business functionality of subscription service.
@@ -34,14 +137,70 @@ type SubscriptionService interface {
 	CreateSubscription(ctx context.Context, pID uuid.UUID, durationInMonths int8, startDate time.Time) error
 	// FetchSubscription fetches subscription for a given ID.
 	FetchSubscription(ctx context.Context, id uuid.UUID) (domain.Subscription, error)
-	// UpdateSubscriptionStatus updates subscription for a given ID.
-	UpdateSubscriptionStatus(ctx context.Context, id uuid.UUID, status domain.SubscriptionStatus) error
+	// ListSubscriptions fetches the subscriptions matching the filter.
+	ListSubscriptions(ctx context.Context, filter domain.SubscriptionFilter) ([]domain.Subscription, error)
+	// ExportSubscriptions calls fn for every subscription matching the filter, joined with its product.
+	ExportSubscriptions(ctx context.Context, filter domain.SubscriptionFilter, fn func(domain.SubscriptionExportRow) error) error
+	// UpdateSubscriptionStatus updates subscription for a given ID, if it is still at the
+	// expected version.
+	UpdateSubscriptionStatus(ctx context.Context, id uuid.UUID, version int, status domain.SubscriptionStatus) error
+}
+
+// SubscriptionImporter describes the bulk import of subscriptions.
+type SubscriptionImporter interface {
+	// ImportSubscriptions validates and imports the subscription rows read from r.
+	ImportSubscriptions(ctx context.Context, r io.Reader, opts domain.ImportOptions) (domain.ImportReport, error)
+}
+
+// ReportsService describes the revenue and churn reporting.
+type ReportsService interface {
+	// RevenueReport computes the MRR, the ARR and the active subscribers at a given date.
+	RevenueReport(ctx context.Context, asOf time.Time) (domain.RevenueReport, error)
+	// ActivityReport computes the new, churned and paused subscriptions per period in [from, to).
+	ActivityReport(ctx context.Context, from, to time.Time, interval domain.ReportInterval) (domain.ActivityReport, error)
+}
+
+// RevenueRecognizer keeps the revenue recognition schedule of subscriptions up to date.
+// It is meant to be called inside the transaction creating or updating the subscriptions.
+type RevenueRecognizer interface {
+	// ScheduleRevenue books the monthly revenue entries of new subscriptions.
+	ScheduleRevenue(ctx context.Context, subs []domain.Subscription, bookedOn time.Time) error
+	// AdjustRevenue adjusts the revenue entries of a subscription, as it was before the
+	// update, to a status change at the given date.
+	AdjustRevenue(ctx context.Context, sub domain.Subscription, status domain.SubscriptionStatus, at time.Time) error
+}
+
+// RecognitionService describes the revenue recognition functionality.
+type RecognitionService interface {
+	RevenueRecognizer
+	// RevenueSchedule fetches the revenue entries of a subscription.
+	RevenueSchedule(ctx context.Context, subscriptionID uuid.UUID) ([]domain.RevenueEntry, error)
+	// RefundSubscription cancels a subscription and refunds its revenue not recognized yet.
+	RefundSubscription(ctx context.Context, subscriptionID uuid.UUID) (domain.Refund, error)
+	// PeriodCloseReport computes the revenue recognized, refunded and deferred per product.
+	PeriodCloseReport(ctx context.Context, from, to time.Time) (domain.PeriodCloseReport, error)
 }
 
 // ProductsService describes main business functionality of products.
 type ProductsService interface {
 	// FetchAllProduct fetches all the products in the database.
 	FetchAllProducts(ctx context.Context) ([]domain.Product, error)
+	// SearchProducts fetches a page of the products matching the query, see ParseProductQuery.
+	SearchProducts(ctx context.Context, query domain.ProductQuery) ([]domain.Product, error)
 	// FetchProduct fetches product for a given ID.
 	FetchProduct(ctx context.Context, id uuid.UUID) (domain.Product, error)
+	// CreateProduct creates a product and returns it with its new ID.
+	CreateProduct(ctx context.Context, product domain.Product) (domain.Product, error)
+}
+
+// APIKeyService describes the management of the API keys of the partners.
+type APIKeyService interface {
+	// CreateAPIKey creates an API key granting the scopes, returned once with the key itself.
+	CreateAPIKey(ctx context.Context, name string, scopes []domain.Role, expiresAt *time.Time) (domain.NewAPIKey, error)
+	// ListAPIKeys lists every API key, without the keys themselves.
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	// RevokeAPIKey revokes an API key, which can't authenticate requests anymore.
+	RevokeAPIKey(ctx context.Context, id uuid.UUID) error
+	// AuthenticateAPIKey checks an API key and returns the principal it authenticates.
+	AuthenticateAPIKey(ctx context.Context, key string) (domain.Principal, error)
 }
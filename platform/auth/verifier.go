@@ -0,0 +1,216 @@
+// Package auth verifies the JWT bearer tokens authenticating the API requests.
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// AlgorithmHS256 signs the tokens with a shared secret.
+	AlgorithmHS256 = "HS256"
+	// AlgorithmRS256 signs the tokens with a RSA private key.
+	AlgorithmRS256 = "RS256"
+)
+
+var _ ports.TokenVerifier = (*Verifier)(nil)
+
+// Verifier verifies the signature and the claims of JWTs.
+type Verifier struct {
+	algorithm string
+	// key verifies the tokens without a kid, keys the tokens with one.
+	key      interface{}
+	keys     map[string]interface{}
+	issuer   string
+	audience string
+}
+
+// tokenClaims are the claims read from the tokens, the roles of the caller being listed in a roles claim.
+type tokenClaims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+// NewVerifier creates a Verifier from the auth configuration, loading its keys.
+func NewVerifier(cfg config.AuthConfig) (*Verifier, error) {
+	v := &Verifier{
+		algorithm: cfg.Algorithm,
+		issuer:    cfg.Issuer,
+		audience:  cfg.Audience,
+	}
+	if cfg.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		v.keys = keys
+		return v, nil
+	}
+
+	switch cfg.Algorithm {
+	case AlgorithmHS256:
+		if cfg.Secret == "" {
+			return nil, errors.New("auth: HS256 requires a secret")
+		}
+		v.key = []byte(cfg.Secret)
+	case AlgorithmRS256:
+		if cfg.PublicKeyFile == "" {
+			return nil, errors.New("auth: RS256 requires a public key file")
+		}
+		pem, err := os.ReadFile(cfg.PublicKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("auth: failed to read public key: %w", err)
+		}
+		key, err := jwt.ParseRSAPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("auth: failed to parse public key: %w", err)
+		}
+		v.key = key
+	default:
+		return nil, fmt.Errorf("auth: unsupported algorithm %q", cfg.Algorithm)
+	}
+	return v, nil
+}
+
+// Verify checks the signature, expiry, issuer and audience of a token and returns the principal
+// named by its subject, with the roles of its claims. Unknown roles are ignored and tokens without
+// any role are customers. Any failure is reported as domain.ErrUnauthenticated.
+func (v *Verifier) Verify(token string) (domain.Principal, error) {
+	claims := tokenClaims{}
+	_, err := jwt.ParseWithClaims(token, &claims, v.keyFor, jwt.WithValidMethods(v.methods()))
+	if err != nil {
+		return domain.Principal{}, fmt.Errorf("%w: %v", domain.ErrUnauthenticated, err)
+	}
+	if claims.ExpiresAt == nil {
+		return domain.Principal{}, fmt.Errorf("%w: token has no expiry", domain.ErrUnauthenticated)
+	}
+	if claims.Subject == "" {
+		return domain.Principal{}, fmt.Errorf("%w: token has no subject", domain.ErrUnauthenticated)
+	}
+	if v.issuer != "" && !claims.VerifyIssuer(v.issuer, true) {
+		return domain.Principal{}, fmt.Errorf("%w: unexpected issuer", domain.ErrUnauthenticated)
+	}
+	if v.audience != "" && !claims.VerifyAudience(v.audience, true) {
+		return domain.Principal{}, fmt.Errorf("%w: unexpected audience", domain.ErrUnauthenticated)
+	}
+	return domain.Principal{Subject: claims.Subject, Roles: claims.roles()}, nil
+}
+
+// roles maps the roles claim to the known roles, defaulting to the customer role.
+func (c tokenClaims) roles() []domain.Role {
+	var roles []domain.Role
+	for _, r := range c.Roles {
+		if role := domain.MapStringToRole(r); role != "" {
+			roles = append(roles, role)
+		}
+	}
+	if len(roles) == 0 {
+		return []domain.Role{domain.RoleCustomer}
+	}
+	return roles
+}
+
+// methods returns the signing methods accepted, so that a token can't pick its own algorithm.
+func (v *Verifier) methods() []string {
+	if v.keys != nil {
+		return []string{AlgorithmHS256, AlgorithmRS256}
+	}
+	return []string{v.algorithm}
+}
+
+// keyFor returns the key verifying a token, picked by its kid with a key set.
+func (v *Verifier) keyFor(token *jwt.Token) (interface{}, error) {
+	if v.keys == nil {
+		return v.key, nil
+	}
+	kid, _ := token.Header["kid"].(string)
+	key, ok := v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	// The key type must match the algorithm, an RSA public key is no HMAC secret
+	switch key.(type) {
+	case []byte:
+		if token.Method.Alg() != AlgorithmHS256 {
+			return nil, fmt.Errorf("key %q can't verify %s", kid, token.Method.Alg())
+		}
+	case *rsa.PublicKey:
+		if token.Method.Alg() != AlgorithmRS256 {
+			return nil, fmt.Errorf("key %q can't verify %s", kid, token.Method.Alg())
+		}
+	}
+	return key, nil
+}
+
+// jwk is a JSON Web Key, only the RSA and symmetric key types are supported.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	K   string `json:"k"`
+}
+
+// loadJWKS reads the keys of a JSON Web Key Set file by kid.
+func loadJWKS(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth: failed to read key set: %w", err)
+	}
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("auth: failed to parse key set: %w", err)
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("auth: key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("auth: key set has no keys")
+	}
+	return keys, nil
+}
+
+// publicKey decodes the key verifying the signatures.
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent: %w", err)
+		}
+		return &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}, nil
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secret: %w", err)
+		}
+		return secret, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+	}
+}
@@ -0,0 +1,308 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/suite"
+)
+
+const testSecret = "test-secret"
+
+type VerifierTestSuite struct {
+	suite.Suite
+	rsaKey *rsa.PrivateKey
+	dir    string
+}
+
+func TestVerifierTestSuite(t *testing.T) {
+	suite.Run(t, new(VerifierTestSuite))
+}
+
+func (ts *VerifierTestSuite) SetupSuite() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	ts.Require().Nil(err)
+	ts.rsaKey = key
+}
+
+func (ts *VerifierTestSuite) SetupTest() {
+	ts.dir = ts.T().TempDir()
+}
+
+// claims returns valid claims for customer-1, expiring in an hour.
+func claims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "customer-1",
+		Issuer:    "isildur-test",
+		Audience:  jwt.ClaimStrings{"isildur"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+// sign mints a token, with a kid header when kid isn't empty.
+func (ts *VerifierTestSuite) sign(method jwt.SigningMethod, key interface{}, kid string, c jwt.Claims) string {
+	token := jwt.NewWithClaims(method, c)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	ts.Require().Nil(err)
+	return signed
+}
+
+func (ts *VerifierTestSuite) writeFile(name string, data []byte) string {
+	path := filepath.Join(ts.dir, name)
+	ts.Require().Nil(os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func (ts *VerifierTestSuite) TestVerify_HS256() {
+	v, err := NewVerifier(config.AuthConfig{
+		Algorithm: AlgorithmHS256,
+		Secret:    testSecret,
+		Issuer:    "isildur-test",
+		Audience:  "isildur",
+	})
+	ts.Require().Nil(err)
+
+	expired := claims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+	noExpiry := claims()
+	noExpiry.ExpiresAt = nil
+	noSubject := claims()
+	noSubject.Subject = ""
+	otherIssuer := claims()
+	otherIssuer.Issuer = "someone-else"
+	otherAudience := claims()
+	otherAudience.Audience = jwt.ClaimStrings{"billing"}
+
+	tt := []struct {
+		name      string
+		token     string
+		expectErr bool
+	}{
+		{
+			name:  "Valid token",
+			token: ts.sign(jwt.SigningMethodHS256, []byte(testSecret), "", claims()),
+		},
+		{
+			name:      "Wrong secret",
+			token:     ts.sign(jwt.SigningMethodHS256, []byte("other-secret"), "", claims()),
+			expectErr: true,
+		},
+		{
+			name:      "Unexpected algorithm",
+			token:     ts.sign(jwt.SigningMethodHS512, []byte(testSecret), "", claims()),
+			expectErr: true,
+		},
+		{
+			name:      "Unsigned token",
+			token:     ts.sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, "", claims()),
+			expectErr: true,
+		},
+		{
+			name:      "Expired token",
+			token:     ts.sign(jwt.SigningMethodHS256, []byte(testSecret), "", expired),
+			expectErr: true,
+		},
+		{
+			name:      "Token without expiry",
+			token:     ts.sign(jwt.SigningMethodHS256, []byte(testSecret), "", noExpiry),
+			expectErr: true,
+		},
+		{
+			name:      "Token without subject",
+			token:     ts.sign(jwt.SigningMethodHS256, []byte(testSecret), "", noSubject),
+			expectErr: true,
+		},
+		{
+			name:      "Unexpected issuer",
+			token:     ts.sign(jwt.SigningMethodHS256, []byte(testSecret), "", otherIssuer),
+			expectErr: true,
+		},
+		{
+			name:      "Unexpected audience",
+			token:     ts.sign(jwt.SigningMethodHS256, []byte(testSecret), "", otherAudience),
+			expectErr: true,
+		},
+		{
+			name:      "Malformed token",
+			token:     "not-a-token",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			principal, err := v.Verify(tc.token)
+			if tc.expectErr {
+				ts.Assert().ErrorIs(err, domain.ErrUnauthenticated)
+				return
+			}
+			ts.Require().Nil(err)
+			ts.Assert().Equal(domain.Principal{Subject: "customer-1", Roles: []domain.Role{domain.RoleCustomer}}, principal)
+		})
+	}
+}
+
+func (ts *VerifierTestSuite) TestVerify_Roles() {
+	v, err := NewVerifier(config.AuthConfig{Algorithm: AlgorithmHS256, Secret: testSecret})
+	ts.Require().Nil(err)
+
+	tt := []struct {
+		name          string
+		roles         []string
+		expectedRoles []domain.Role
+	}{
+		{
+			name:          "No roles claim",
+			expectedRoles: []domain.Role{domain.RoleCustomer},
+		},
+		{
+			name:          "Staff roles",
+			roles:         []string{"support", "admin"},
+			expectedRoles: []domain.Role{domain.RoleSupport, domain.RoleAdmin},
+		},
+		{
+			name:          "Unknown roles are ignored",
+			roles:         []string{"superuser", "admin"},
+			expectedRoles: []domain.Role{domain.RoleAdmin},
+		},
+		{
+			name:          "Only unknown roles",
+			roles:         []string{"superuser"},
+			expectedRoles: []domain.Role{domain.RoleCustomer},
+		},
+	}
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			token := ts.sign(jwt.SigningMethodHS256, []byte(testSecret), "", tokenClaims{
+				RegisteredClaims: claims(),
+				Roles:            tc.roles,
+			})
+			principal, err := v.Verify(token)
+			ts.Require().Nil(err)
+			ts.Assert().Equal(tc.expectedRoles, principal.Roles)
+		})
+	}
+}
+
+func (ts *VerifierTestSuite) TestVerify_RS256() {
+	der, err := x509.MarshalPKIXPublicKey(&ts.rsaKey.PublicKey)
+	ts.Require().Nil(err)
+	path := ts.writeFile("public.pem", pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+
+	v, err := NewVerifier(config.AuthConfig{
+		Algorithm:     AlgorithmRS256,
+		PublicKeyFile: path,
+	})
+	ts.Require().Nil(err)
+
+	principal, err := v.Verify(ts.sign(jwt.SigningMethodRS256, ts.rsaKey, "", claims()))
+	ts.Require().Nil(err)
+	ts.Assert().Equal("customer-1", principal.Subject)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	ts.Require().Nil(err)
+	_, err = v.Verify(ts.sign(jwt.SigningMethodRS256, otherKey, "", claims()))
+	ts.Assert().ErrorIs(err, domain.ErrUnauthenticated)
+
+	// An HS256 token signed with the public key as secret must not pass
+	_, err = v.Verify(ts.sign(jwt.SigningMethodHS256, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), "", claims()))
+	ts.Assert().ErrorIs(err, domain.ErrUnauthenticated)
+}
+
+func (ts *VerifierTestSuite) TestVerify_JWKS() {
+	set := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa-1",
+				"n":   base64.RawURLEncoding.EncodeToString(ts.rsaKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(ts.rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "oct",
+				"kid": "hmac-1",
+				"k":   base64.RawURLEncoding.EncodeToString([]byte(testSecret)),
+			},
+		},
+	}
+	data, err := json.Marshal(set)
+	ts.Require().Nil(err)
+	v, err := NewVerifier(config.AuthConfig{JWKSFile: ts.writeFile("jwks.json", data)})
+	ts.Require().Nil(err)
+
+	tt := []struct {
+		name      string
+		token     string
+		expectErr bool
+	}{
+		{
+			name:  "RSA key",
+			token: ts.sign(jwt.SigningMethodRS256, ts.rsaKey, "rsa-1", claims()),
+		},
+		{
+			name:  "Symmetric key",
+			token: ts.sign(jwt.SigningMethodHS256, []byte(testSecret), "hmac-1", claims()),
+		},
+		{
+			name:      "Unknown kid",
+			token:     ts.sign(jwt.SigningMethodRS256, ts.rsaKey, "rsa-2", claims()),
+			expectErr: true,
+		},
+		{
+			name:      "Missing kid",
+			token:     ts.sign(jwt.SigningMethodRS256, ts.rsaKey, "", claims()),
+			expectErr: true,
+		},
+		{
+			name:      "Algorithm not matching the key",
+			token:     ts.sign(jwt.SigningMethodHS256, []byte(testSecret), "rsa-1", claims()),
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			principal, err := v.Verify(tc.token)
+			if tc.expectErr {
+				ts.Assert().ErrorIs(err, domain.ErrUnauthenticated)
+				return
+			}
+			ts.Require().Nil(err)
+			ts.Assert().Equal("customer-1", principal.Subject)
+		})
+	}
+}
+
+func (ts *VerifierTestSuite) TestNewVerifier_InvalidConfig() {
+	tt := []struct {
+		name string
+		cfg  config.AuthConfig
+	}{
+		{name: "HS256 without secret", cfg: config.AuthConfig{Algorithm: AlgorithmHS256}},
+		{name: "RS256 without key", cfg: config.AuthConfig{Algorithm: AlgorithmRS256}},
+		{name: "Missing key file", cfg: config.AuthConfig{Algorithm: AlgorithmRS256, PublicKeyFile: filepath.Join(ts.dir, "missing.pem")}},
+		{name: "Unsupported algorithm", cfg: config.AuthConfig{Algorithm: "ES256", Secret: testSecret}},
+		{name: "Empty key set", cfg: config.AuthConfig{JWKSFile: ts.writeFile("empty.json", []byte(`{"keys":[]}`))}},
+	}
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			_, err := NewVerifier(tc.cfg)
+			ts.Assert().NotNil(err)
+		})
+	}
+}
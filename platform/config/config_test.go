@@ -0,0 +1,298 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+)
+
+type ConfigTestSuite struct {
+	suite.Suite
+	dir string
+}
+
+func TestConfigTestSuite(t *testing.T) {
+	suite.Run(t, new(ConfigTestSuite))
+}
+
+func (ts *ConfigTestSuite) SetupTest() {
+	ts.dir = ts.T().TempDir()
+	// The database credentials have no default
+	ts.T().Setenv("DB_USER", "isildur")
+}
+
+// write writes a file in the temp dir of the test and returns its path.
+func (ts *ConfigTestSuite) write(name, content string) string {
+	path := filepath.Join(ts.dir, name)
+	ts.Require().Nil(os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func (ts *ConfigTestSuite) TestDefaults() {
+	cfg, args, err := Load(nil)
+	ts.Require().Nil(err)
+	ts.Assert().Empty(args)
+
+	expected := Default()
+	expected.DB.User = "isildur"
+	ts.Assert().Equal(expected, cfg)
+}
+
+func (ts *ConfigTestSuite) TestLayers() {
+	path := ts.write("isildur.yaml", `
+service_port: 8000
+shutdown_timeout: 5s
+db:
+  host: file-host
+  port: 6000
+  name: file-name
+tracing:
+  sample_ratio: 0.5
+`)
+	ts.T().Setenv("DB_HOST", "env-host")
+	ts.T().Setenv("DB_PORT", "7000")
+
+	cfg, args, err := Load([]string{"--config", path, "--db.port", "8000", "--auth.disabled", "subscriptions", "--status", "active"})
+	ts.Require().Nil(err)
+	ts.Assert().Equal([]string{"subscriptions", "--status", "active"}, args)
+	// The file overrides the defaults
+	ts.Assert().Equal(8000, cfg.ServicePort)
+	ts.Assert().Equal(5*time.Second, cfg.ShutdownTimeout)
+	ts.Assert().Equal("file-name", cfg.DB.Name)
+	ts.Assert().Equal(0.5, cfg.Tracing.SampleRatio)
+	// The env overrides the file
+	ts.Assert().Equal("env-host", cfg.DB.Host)
+	// The flags override the env
+	ts.Assert().Equal(8000, cfg.DB.Port)
+	ts.Assert().True(cfg.Auth.Disabled)
+	// Untouched settings keep their default
+	ts.Assert().Equal(9090, cfg.GRPCPort)
+}
+
+func (ts *ConfigTestSuite) TestFileFromEnv() {
+	ts.T().Setenv("CONFIG_FILE", ts.write("isildur.yaml", "storage: memory\n"))
+
+	cfg, _, err := Load(nil)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(StorageMemory, cfg.Storage)
+}
+
+func (ts *ConfigTestSuite) TestStoragePostgresAlias() {
+	ts.T().Setenv("STORAGE", "postgres")
+
+	cfg, _, err := Load(nil)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(StorageDatabase, cfg.Storage)
+}
+
+func (ts *ConfigTestSuite) TestSecretFiles() {
+	ts.T().Setenv("DB_PASS_FILE", ts.write("db_pass", "s3cr3t\n"))
+
+	cfg, _, err := Load(nil)
+	ts.Require().Nil(err)
+	ts.Assert().Equal("s3cr3t", cfg.DB.Pass)
+
+	ts.T().Setenv("DB_PASS", "other")
+	_, _, err = Load(nil)
+	ts.Assert().EqualError(err, "env DB_PASS and DB_PASS_FILE are both set")
+
+	os.Unsetenv("DB_PASS")
+	ts.T().Setenv("DB_PASS_FILE", filepath.Join(ts.dir, "missing"))
+	_, _, err = Load(nil)
+	ts.Require().NotNil(err)
+	ts.Assert().Contains(err.Error(), "env DB_PASS_FILE: open")
+}
+
+func (ts *ConfigTestSuite) TestInvalidValues() {
+	tt := []struct {
+		name        string
+		args        []string
+		env         map[string]string
+		file        string
+		expectedErr string
+	}{
+		{
+			name:        "Env",
+			env:         map[string]string{"SHUTDOWN_TIMEOUT": "30"},
+			expectedErr: `env SHUTDOWN_TIMEOUT: invalid duration "30"`,
+		},
+		{
+			name:        "Flag",
+			args:        []string{"--metrics_enabled=maybe"},
+			expectedErr: `flag --metrics_enabled: invalid boolean "maybe"`,
+		},
+		{
+			name:        "UnknownFlag",
+			args:        []string{"--db.hots", "localhost"},
+			expectedErr: "flag provided but not defined: -db.hots",
+		},
+		{
+			name:        "UnknownKey",
+			file:        "db:\n  hots: localhost\n",
+			expectedErr: "field hots not found in type config.DBConfig",
+		},
+	}
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			for key, value := range tc.env {
+				ts.T().Setenv(key, value)
+			}
+			args := tc.args
+			if tc.file != "" {
+				args = append([]string{"--config", ts.write("isildur.yaml", tc.file)}, args...)
+			}
+			_, _, err := Load(args)
+			ts.Require().NotNil(err)
+			ts.Assert().Contains(err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func (ts *ConfigTestSuite) TestValidate() {
+	cert := ts.write("cert.pem", "")
+	tt := []struct {
+		name             string
+		update           func(cfg *CFG)
+		expectedProblems []string
+	}{
+		{
+			name:   "Valid",
+			update: func(cfg *CFG) {},
+		},
+		{
+			name: "Service",
+			update: func(cfg *CFG) {
+				cfg.ServiceLevel = "verbose"
+				cfg.GRPCPort = cfg.ServicePort
+				cfg.ShutdownTimeout = 0
+				cfg.ShutdownDrainDelay = -time.Second
+			},
+			expectedProblems: []string{
+				`service_level: "verbose" is not one of debug, release, test`,
+				"grpc_port: must differ from service_port 8080",
+				"shutdown_timeout: must be positive",
+				"shutdown_drain_delay: must not be negative",
+			},
+		},
+		{
+			name: "Postgres",
+			update: func(cfg *CFG) {
+				cfg.DB.User = ""
+				cfg.DB.Port = 70000
+				cfg.DB.SSLMode = "always"
+				cfg.DB.SSLRootCert = filepath.Join(ts.dir, "ca.pem")
+			},
+			expectedProblems: []string{
+				"db.port: 70000 is not a valid port",
+				"db.user: is required",
+				`db.ssl_mode: "always" is not one of disable, allow, prefer, require, verify-ca, verify-full`,
+				"db.ssl_root_cert: stat " + filepath.Join(ts.dir, "ca.pem") + ": no such file or directory",
+			},
+		},
+		{
+			name: "Pool",
+			update: func(cfg *CFG) {
+				cfg.DB.MaxOpenConns = 10
+				cfg.DB.MaxIdleConns = 20
+				cfg.DB.ConnMaxLifetime = -time.Second
+			},
+			expectedProblems: []string{
+				"db.max_idle_conns: must not exceed db.max_open_conns 10",
+				"db.conn_max_lifetime: must not be negative",
+			},
+		},
+		{
+			name: "MemoryStorage",
+			update: func(cfg *CFG) {
+				cfg.Storage = StorageMemory
+				cfg.DB.User = ""
+				cfg.RateLimit.Backend = RateLimitDatabase
+				cfg.Cache.Shared = CacheSharedDatabase
+			},
+			expectedProblems: []string{
+				"rate_limit.backend: database requires the database storage",
+				"cache.shared: database requires the database storage",
+			},
+		},
+		{
+			name: "TrustedProxies",
+			update: func(cfg *CFG) {
+				cfg.TrustedProxies = "10.0.0.0/8, 192.0.2.1,proxy.internal"
+			},
+			expectedProblems: []string{`trusted_proxies: "proxy.internal" is not an IP or a CIDR`},
+		},
+		{
+			name: "TLS",
+			update: func(cfg *CFG) {
+				cfg.TLS.CertFile = cert
+			},
+			expectedProblems: []string{"tls: cert_file and key_file must be set together"},
+		},
+		{
+			name: "Cache",
+			update: func(cfg *CFG) {
+				cfg.Cache.Size = 0
+				cfg.Cache.Shared = "redis"
+			},
+			expectedProblems: []string{
+				"cache.size: must be positive",
+				`cache.shared: "redis" is not one of none, database`,
+			},
+		},
+		{
+			name: "Tracing",
+			update: func(cfg *CFG) {
+				cfg.Tracing.Exporter = TracingOTLP
+				cfg.Tracing.Endpoint = ""
+				cfg.Tracing.SampleRatio = 1.5
+			},
+			expectedProblems: []string{
+				"tracing.endpoint: is required",
+				"tracing.sample_ratio: must be between 0 and 1",
+			},
+		},
+	}
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			cfg := Default()
+			cfg.DB.User = "isildur"
+			tc.update(cfg)
+
+			err := cfg.Validate()
+			if tc.expectedProblems == nil {
+				ts.Assert().Nil(err)
+				return
+			}
+			ts.Require().IsType(ValidationError{}, err)
+			ts.Assert().Equal(tc.expectedProblems, err.(ValidationError).Problems)
+		})
+	}
+}
+
+func (ts *ConfigTestSuite) TestPrint() {
+	ts.T().Setenv("DB_PASS", "s3cr3t")
+	ts.T().Setenv("AUTH_JWT_SECRET", "jwt-s3cr3t")
+	cfg, _, err := Load([]string{"--shutdown_timeout", "1m30s"})
+	ts.Require().Nil(err)
+
+	buf := &bytes.Buffer{}
+	ts.Require().Nil(cfg.Print(buf))
+	printed := buf.String()
+	ts.Assert().NotContains(printed, "s3cr3t")
+	ts.Assert().Contains(printed, "  pass: '[redacted]'\n")
+	ts.Assert().Contains(printed, "shutdown_timeout: 1m30s\n")
+	// Unset settings are printed empty
+	ts.Assert().Contains(printed, "  issuer: \"\"\n")
+
+	// The printed config can be loaded back
+	os.Unsetenv("DB_PASS")
+	os.Unsetenv("AUTH_JWT_SECRET")
+	loaded, _, err := Load([]string{"--config", ts.write("printed.yaml", printed)})
+	ts.Require().Nil(err)
+	cfg.DB.Pass, cfg.Auth.Secret = redacted, redacted
+	ts.Assert().Equal(cfg, loaded)
+}
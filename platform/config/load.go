@@ -0,0 +1,201 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileEnv names the YAML file to load, unless the --config flag is passed.
+const fileEnv = "CONFIG_FILE"
+
+// Load loads the configuration from, by increasing precedence, the defaults, the YAML file passed
+// with --config or CONFIG_FILE, the env vars and the flags in args, then validates it. The flags
+// are parsed up to the first argument that isn't one, e.g. the command, the remaining arguments
+// being returned.
+//
+// Every env var can be read from a file instead, e.g. a mounted secret, named by the var with a
+// _FILE suffix: DB_PASS_FILE=/run/secrets/db_pass.
+func Load(args []string) (*CFG, []string, error) {
+	cfg := Default()
+	settings := settingsOf(cfg)
+
+	fs := flag.NewFlagSet("isildur", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	path := fs.String("config", "", "YAML file to load")
+	flags := map[string]string{}
+	for _, s := range settings {
+		fs.Var(&flagValue{name: s.path, set: flags, isBool: s.value.Kind() == reflect.Bool}, s.path, s.env)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *path == "" {
+		*path = os.Getenv(fileEnv)
+	}
+	if *path != "" {
+		if err := loadFile(cfg, *path); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, s := range settings {
+		value, ok, err := lookupEnv(s.env)
+		if err != nil {
+			return nil, nil, err
+		}
+		if ok {
+			if err := s.set(value); err != nil {
+				return nil, nil, fmt.Errorf("env %s: %w", s.env, err)
+			}
+		}
+	}
+	for _, s := range settings {
+		if value, ok := flags[s.path]; ok {
+			if err := s.set(value); err != nil {
+				return nil, nil, fmt.Errorf("flag --%s: %w", s.path, err)
+			}
+		}
+	}
+	if cfg.Storage == StoragePostgres {
+		cfg.Storage = StorageDatabase
+	}
+	return cfg, fs.Args(), cfg.Validate()
+}
+
+// loadFile overrides the configuration with the settings of a YAML file. Unknown keys are
+// rejected, not to ignore a misspelt setting.
+func loadFile(cfg *CFG, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("config file: %w", err)
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// lookupEnv returns the value of an env var, read from the file named by key_FILE if set.
+func lookupEnv(key string) (string, bool, error) {
+	value, ok := os.LookupEnv(key)
+	path, fromFile := os.LookupEnv(key + "_FILE")
+	if !fromFile {
+		return value, ok, nil
+	}
+	if ok {
+		return "", false, fmt.Errorf("env %s and %s_FILE are both set", key, key)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false, fmt.Errorf("env %s_FILE: %w", key, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// setting is a leaf of the configuration.
+type setting struct {
+	// path is the dotted yaml path of the setting, e.g. db.host, also naming its flag.
+	path   string
+	env    string
+	secret bool
+	value  reflect.Value
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// settingsOf lists the settings of the configuration, in their declaration order.
+func settingsOf(cfg *CFG) []setting {
+	var settings []setting
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			path := prefix + f.Tag.Get("yaml")
+			if f.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), path+".")
+				continue
+			}
+			settings = append(settings, setting{
+				path:   path,
+				env:    f.Tag.Get("env"),
+				secret: f.Tag.Get("secret") == "true",
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return settings
+}
+
+// set parses a value into the setting, according to its type.
+func (s setting) set(value string) error {
+	if s.value.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		s.value.SetInt(int64(d))
+		return nil
+	}
+	switch s.value.Kind() {
+	case reflect.String:
+		s.value.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		s.value.SetBool(b)
+	case reflect.Int:
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		s.value.SetInt(int64(i))
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		s.value.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", s.value.Type())
+	}
+	return nil
+}
+
+// flagValue records the value of a flag, applied once the file and the env vars are loaded.
+type flagValue struct {
+	name   string
+	set    map[string]string
+	isBool bool
+}
+
+func (f *flagValue) String() string {
+	if f == nil || f.set == nil {
+		return ""
+	}
+	return f.set[f.name]
+}
+
+func (f *flagValue) Set(value string) error {
+	f.set[f.name] = value
+	return nil
+}
+
+// IsBoolFlag allows passing the boolean flags without their value, e.g. --auth.disabled.
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
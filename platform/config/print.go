@@ -0,0 +1,57 @@
+package config
+
+import (
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces the secrets set in the printed configuration.
+const redacted = "[redacted]"
+
+// Print writes the configuration as YAML, in the format of the configuration file, with the
+// secrets redacted.
+func (c *CFG) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node(reflect.ValueOf(c).Elem(), false)); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// node returns the YAML node of a value, keeping the order of the struct fields.
+func node(v reflect.Value, secret bool) *yaml.Node {
+	if v.Kind() == reflect.Struct {
+		n := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			n.Content = append(n.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: f.Tag.Get("yaml")},
+				node(v.Field(i), f.Tag.Get("secret") == "true"),
+			)
+		}
+		return n
+	}
+
+	scalar := &yaml.Node{Kind: yaml.ScalarNode}
+	switch {
+	case v.Type() == durationType:
+		scalar.Tag, scalar.Value = "!!str", time.Duration(v.Int()).String()
+	case v.Kind() == reflect.String:
+		scalar.Tag, scalar.Value = "!!str", v.String()
+		if secret && scalar.Value != "" {
+			scalar.Value = redacted
+		}
+	case v.Kind() == reflect.Bool:
+		scalar.Value = strconv.FormatBool(v.Bool())
+	case v.Kind() == reflect.Int:
+		scalar.Value = strconv.FormatInt(v.Int(), 10)
+	case v.Kind() == reflect.Float64:
+		scalar.Value = strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	}
+	return scalar
+}
@@ -0,0 +1,141 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// ValidationError lists every invalid setting of a configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e ValidationError) Error() string {
+	return "invalid config:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks the configuration, reporting every invalid setting at once, named after its
+// yaml path.
+func (c *CFG) Validate() error {
+	v := &validator{}
+
+	v.oneOf("service_level", c.ServiceLevel, "debug", "release", "test")
+	v.port("service_port", c.ServicePort)
+	if c.GRPCPort != 0 {
+		v.port("grpc_port", c.GRPCPort)
+		v.check(c.GRPCPort != c.ServicePort, "grpc_port: must differ from service_port %d", c.ServicePort)
+	}
+	v.check(c.ShutdownTimeout > 0, "shutdown_timeout: must be positive")
+	v.check(c.ShutdownDrainDelay >= 0, "shutdown_drain_delay: must not be negative")
+	v.oneOf("storage", c.Storage, StorageDatabase, StorageMemory)
+
+	for _, proxy := range c.TrustedProxyList() {
+		_, _, cidrErr := net.ParseCIDR(proxy)
+		v.check(cidrErr == nil || net.ParseIP(proxy) != nil, "trusted_proxies: %q is not an IP or a CIDR", proxy)
+	}
+
+	v.check((c.TLS.CertFile == "") == (c.TLS.KeyFile == ""), "tls: cert_file and key_file must be set together")
+	v.file("tls.cert_file", c.TLS.CertFile)
+	v.file("tls.key_file", c.TLS.KeyFile)
+
+	if c.Storage == StorageDatabase {
+		v.oneOf("db.driver", c.DB.Driver, DriverPostgres, DriverSQLite)
+		switch c.DB.Driver {
+		case DriverPostgres:
+			v.required("db.host", c.DB.Host)
+			v.port("db.port", c.DB.Port)
+			v.required("db.user", c.DB.User)
+			v.required("db.name", c.DB.Name)
+			if c.DB.SSLMode != "" {
+				v.oneOf("db.ssl_mode", c.DB.SSLMode,
+					"disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+			}
+			v.file("db.ssl_root_cert", c.DB.SSLRootCert)
+		case DriverSQLite:
+			v.required("db.path", c.DB.Path)
+		}
+		v.check(c.DB.ConnectTimeout > 0, "db.connect_timeout: must be positive")
+		v.check(c.DB.MaxOpenConns >= 0, "db.max_open_conns: must not be negative")
+		v.check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns: must not be negative")
+		v.check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns,
+			"db.max_idle_conns: must not exceed db.max_open_conns %d", c.DB.MaxOpenConns)
+		v.check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime: must not be negative")
+		v.check(c.DB.ConnMaxIdleTime >= 0, "db.conn_max_idle_time: must not be negative")
+	}
+
+	if !c.Auth.Disabled {
+		v.oneOf("auth.algorithm", c.Auth.Algorithm, "HS256", "RS256")
+		v.file("auth.public_key_file", c.Auth.PublicKeyFile)
+		v.file("auth.jwks_file", c.Auth.JWKSFile)
+	}
+
+	if c.RateLimit.Enabled {
+		v.oneOf("rate_limit.backend", c.RateLimit.Backend, RateLimitMemory, RateLimitDatabase)
+		v.check(c.RateLimit.Backend != RateLimitDatabase || c.Storage == StorageDatabase,
+			"rate_limit.backend: database requires the database storage")
+	}
+
+	v.oneOf("tracing.exporter", c.Tracing.Exporter, TracingNone, TracingOTLP, TracingStdout)
+	if c.Tracing.Exporter == TracingOTLP {
+		v.required("tracing.endpoint", c.Tracing.Endpoint)
+	}
+	v.check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio: must be between 0 and 1")
+
+	if c.Cache.Enabled {
+		v.check(c.Cache.Size > 0, "cache.size: must be positive")
+		v.check(c.Cache.TTL > 0, "cache.ttl: must be positive")
+		v.oneOf("cache.shared", c.Cache.Shared, CacheSharedNone, CacheSharedDatabase)
+		if c.Cache.Shared == CacheSharedDatabase {
+			v.check(c.Storage == StorageDatabase, "cache.shared: database requires the database storage")
+			v.check(c.Cache.SharedTTL > 0, "cache.shared_ttl: must be positive")
+		}
+	}
+
+	if len(v.problems) > 0 {
+		return ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+// validator collects the problems of a configuration.
+type validator struct {
+	problems []string
+}
+
+func (v *validator) check(ok bool, format string, args ...interface{}) {
+	if !ok {
+		v.problems = append(v.problems, fmt.Sprintf(format, args...))
+	}
+}
+
+func (v *validator) required(path, value string) {
+	v.check(value != "", "%s: is required", path)
+}
+
+func (v *validator) port(path string, port int) {
+	v.check(port > 0 && port <= 65535, "%s: %d is not a valid port", path, port)
+}
+
+func (v *validator) oneOf(path, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	v.check(false, "%s: %q is not one of %s", path, value, strings.Join(allowed, ", "))
+}
+
+// file checks that a file setting, if set, names a readable file.
+func (v *validator) file(path, name string) {
+	if name == "" {
+		return
+	}
+	info, err := os.Stat(name)
+	if err != nil {
+		v.check(false, "%s: %v", path, err)
+		return
+	}
+	v.check(!info.IsDir(), "%s: %s is a directory", path, name)
+}
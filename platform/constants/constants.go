@@ -13,4 +13,13 @@ const (
 
 	// SubscriptionIDKey represents key used for subscriptionID.
 	SubscriptionIDKey string = "subscription-id"
+
+	// APIKeyIDKey represents key used for apiKeyID.
+	APIKeyIDKey string = "api-key-id"
+
+	// DefaultPageSize is the number of entities listed when no limit is passed.
+	DefaultPageSize int = 50
+
+	// MaxPageSize is the maximum number of entities that can be listed at once.
+	MaxPageSize int = 1000
 )
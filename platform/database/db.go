@@ -1,20 +1,27 @@
 package database
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
 
+	"github.com/glebarez/sqlite"
+	"github.com/goakshit/isildur/core/ports"
 	"github.com/goakshit/isildur/platform/config"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/schema"
+	"gorm.io/plugin/dbresolver"
 )
 
-// Singleton
-var db *gorm.DB
-
-func getGORMConfig() *gorm.Config {
+func getGORMConfig(log ports.Logger) *gorm.Config {
 	return &gorm.Config{
 		SkipDefaultTransaction: true,
+		Logger:                 NewGormLogger(log),
 		NamingStrategy: schema.NamingStrategy{
 			// Doesn't pluralize the table names
 			// Eg: 'user' table won't be pluralized to 'users' table
@@ -23,18 +30,131 @@ func getGORMConfig() *gorm.Config {
 	}
 }
 
-// Return postgres connection string
+// Return postgres connection string, escaping the credentials
 func getPostgresConnString(cfg *config.CFG) string {
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s",
-		cfg.DB.User, cfg.DB.Pass, cfg.DB.Host, cfg.DB.Port, cfg.DB.Name)
+	query := url.Values{}
+	if cfg.DB.SSLMode != "" {
+		query.Set("sslmode", cfg.DB.SSLMode)
+	}
+	if cfg.DB.SSLRootCert != "" {
+		query.Set("sslrootcert", cfg.DB.SSLRootCert)
+	}
+	u := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(cfg.DB.User, cfg.DB.Pass),
+		Host:     net.JoinHostPort(cfg.DB.Host, strconv.Itoa(cfg.DB.Port)),
+		Path:     "/" + cfg.DB.Name,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// Return sqlite connection string, waiting on locks instead of failing right away
+func getSQLiteConnString(cfg *config.CFG) string {
+	return fmt.Sprintf("%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", cfg.DB.Path)
 }
 
-// GetGormClient - Returns db client
-func GetGormClient(cfg *config.CFG) *gorm.DB {
-	var err error
-	db, err = gorm.Open(postgres.Open(getPostgresConnString(cfg)), getGORMConfig())
+// GetGormClient - Returns db client for the configured driver, logging the queries to log.
+// Panics if the database can't be opened, see Connect to wait for the database instead
+func GetGormClient(cfg *config.CFG, log ports.Logger) *gorm.DB {
+	db, err := open(cfg, log)
 	if err != nil {
-		panic("Failed to open postgres connection\n" + err.Error())
+		panic(err.Error())
 	}
 	return db
 }
+
+const (
+	// connectMinBackoff is the wait before retrying the first failed connection, doubled after
+	// every attempt up to connectMaxBackoff.
+	connectMinBackoff = 250 * time.Millisecond
+	connectMaxBackoff = 5 * time.Second
+)
+
+// Connect returns db client for the configured driver once the database accepts connections,
+// retrying with an exponential backoff for up to cfg.DB.ConnectTimeout, e.g. while the database
+// starts next to the service.
+func Connect(ctx context.Context, cfg *config.CFG, log ports.Logger) (*gorm.DB, error) {
+	ctx, cancel := context.WithTimeout(ctx, cfg.DB.ConnectTimeout)
+	defer cancel()
+
+	backoff := connectMinBackoff
+	for attempt := 1; ; attempt++ {
+		client, err := open(cfg, log)
+		if err == nil {
+			return client, nil
+		}
+		// Unknown drivers won't be any better on retry
+		if errors.Is(err, errUnknownDriver) {
+			return nil, err
+		}
+		log.Warn(ctx, "failed to connect to database", "attempt", attempt, "retry_in", backoff, "error", err)
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("gave up connecting after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > connectMaxBackoff {
+			backoff = connectMaxBackoff
+		}
+	}
+}
+
+// errUnknownDriver is returned for a driver Connect doesn't know.
+var errUnknownDriver = errors.New("unknown database driver")
+
+// open opens the database, checking it accepts connections.
+func open(cfg *config.CFG, log ports.Logger) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	switch cfg.DB.Driver {
+	case config.DriverSQLite:
+		dialector = sqlite.Open(getSQLiteConnString(cfg))
+	case config.DriverPostgres:
+		dialector = postgres.Open(getPostgresConnString(cfg))
+	default:
+		return nil, fmt.Errorf("%w %s", errUnknownDriver, cfg.DB.Driver)
+	}
+	// gorm pings the database once opened
+	client, err := gorm.Open(dialector, getGORMConfig(log))
+	if err != nil {
+		if client != nil {
+			if sqlDB, dbErr := client.DB(); dbErr == nil {
+				sqlDB.Close()
+			}
+		}
+		return nil, fmt.Errorf("failed to open %s database: %w", cfg.DB.Driver, err)
+	}
+	sqlDB, err := client.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(cfg.DB.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.DB.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.DB.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(cfg.DB.ConnMaxIdleTime)
+	return client, nil
+}
+
+// UseReplica sends the reads of db outside of the transactions to the replica at
+// cfg.DB.ReplicaDSN, with a pool sized as the primary one. The writes, the locking reads and the
+// reads on a context forcing the primary stay on the primary, see repositories.
+func UseReplica(db *gorm.DB, cfg *config.CFG) error {
+	var dialector gorm.Dialector
+	switch cfg.DB.Driver {
+	case config.DriverSQLite:
+		dialector = sqlite.Open(cfg.DB.ReplicaDSN)
+	case config.DriverPostgres:
+		dialector = postgres.Open(cfg.DB.ReplicaDSN)
+	default:
+		return fmt.Errorf("%w %s", errUnknownDriver, cfg.DB.Driver)
+	}
+	resolver := dbresolver.Register(dbresolver.Config{Replicas: []gorm.Dialector{dialector}}).
+		SetMaxOpenConns(cfg.DB.MaxOpenConns).
+		SetMaxIdleConns(cfg.DB.MaxIdleConns).
+		SetConnMaxLifetime(cfg.DB.ConnMaxLifetime).
+		SetConnMaxIdleTime(cfg.DB.ConnMaxIdleTime)
+	if err := db.Use(resolver); err != nil {
+		return fmt.Errorf("failed to open the replica: %w", err)
+	}
+	return nil
+}
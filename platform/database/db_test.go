@@ -0,0 +1,72 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/stretchr/testify/suite"
+)
+
+type ConnectTestSuite struct {
+	suite.Suite
+}
+
+func TestConnectTestSuite(t *testing.T) {
+	suite.Run(t, new(ConnectTestSuite))
+}
+
+func (ts *ConnectTestSuite) TestConnect() {
+	tt := []struct {
+		name             string
+		driver           string
+		path             string
+		expectedErr      string
+		expectedAttempts int
+	}{
+		{
+			name:   "Success",
+			driver: config.DriverSQLite,
+			path:   filepath.Join(ts.T().TempDir(), "isildur.db"),
+		},
+		{
+			name:             "Unreachable",
+			driver:           config.DriverSQLite,
+			path:             filepath.Join(ts.T().TempDir(), "missing", "isildur.db"),
+			expectedErr:      "gave up connecting after 2 attempts: failed to open sqlite database",
+			expectedAttempts: 2,
+		},
+		{
+			name:        "UnknownDriver",
+			driver:      "mysql",
+			expectedErr: "unknown database driver mysql",
+		},
+	}
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			logs := &bytes.Buffer{}
+			cfg := &config.CFG{DB: config.DBConfig{
+				Driver:         tc.driver,
+				Path:           tc.path,
+				ConnectTimeout: 400 * time.Millisecond,
+			}}
+			db, err := Connect(context.Background(), cfg, logger.New(logger.LevelRelease, logs))
+			ts.Assert().Equal(tc.expectedAttempts, strings.Count(logs.String(), "failed to connect to database"))
+			if tc.expectedErr != "" {
+				ts.Require().NotNil(err)
+				ts.Assert().Contains(err.Error(), tc.expectedErr)
+				return
+			}
+			ts.Require().Nil(err)
+			sqlDB, err := db.DB()
+			ts.Require().Nil(err)
+			ts.Assert().Nil(sqlDB.Ping())
+			ts.Assert().Nil(sqlDB.Close())
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/goakshit/isildur/core/ports"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// slowQueryThreshold is the duration from which a query is logged as slow.
+const slowQueryThreshold = 200 * time.Millisecond
+
+// gormLogger logs the queries at debug level with their duration, the slow and failed
+// queries as warnings. The request id is taken from the context of the queries.
+type gormLogger struct {
+	log   ports.Logger
+	level gormlogger.LogLevel
+}
+
+// NewGormLogger returns a gorm logger writing to log.
+func NewGormLogger(log ports.Logger) gormlogger.Interface {
+	return &gormLogger{
+		log:   log,
+		level: gormlogger.Info,
+	}
+}
+
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+func (l *gormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.log.Info(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.log.Warn(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+func (l *gormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.log.Error(ctx, fmt.Sprintf(msg, data...))
+	}
+}
+
+// Trace logs a query once run. Missing records aren't failures, the repositories map them to
+// the domain errors.
+func (l *gormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+	elapsed := time.Since(begin)
+	sql, rows := fc()
+	fields := []interface{}{"sql", sql, "rows", rows, "duration_ms", elapsed}
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		l.log.Warn(ctx, "query failed", append(fields, "error", err)...)
+	case elapsed >= slowQueryThreshold && l.level >= gormlogger.Warn:
+		l.log.Warn(ctx, "slow query", fields...)
+	case l.level >= gormlogger.Info:
+		l.log.Debug(ctx, "query", fields...)
+	}
+}
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// seedProducts are the products every environment starts with.
+var seedProducts = []domain.Product{
+	{
+		ID:             uuid.MustParse("56f79fee-0cb0-4e87-9bca-7b5811cca4ce"),
+		Name:           "YOGA L1",
+		Description:    "Basic yoga lessons",
+		MonthlyPrice:   5,
+		InstructorName: "A. Dhar",
+	},
+	{
+		ID:             uuid.MustParse("56f79fee-0cb0-4e87-9bca-7b5811cca4cf"),
+		Name:           "YOGA L2",
+		Description:    "Intermediate yoga lessons",
+		MonthlyPrice:   7,
+		InstructorName: "A. Dhar",
+	},
+}
+
+// Seed inserts the seed products, skipping the ones which already exist.
+// It expects the schema to be migrated.
+func Seed(ctx context.Context, db *gorm.DB) error {
+	return db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&seedProducts).Error
+}
@@ -0,0 +1,106 @@
+// Package logger writes the leveled, structured logs of the service with zerolog.
+package logger
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/mattn/go-isatty"
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var _ ports.Logger = (*Logger)(nil)
+
+const (
+	// LevelDebug logs every entry, in a human readable format.
+	LevelDebug = "debug"
+	// LevelRelease logs the entries from info on, as json lines.
+	LevelRelease = "release"
+	// LevelTest only logs the errors, as json lines.
+	LevelTest = "test"
+)
+
+// Logger writes the log entries with zerolog.
+type Logger struct {
+	log zerolog.Logger
+}
+
+// New returns a logger writing to w for the service level, which are the gin modes. Unknown
+// levels are logged like the release level.
+func New(level string, w io.Writer) *Logger {
+	var log zerolog.Logger
+	switch level {
+	case LevelDebug:
+		console := zerolog.ConsoleWriter{Out: w, TimeFormat: time.RFC3339, NoColor: !isTerminal(w)}
+		log = zerolog.New(console).Level(zerolog.DebugLevel)
+	case LevelTest:
+		log = zerolog.New(w).Level(zerolog.ErrorLevel)
+	default:
+		log = zerolog.New(w).Level(zerolog.InfoLevel)
+	}
+	return &Logger{
+		log: log.With().Timestamp().Logger(),
+	}
+}
+
+// Nop returns a logger discarding every entry.
+func Nop() *Logger {
+	return &Logger{
+		log: zerolog.Nop(),
+	}
+}
+
+// Debug logs the details only useful while developing, e.g. the sql queries.
+func (l *Logger) Debug(ctx context.Context, msg string, fields ...interface{}) {
+	write(ctx, l.log.Debug(), msg, fields)
+}
+
+// Info logs the regular events, e.g. the served requests.
+func (l *Logger) Info(ctx context.Context, msg string, fields ...interface{}) {
+	write(ctx, l.log.Info(), msg, fields)
+}
+
+// Warn logs the unexpected events the service recovers from.
+func (l *Logger) Warn(ctx context.Context, msg string, fields ...interface{}) {
+	write(ctx, l.log.Warn(), msg, fields)
+}
+
+// Error logs the failures.
+func (l *Logger) Error(ctx context.Context, msg string, fields ...interface{}) {
+	write(ctx, l.log.Error(), msg, fields)
+}
+
+// With returns a logger adding the fields to every entry.
+func (l *Logger) With(fields ...interface{}) ports.Logger {
+	return &Logger{
+		log: l.log.With().Fields(fields).Logger(),
+	}
+}
+
+// isTerminal reports whether w is a terminal, the only writer the colors are meant for.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && isatty.IsTerminal(f.Fd())
+}
+
+// write sends an entry with the request id and the trace of the context, the event being nil
+// when its level is disabled.
+func write(ctx context.Context, e *zerolog.Event, msg string, fields []interface{}) {
+	if e == nil {
+		return
+	}
+	if ctx != nil {
+		if id := domain.RequestIDFromContext(ctx); id != "" {
+			e = e.Str("request_id", id)
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+			e = e.Str("trace_id", span.TraceID().String()).Str("span_id", span.SpanID().String())
+		}
+	}
+	e.Fields(fields).Msg(msg)
+}
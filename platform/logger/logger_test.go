@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type LoggerTestSuite struct {
+	suite.Suite
+}
+
+func TestLoggerTestSuite(t *testing.T) {
+	suite.Run(t, new(LoggerTestSuite))
+}
+
+// entries decodes the json lines written by a logger.
+func (ts *LoggerTestSuite) entries(buf *bytes.Buffer) []map[string]interface{} {
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		entry := map[string]interface{}{}
+		ts.Require().Nil(json.Unmarshal([]byte(line), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (ts *LoggerTestSuite) TestFields() {
+	buf := &bytes.Buffer{}
+	log := New(LevelRelease, buf).With("service", "isildur")
+
+	ctx := domain.ContextWithRequestID(context.Background(), "req-1")
+	log.Error(ctx, "failed", "error", errors.New("boom"), "rows", 3)
+
+	entries := ts.entries(buf)
+	ts.Require().Len(entries, 1)
+	ts.Assert().Equal("error", entries[0]["level"])
+	ts.Assert().Equal("failed", entries[0]["message"])
+	ts.Assert().Equal("req-1", entries[0]["request_id"])
+	ts.Assert().Equal("isildur", entries[0]["service"])
+	ts.Assert().Equal("boom", entries[0]["error"])
+	ts.Assert().EqualValues(3, entries[0]["rows"])
+	ts.Assert().NotEmpty(entries[0]["time"])
+}
+
+func (ts *LoggerTestSuite) TestTrace() {
+	buf := &bytes.Buffer{}
+	log := New(LevelRelease, buf)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	log.Info(ctx, "served")
+	log.Info(context.Background(), "untraced")
+
+	entries := ts.entries(buf)
+	ts.Require().Len(entries, 2)
+	ts.Assert().Equal("4bf92f3577b34da6a3ce929d0e0e4736", entries[0]["trace_id"])
+	ts.Assert().Equal("00f067aa0ba902b7", entries[0]["span_id"])
+	ts.Assert().NotContains(entries[1], "trace_id")
+}
+
+func (ts *LoggerTestSuite) TestLevels() {
+	tt := []struct {
+		name     string
+		level    string
+		expected []string
+	}{
+		{
+			name:     "Release logs from info on",
+			level:    LevelRelease,
+			expected: []string{"info", "warn", "error"},
+		},
+		{
+			name:     "Unknown level is release",
+			level:    "verbose",
+			expected: []string{"info", "warn", "error"},
+		},
+		{
+			name:     "Test only logs the errors",
+			level:    LevelTest,
+			expected: []string{"error"},
+		},
+	}
+
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			buf := &bytes.Buffer{}
+			log := New(tc.level, buf)
+			ctx := context.Background()
+			log.Debug(ctx, "debug")
+			log.Info(ctx, "info")
+			log.Warn(ctx, "warn")
+			log.Error(ctx, "error")
+
+			var levels []string
+			for _, entry := range ts.entries(buf) {
+				levels = append(levels, entry["level"].(string))
+			}
+			ts.Assert().Equal(tc.expected, levels)
+		})
+	}
+}
+
+func (ts *LoggerTestSuite) TestDebugIsHumanReadable() {
+	buf := &bytes.Buffer{}
+	New(LevelDebug, buf).Debug(context.Background(), "query", "rows", 1)
+
+	ts.Assert().Contains(buf.String(), "DBG")
+	ts.Assert().Contains(buf.String(), "query")
+	ts.Assert().Contains(buf.String(), "rows=1")
+}
@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// queryStartKey stores the start of a query in the gorm statement.
+const queryStartKey = "metrics:query_start"
+
+// gormPlugin times the queries run through gorm.
+type gormPlugin struct {
+	metrics *Metrics
+}
+
+// GormPlugin returns the gorm plugin recording the duration of the queries, to be set up with
+// db.Use.
+func (m *Metrics) GormPlugin() gorm.Plugin {
+	return gormPlugin{metrics: m}
+}
+
+func (p gormPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize registers the callbacks around every kind of query.
+func (p gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register("metrics:before_create", start),
+		cb.Create().After("gorm:create").Register("metrics:after_create", p.observe("create")),
+		cb.Query().Before("gorm:query").Register("metrics:before_query", start),
+		cb.Query().After("gorm:query").Register("metrics:after_query", p.observe("query")),
+		cb.Update().Before("gorm:update").Register("metrics:before_update", start),
+		cb.Update().After("gorm:update").Register("metrics:after_update", p.observe("update")),
+		cb.Delete().Before("gorm:delete").Register("metrics:before_delete", start),
+		cb.Delete().After("gorm:delete").Register("metrics:after_delete", p.observe("delete")),
+		cb.Row().Before("gorm:row").Register("metrics:before_row", start),
+		cb.Row().After("gorm:row").Register("metrics:after_row", p.observe("row")),
+		cb.Raw().Before("gorm:raw").Register("metrics:before_raw", start),
+		cb.Raw().After("gorm:raw").Register("metrics:after_raw", p.observe("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func start(db *gorm.DB) {
+	db.InstanceSet(queryStartKey, time.Now())
+}
+
+func (p gormPlugin) observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		started, ok := db.InstanceGet(queryStartKey)
+		if !ok {
+			return
+		}
+		p.metrics.ObserveQuery(operation, db.Statement.Table, time.Since(started.(time.Time)))
+	}
+}
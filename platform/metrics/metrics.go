@@ -0,0 +1,117 @@
+// Package metrics exposes the http, database and business metrics of the service to prometheus.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var _ ports.Metrics = (*Metrics)(nil)
+
+// namespace prefixes the metrics of the service.
+const namespace = "isildur"
+
+// Metrics records the metrics in its own registry, exposed by its Handler.
+type Metrics struct {
+	registry             *prometheus.Registry
+	requests             *prometheus.HistogramVec
+	queries              *prometheus.HistogramVec
+	subscriptionsCreated *prometheus.CounterVec
+	statusTransitions    *prometheus.CounterVec
+	cacheLookups         *prometheus.CounterVec
+}
+
+// New returns the metrics of the service, along with the go runtime and process metrics.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Duration of the http requests per route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		queries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "db",
+			Name:      "query_duration_seconds",
+			Help:      "Duration of the sql queries per operation and table.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"operation", "table"}),
+		subscriptionsCreated: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "subscriptions_created_total",
+			Help:      "Subscriptions created per product and initial status.",
+		}, []string{"product_id", "status"}),
+		statusTransitions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "subscription_status_transitions_total",
+			Help:      "Status changes of the subscriptions.",
+		}, []string{"from", "to"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Lookups of the caches per tier and result, hit or miss.",
+		}, []string{"cache", "tier", "result"}),
+	}
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.queries,
+		m.subscriptionsCreated,
+		m.statusTransitions,
+		m.cacheLookups,
+	)
+	return m
+}
+
+// Handler serves the metrics in the prometheus exposition format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterDB exposes the statistics of the connection pool of a database.
+func (m *Metrics) RegisterDB(name string, db *sql.DB) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// ObserveRequest records the duration of a served http request. The route is the path pattern
+// the request matched, not to label the metrics per id.
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Observe(duration.Seconds())
+}
+
+// ObserveQuery records the duration of a sql query.
+func (m *Metrics) ObserveQuery(operation, table string, duration time.Duration) {
+	m.queries.WithLabelValues(operation, table).Observe(duration.Seconds())
+}
+
+// SubscriptionsCreated counts the subscriptions created for a product in a status.
+func (m *Metrics) SubscriptionsCreated(productID uuid.UUID, status domain.SubscriptionStatus, count int) {
+	m.subscriptionsCreated.WithLabelValues(productID.String(), status.String()).Add(float64(count))
+}
+
+// SubscriptionStatusChanged counts a status transition of a subscription.
+func (m *Metrics) SubscriptionStatusChanged(from, to domain.SubscriptionStatus) {
+	m.statusTransitions.WithLabelValues(from.String(), to.String()).Inc()
+}
+
+// CacheLookup counts a lookup of a cache tier, e.g. local or shared, hit or missed.
+func (m *Metrics) CacheLookup(cache, tier string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	m.cacheLookups.WithLabelValues(cache, tier, result).Inc()
+}
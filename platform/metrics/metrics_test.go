@@ -0,0 +1,90 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/database"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/suite"
+)
+
+type MetricsTestSuite struct {
+	suite.Suite
+	metrics *Metrics
+}
+
+func TestMetricsTestSuite(t *testing.T) {
+	suite.Run(t, new(MetricsTestSuite))
+}
+
+func (ts *MetricsTestSuite) SetupTest() {
+	ts.metrics = New()
+}
+
+// scrape returns the metrics as served to prometheus.
+func (ts *MetricsTestSuite) scrape() string {
+	w := httptest.NewRecorder()
+	ts.metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	ts.Require().Equal(http.StatusOK, w.Code)
+	data, err := io.ReadAll(w.Result().Body)
+	ts.Require().Nil(err)
+	return string(data)
+}
+
+func (ts *MetricsTestSuite) TestBusinessMetrics() {
+	productID := uuid.MustParse("56f79fee-0cb0-4e87-9bca-7b5811cca4ce")
+	ts.metrics.SubscriptionsCreated(productID, domain.SubscriptionStatusActive, 2)
+	ts.metrics.SubscriptionsCreated(productID, domain.SubscriptionStatusActive, 1)
+	ts.metrics.SubscriptionStatusChanged(domain.SubscriptionStatusActive, domain.SubscriptionStatusPaused)
+	ts.metrics.CacheLookup("products", "local", true)
+	ts.metrics.CacheLookup("products", "local", false)
+
+	ts.Assert().Equal(3.0, testutil.ToFloat64(ts.metrics.subscriptionsCreated.WithLabelValues(productID.String(), "active")))
+	ts.Assert().Equal(1.0, testutil.ToFloat64(ts.metrics.statusTransitions.WithLabelValues("active", "paused")))
+
+	scraped := ts.scrape()
+	ts.Assert().Contains(scraped, `isildur_subscriptions_created_total{product_id="56f79fee-0cb0-4e87-9bca-7b5811cca4ce",status="active"} 3`)
+	ts.Assert().Contains(scraped, `isildur_subscription_status_transitions_total{from="active",to="paused"} 1`)
+	ts.Assert().Contains(scraped, `isildur_cache_lookups_total{cache="products",result="hit",tier="local"} 1`)
+	ts.Assert().Contains(scraped, `isildur_cache_lookups_total{cache="products",result="miss",tier="local"} 1`)
+	ts.Assert().Contains(scraped, "go_goroutines")
+}
+
+func (ts *MetricsTestSuite) TestObserveRequest() {
+	ts.metrics.ObserveRequest(http.MethodGet, "/api/products/:product-id", http.StatusOK, 30*time.Millisecond)
+
+	scraped := ts.scrape()
+	ts.Assert().Contains(scraped, `isildur_http_request_duration_seconds_count{method="GET",route="/api/products/:product-id",status="200"} 1`)
+	ts.Assert().Contains(scraped, `isildur_http_request_duration_seconds_bucket{method="GET",route="/api/products/:product-id",status="200",le="0.05"} 1`)
+}
+
+func (ts *MetricsTestSuite) TestDatabase() {
+	db := database.GetGormClient(&config.CFG{DB: config.DBConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(ts.T().TempDir(), "isildur.db"),
+	}}, logger.Nop())
+	sqlDB, err := db.DB()
+	ts.Require().Nil(err)
+	defer sqlDB.Close()
+
+	ts.Require().Nil(db.Use(ts.metrics.GormPlugin()))
+	ts.Require().Nil(ts.metrics.RegisterDB("isildur", sqlDB))
+	ts.Require().Nil(db.AutoMigrate(&domain.Product{}))
+	ts.Require().Nil(db.Create(&domain.Product{ID: uuid.New(), Name: "YOGA 1"}).Error)
+	var products []domain.Product
+	ts.Require().Nil(db.Find(&products).Error)
+
+	scraped := ts.scrape()
+	ts.Assert().Contains(scraped, `isildur_db_query_duration_seconds_count{operation="create",table="product"} 1`)
+	ts.Assert().Contains(scraped, `isildur_db_query_duration_seconds_count{operation="query",table="product"} 1`)
+	ts.Assert().Contains(scraped, `go_sql_open_connections{db_name="isildur"}`)
+}
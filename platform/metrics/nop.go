@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/google/uuid"
+)
+
+var _ ports.Metrics = nop{}
+
+// nop discards the business metrics.
+type nop struct{}
+
+// Nop returns metrics discarding every record, for when they aren't exposed.
+func Nop() ports.Metrics {
+	return nop{}
+}
+
+func (nop) SubscriptionsCreated(uuid.UUID, domain.SubscriptionStatus, int) {}
+
+func (nop) SubscriptionStatusChanged(domain.SubscriptionStatus, domain.SubscriptionStatus) {}
+
+func (nop) CacheLookup(string, string, bool) {}
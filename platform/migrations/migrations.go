@@ -0,0 +1,300 @@
+// Package migrations holds the versioned schema migrations of the service.
+// Migrations are embedded in the binary, one set per database dialect, and are
+// named <version>_<name>.<up|down>.sql.
+package migrations
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/goakshit/isildur/core/ports"
+	"gorm.io/gorm"
+)
+
+//go:embed sql
+var files embed.FS
+
+// advisoryLockKey is the postgres advisory lock held while migrating.
+const advisoryLockKey = 7_211_983_240
+
+var fileNameRegex = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// ErrUnknownDialect is returned when there are no migrations for the database dialect.
+var ErrUnknownDialect = errors.New("no migrations for database dialect")
+
+// Migration represents a single versioned schema change.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status represents whether a migration has been applied.
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt *time.Time
+}
+
+// schemaMigration represents an applied migration in the migrations table.
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+// TableName overrides the table name used by gorm.
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrator applies and reverts the migrations on a database.
+type Migrator struct {
+	db         *gorm.DB
+	log        ports.Logger
+	migrations []Migration
+}
+
+// New creates and returns a new Migrator for the dialect of the given db client.
+func New(db *gorm.DB, log ports.Logger) (*Migrator, error) {
+	migrations, err := load(db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		log:        log,
+		migrations: migrations,
+	}, nil
+}
+
+// load reads the ordered migrations of a dialect from the embedded files.
+func load(dialect string) ([]Migration, error) {
+	dir := path.Join("sql", dialect)
+	entries, err := fs.ReadDir(files, dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownDialect, dialect)
+	}
+
+	byVersion := map[int]*Migration{}
+	for _, entry := range entries {
+		matches := fileNameRegex.FindStringSubmatch(entry.Name())
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(matches[1])
+		content, err := fs.ReadFile(files, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = m
+		}
+		if m.Name != matches[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %s and %s", version, m.Name, matches[2])
+		}
+		if matches[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// Up applies all the pending migrations in order and returns the applied ones.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var applied []Migration
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		if err := m.adoptBaseline(ctx, conn); err != nil {
+			return err
+		}
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := done[migration.Version]; ok {
+				continue
+			}
+			var skipped bool
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				// Another runner may have applied it since we read the table
+				if skipped, err = isApplied(tx, migration.Version); err != nil || skipped {
+					return err
+				}
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return tx.Create(&schemaMigration{
+					Version:   migration.Version,
+					Name:      migration.Name,
+					AppliedAt: time.Now().UTC(),
+				}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			if !skipped {
+				applied = append(applied, migration)
+			}
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down reverts the last applied migrations, at most steps of them, and returns the reverted ones.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var reverted []Migration
+	err := m.withLock(ctx, func(conn *gorm.DB) error {
+		done, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0 && len(reverted) < steps; i-- {
+			migration := m.migrations[i]
+			if _, ok := done[migration.Version]; !ok {
+				continue
+			}
+			var skipped bool
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				// Another runner may have reverted it since we read the table
+				applied, err := isApplied(tx, migration.Version)
+				if skipped = !applied; err != nil || skipped {
+					return err
+				}
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return tx.Delete(&schemaMigration{Version: migration.Version}).Error
+			})
+			if err != nil {
+				return fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			if !skipped {
+				reverted = append(reverted, migration)
+			}
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Status reports every known migration and whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	conn := m.db.WithContext(ctx)
+	if err := conn.AutoMigrate(&schemaMigration{}); err != nil {
+		return nil, err
+	}
+	done, err := appliedVersions(conn)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Version: migration.Version, Name: migration.Name}
+		if sm, ok := done[migration.Version]; ok {
+			appliedAt := sm.AppliedAt
+			status.Applied, status.AppliedAt = true, &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// withLock runs fn on a single connection while holding the migrations lock, so that
+// concurrent runners, e.g. several replicas starting at once, apply migrations only once.
+// On sqlite writes are serialised by the database itself, so no extra lock is taken.
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if conn.Dialector.Name() == "postgres" {
+			if err := conn.Exec("select pg_advisory_lock(?)", advisoryLockKey).Error; err != nil {
+				return fmt.Errorf("failed to acquire migrations lock: %w", err)
+			}
+			defer func() {
+				// The lock is otherwise held by the pooled connection until it is closed
+				if err := conn.Exec("select pg_advisory_unlock(?)", advisoryLockKey).Error; err != nil {
+					m.log.Error(ctx, "failed to release migrations lock", "error", err)
+				}
+			}()
+		}
+		if err := conn.AutoMigrate(&schemaMigration{}); err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+// adoptBaseline records the first migration as applied on the databases created before the
+// migrations from build/scripts/db/init.sql, which created the same schema, instead of failing to
+// create the existing tables. The databases created before the subscription version are given it.
+func (m *Migrator) adoptBaseline(ctx context.Context, conn *gorm.DB) error {
+	var count int64
+	if err := conn.Model(&schemaMigration{}).Count(&count).Error; err != nil || count > 0 {
+		return err
+	}
+	if !conn.Migrator().HasTable("product") {
+		return nil
+	}
+	baseline := m.migrations[0]
+	return conn.Transaction(func(tx *gorm.DB) error {
+		if tx.Migrator().HasTable("subscription") && !tx.Migrator().HasColumn("subscription", "version") {
+			if err := tx.Exec("alter table subscription add column version integer not null default 1").Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(&schemaMigration{
+			Version:   baseline.Version,
+			Name:      baseline.Name,
+			AppliedAt: time.Now().UTC(),
+		}).Error; err != nil {
+			return err
+		}
+		m.log.Info(ctx, "adopted existing schema as baseline migration", "version", baseline.Version, "name", baseline.Name)
+		return nil
+	})
+}
+
+// appliedVersions returns the applied migrations keyed by version.
+func appliedVersions(conn *gorm.DB) (map[int]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := conn.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[int]schemaMigration, len(rows))
+	for _, row := range rows {
+		done[row.Version] = row
+	}
+	return done, nil
+}
+
+// isApplied checks whether a migration version is recorded in the migrations table.
+func isApplied(tx *gorm.DB, version int) (bool, error) {
+	var count int64
+	err := tx.Model(&schemaMigration{}).Where("version = ?", version).Count(&count).Error
+	return count > 0, err
+}
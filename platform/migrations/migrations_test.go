@@ -0,0 +1,131 @@
+package migrations
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/database"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+)
+
+type MigrationsTestSuite struct {
+	suite.Suite
+	db       *gorm.DB
+	migrator *Migrator
+}
+
+func TestMigrationsTestSuite(t *testing.T) {
+	suite.Run(t, new(MigrationsTestSuite))
+}
+
+func (ts *MigrationsTestSuite) SetupTest() {
+	ts.db = database.GetGormClient(&config.CFG{DB: config.DBConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(ts.T().TempDir(), "isildur.db"),
+	}}, logger.Nop())
+	var err error
+	ts.migrator, err = New(ts.db, logger.Nop())
+	ts.Require().Nil(err)
+}
+
+func (ts *MigrationsTestSuite) TearDownTest() {
+	if sqlDB, err := ts.db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+func (ts *MigrationsTestSuite) TestLoad_AllDialects() {
+	for _, dialect := range []string{"postgres", "sqlite"} {
+		migrations, err := load(dialect)
+		ts.Require().Nil(err)
+		ts.Require().NotEmpty(migrations)
+		ts.Assert().Equal(1, migrations[0].Version)
+		ts.Assert().Equal("init", migrations[0].Name)
+		for i := 1; i < len(migrations); i++ {
+			ts.Assert().Less(migrations[i-1].Version, migrations[i].Version)
+		}
+	}
+
+	_, err := load("mysql")
+	ts.Assert().ErrorIs(err, ErrUnknownDialect)
+}
+
+func (ts *MigrationsTestSuite) TestUpDownStatus() {
+	ctx := context.Background()
+
+	applied, err := ts.migrator.Up(ctx)
+	ts.Require().Nil(err)
+	ts.Assert().Len(applied, len(ts.migrator.migrations))
+	ts.Assert().True(ts.db.Migrator().HasTable("subscription"))
+
+	// Running again is a no-op
+	applied, err = ts.migrator.Up(ctx)
+	ts.Require().Nil(err)
+	ts.Assert().Empty(applied)
+
+	statuses, err := ts.migrator.Status(ctx)
+	ts.Require().Nil(err)
+	for _, s := range statuses {
+		ts.Assert().True(s.Applied)
+		ts.Assert().NotNil(s.AppliedAt)
+	}
+
+	reverted, err := ts.migrator.Down(ctx, len(ts.migrator.migrations))
+	ts.Require().Nil(err)
+	ts.Assert().Len(reverted, len(ts.migrator.migrations))
+	ts.Assert().False(ts.db.Migrator().HasTable("subscription"))
+
+	statuses, err = ts.migrator.Status(ctx)
+	ts.Require().Nil(err)
+	for _, s := range statuses {
+		ts.Assert().False(s.Applied)
+	}
+}
+
+func (ts *MigrationsTestSuite) TestSeed() {
+	ctx := context.Background()
+	_, err := ts.migrator.Up(ctx)
+	ts.Require().Nil(err)
+
+	// Seeding twice doesn't duplicate the products
+	ts.Require().Nil(database.Seed(ctx, ts.db))
+	ts.Require().Nil(database.Seed(ctx, ts.db))
+
+	var count int64
+	ts.Require().Nil(ts.db.Table("product").Count(&count).Error)
+	ts.Assert().EqualValues(2, count)
+}
+
+func (ts *MigrationsTestSuite) TestUp_AdoptsBaseline() {
+	ctx := context.Background()
+	// A database created from build/scripts/db/init.sql, before the subscription version
+	ts.Require().Nil(ts.db.Exec(`create table product (
+		id varchar(36) not null primary key, name varchar, description varchar,
+		monthly_price numeric, instructor_name varchar)`).Error)
+	ts.Require().Nil(ts.db.Exec(`create table subscription (
+		id varchar(36) not null primary key, product_id varchar(36) not null,
+		duration_in_months smallint, tax numeric, total_cost numeric, status varchar,
+		start_date datetime, end_date datetime)`).Error)
+	ts.Require().Nil(ts.db.Exec(`insert into product values
+		('56f79fee-0cb0-4e87-9bca-7b5811cca4ce', 'YOGA L1', 'Basic yoga lessons', 5, 'A. Dhar')`).Error)
+
+	applied, err := ts.migrator.Up(ctx)
+	ts.Require().Nil(err)
+	ts.Require().Len(applied, len(ts.migrator.migrations)-1)
+	ts.Assert().Equal(2, applied[0].Version)
+	ts.Assert().True(ts.db.Migrator().HasColumn("subscription", "version"))
+
+	statuses, err := ts.migrator.Status(ctx)
+	ts.Require().Nil(err)
+	for _, s := range statuses {
+		ts.Assert().True(s.Applied)
+	}
+	// The existing data is kept
+	var count int64
+	ts.Require().Nil(ts.db.Table("product").Count(&count).Error)
+	ts.Assert().EqualValues(1, count)
+}
@@ -0,0 +1,15 @@
+create table revenue_entry (
+    id uuid not null primary key,
+    subscription_id uuid not null,
+    product_id uuid not null,
+    month smallint not null,
+    period_start timestamptz not null,
+    period_end timestamptz not null,
+    amount numeric not null,
+    status varchar not null,
+    recognize_on timestamptz not null,
+    booked_on timestamptz not null,
+    refunded_on timestamptz
+);
+create index revenue_entry_subscription_id_idx on revenue_entry (subscription_id);
+create index revenue_entry_recognize_on_idx on revenue_entry (recognize_on);
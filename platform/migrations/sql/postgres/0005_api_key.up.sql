@@ -0,0 +1,12 @@
+create table api_key (
+    id uuid not null primary key,
+    name varchar not null,
+    prefix varchar not null,
+    hash varchar not null,
+    scopes varchar not null,
+    created_at timestamptz not null,
+    expires_at timestamptz,
+    last_used_at timestamptz,
+    revoked_at timestamptz
+);
+create unique index api_key_prefix_idx on api_key (prefix);
@@ -0,0 +1,2 @@
+drop index product_instructor_name_idx;
+drop index product_search_idx;
@@ -0,0 +1,3 @@
+create index product_search_idx on product
+    using gin (to_tsvector('english', coalesce(name, '') || ' ' || coalesce(description, '')));
+create index product_instructor_name_idx on product (lower(instructor_name));
@@ -0,0 +1,18 @@
+create table product (
+    id varchar(36) not null primary key,
+    name varchar,
+    description varchar,
+    monthly_price numeric,
+    instructor_name varchar
+);
+create table subscription (
+    id varchar(36) not null primary key,
+    product_id varchar(36) not null,
+    duration_in_months smallint,
+    tax numeric,
+    total_cost numeric,
+    status varchar,
+    start_date datetime,
+    end_date datetime,
+    version integer not null default 1
+);
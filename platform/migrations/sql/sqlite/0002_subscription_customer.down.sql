@@ -0,0 +1,2 @@
+drop index subscription_customer_id_idx;
+alter table subscription drop column customer_id;
@@ -0,0 +1,2 @@
+alter table subscription add column customer_id varchar not null default '';
+create index subscription_customer_id_idx on subscription (customer_id);
@@ -0,0 +1,5 @@
+drop index subscription_cancelled_at_idx;
+drop index subscription_start_date_idx;
+alter table subscription drop column resumed_at;
+alter table subscription drop column paused_at;
+alter table subscription drop column cancelled_at;
@@ -0,0 +1,5 @@
+alter table subscription add column cancelled_at datetime;
+alter table subscription add column paused_at datetime;
+alter table subscription add column resumed_at datetime;
+create index subscription_start_date_idx on subscription (start_date);
+create index subscription_cancelled_at_idx on subscription (cancelled_at);
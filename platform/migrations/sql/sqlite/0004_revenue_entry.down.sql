@@ -0,0 +1 @@
+drop table revenue_entry;
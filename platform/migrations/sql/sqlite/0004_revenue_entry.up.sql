@@ -0,0 +1,15 @@
+create table revenue_entry (
+    id varchar(36) not null primary key,
+    subscription_id varchar(36) not null,
+    product_id varchar(36) not null,
+    month smallint not null,
+    period_start datetime not null,
+    period_end datetime not null,
+    amount numeric not null,
+    status varchar not null,
+    recognize_on datetime not null,
+    booked_on datetime not null,
+    refunded_on datetime
+);
+create index revenue_entry_subscription_id_idx on revenue_entry (subscription_id);
+create index revenue_entry_recognize_on_idx on revenue_entry (recognize_on);
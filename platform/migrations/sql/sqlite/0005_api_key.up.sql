@@ -0,0 +1,12 @@
+create table api_key (
+    id varchar(36) not null primary key,
+    name varchar not null,
+    prefix varchar not null,
+    hash varchar not null,
+    scopes varchar not null,
+    created_at datetime not null,
+    expires_at datetime,
+    last_used_at datetime,
+    revoked_at datetime
+);
+create unique index api_key_prefix_idx on api_key (prefix);
@@ -0,0 +1,8 @@
+create table rate_limit_bucket (
+    bucket_key varchar not null primary key,
+    tokens real not null,
+    refilled_at datetime not null,
+    full_at datetime not null,
+    version integer not null
+);
+create index rate_limit_bucket_full_at_idx on rate_limit_bucket (full_at);
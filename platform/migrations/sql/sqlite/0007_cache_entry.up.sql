@@ -0,0 +1,6 @@
+create table cache_entry (
+    cache_key varchar not null primary key,
+    value blob not null,
+    expires_at datetime not null
+);
+create index cache_entry_expires_at_idx on cache_entry (expires_at);
@@ -0,0 +1 @@
+drop index product_instructor_name_idx;
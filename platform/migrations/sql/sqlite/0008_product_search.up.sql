@@ -0,0 +1 @@
+create index product_instructor_name_idx on product (lower(instructor_name));
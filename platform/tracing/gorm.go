@@ -0,0 +1,101 @@
+package tracing
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// instrumentationName names the tracer of the queries.
+const instrumentationName = "github.com/goakshit/isildur/platform/tracing"
+
+// spanKey stores the span of a query in the gorm statement.
+const spanKey = "tracing:span"
+
+// gormPlugin traces the queries run through gorm.
+type gormPlugin struct{}
+
+// GormPlugin returns the gorm plugin tracing every query in a span, child of the span of the
+// context the query runs with, to be set up with db.Use.
+func GormPlugin() gorm.Plugin {
+	return gormPlugin{}
+}
+
+func (p gormPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registers the callbacks around every kind of query.
+func (p gormPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:create").Register("tracing:before_create", startSpan("create")),
+		cb.Create().After("gorm:create").Register("tracing:after_create", endSpan),
+		cb.Query().Before("gorm:query").Register("tracing:before_query", startSpan("query")),
+		cb.Query().After("gorm:query").Register("tracing:after_query", endSpan),
+		cb.Update().Before("gorm:update").Register("tracing:before_update", startSpan("update")),
+		cb.Update().After("gorm:update").Register("tracing:after_update", endSpan),
+		cb.Delete().Before("gorm:delete").Register("tracing:before_delete", startSpan("delete")),
+		cb.Delete().After("gorm:delete").Register("tracing:after_delete", endSpan),
+		cb.Row().Before("gorm:row").Register("tracing:before_row", startSpan("row")),
+		cb.Row().After("gorm:row").Register("tracing:after_row", endSpan),
+		cb.Raw().Before("gorm:raw").Register("tracing:before_raw", startSpan("raw")),
+		cb.Raw().After("gorm:raw").Register("tracing:after_raw", endSpan),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		ctx := db.Statement.Context
+		_, span := otel.Tracer(instrumentationName).Start(ctx, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemKey.String(dbSystem(db.Dialector.Name())),
+				semconv.DBOperationKey.String(operation),
+			),
+		)
+		db.InstanceSet(spanKey, span)
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(spanKey)
+	if !ok {
+		return
+	}
+	span := value.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.DBSQLTableKey.String(db.Statement.Table),
+		// The statement has placeholders, the values aren't recorded
+		semconv.DBStatementKey.String(db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.Statement.RowsAffected),
+	)
+	if err := db.Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// dbSystem returns the opentelemetry name of the database a gorm dialector connects to.
+func dbSystem(dialector string) string {
+	switch dialector {
+	case "postgres":
+		return semconv.DBSystemPostgreSQL.Value.AsString()
+	case "sqlite":
+		return semconv.DBSystemSqlite.Value.AsString()
+	default:
+		return dialector
+	}
+}
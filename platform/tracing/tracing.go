@@ -0,0 +1,65 @@
+// Package tracing traces the requests through the handlers, the services and the database with
+// opentelemetry.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/goakshit/isildur/platform/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.12.0"
+)
+
+// Setup installs the tracer provider exporting the spans as configured, and the W3C trace
+// context propagator reading the parent span of the requests. The returned function flushes the
+// pending spans, to be called before exiting. Nothing is exported with the none exporter.
+func Setup(ctx context.Context, cfg *config.CFG) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch cfg.Tracing.Exporter {
+	case config.TracingNone:
+		return func(context.Context) error { return nil }, nil
+	case config.TracingOTLP:
+		opts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(cfg.Tracing.Endpoint)}
+		if cfg.Tracing.Insecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, opts...)
+	case config.TracingStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", cfg.Tracing.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup the %s exporter: %w", cfg.Tracing.Exporter, err)
+	}
+
+	provider := NewProvider(cfg, sdktrace.WithBatcher(exporter))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider returns a tracer provider for the service, sampling the configured share of the
+// traces not sampled by the caller yet. The spans are sent to the processors in opts.
+func NewProvider(cfg *config.CFG, opts ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	opts = append([]sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(cfg.ServiceName),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.Tracing.SampleRatio))),
+	}, opts...)
+	return sdktrace.NewTracerProvider(opts...)
+}
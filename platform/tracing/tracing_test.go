@@ -0,0 +1,117 @@
+package tracing
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/database"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"gorm.io/gorm"
+)
+
+type TracingTestSuite struct {
+	suite.Suite
+	spans *tracetest.InMemoryExporter
+}
+
+func TestTracingTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingTestSuite))
+}
+
+func (ts *TracingTestSuite) SetupTest() {
+	ts.spans = tracetest.NewInMemoryExporter()
+	cfg := &config.CFG{ServiceName: "isildur", Tracing: config.TracingConfig{SampleRatio: 1}}
+	otel.SetTracerProvider(NewProvider(cfg, sdktrace.WithSyncer(ts.spans)))
+}
+
+// attributes returns the attributes of a span by key.
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attrs := map[attribute.Key]attribute.Value{}
+	for _, attr := range span.Attributes {
+		attrs[attr.Key] = attr.Value
+	}
+	return attrs
+}
+
+func (ts *TracingTestSuite) TestSetup() {
+	tt := []struct {
+		name        string
+		exporter    string
+		expectedErr string
+	}{
+		{name: "None", exporter: config.TracingNone},
+		{name: "Stdout", exporter: config.TracingStdout},
+		{name: "OTLP", exporter: config.TracingOTLP},
+		{name: "Unknown", exporter: "zipkin", expectedErr: `unknown tracing exporter "zipkin"`},
+	}
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			shutdown, err := Setup(context.Background(), &config.CFG{Tracing: config.TracingConfig{
+				Exporter: tc.exporter,
+				Endpoint: "localhost:4317",
+				Insecure: true,
+			}})
+			if tc.expectedErr != "" {
+				ts.Assert().EqualError(err, tc.expectedErr)
+				return
+			}
+			ts.Require().Nil(err)
+			ts.Assert().Nil(shutdown(context.Background()))
+		})
+	}
+}
+
+func (ts *TracingTestSuite) TestGormPlugin() {
+	db := database.GetGormClient(&config.CFG{DB: config.DBConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(ts.T().TempDir(), "isildur.db"),
+	}}, logger.Nop())
+	sqlDB, err := db.DB()
+	ts.Require().Nil(err)
+	defer sqlDB.Close()
+	ts.Require().Nil(db.Use(GormPlugin()))
+	ts.Require().Nil(db.AutoMigrate(&domain.Product{}))
+	ts.spans.Reset()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	ts.Require().Nil(db.WithContext(ctx).Create(&domain.Product{ID: uuid.New(), Name: "YOGA 1"}).Error)
+	var product domain.Product
+	err = db.WithContext(ctx).Where("id = ?", uuid.New()).First(&product).Error
+	ts.Require().ErrorIs(err, gorm.ErrRecordNotFound)
+	ts.Require().NotNil(db.WithContext(ctx).Exec("SELECT * FROM missing").Error)
+	parent.End()
+
+	spans := ts.spans.GetSpans()
+	ts.Require().Len(spans, 4)
+	for _, span := range spans[:3] {
+		ts.Assert().Equal(parent.SpanContext().TraceID(), span.SpanContext.TraceID())
+		ts.Assert().Equal(parent.SpanContext().SpanID(), span.Parent.SpanID())
+	}
+
+	ts.Assert().Equal("gorm.create", spans[0].Name)
+	attrs := attributes(spans[0])
+	ts.Assert().Equal("sqlite", attrs["db.system"].AsString())
+	ts.Assert().Equal("create", attrs["db.operation"].AsString())
+	ts.Assert().Equal("product", attrs["db.sql.table"].AsString())
+	ts.Assert().Contains(attrs["db.statement"].AsString(), "INSERT INTO `product`")
+	ts.Assert().Equal(int64(1), attrs["db.rows_affected"].AsInt64())
+	ts.Assert().Equal(codes.Unset, spans[0].Status.Code)
+
+	// Missing records aren't failures of the query
+	ts.Assert().Equal("gorm.query", spans[1].Name)
+	ts.Assert().Equal(codes.Unset, spans[1].Status.Code)
+
+	ts.Assert().Equal("gorm.raw", spans[2].Name)
+	ts.Assert().Equal(codes.Error, spans[2].Status.Code)
+	ts.Assert().Len(spans[2].Events, 1)
+}
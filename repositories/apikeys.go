@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var _ ports.APIKeysRepository = (*APIKeysRepository)(nil)
+
+// apiKeyRow is the api_key table row, the scopes being stored comma separated.
+type apiKeyRow struct {
+	ID         uuid.UUID `gorm:"type:uuid;primary_key;"`
+	Name       string
+	Prefix     string
+	Hash       string
+	Scopes     string
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+func (apiKeyRow) TableName() string {
+	return "api_key"
+}
+
+func newAPIKeyRow(key domain.APIKey) apiKeyRow {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	return apiKeyRow{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Hash:       key.Hash,
+		Scopes:     strings.Join(scopes, ","),
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
+
+func (r apiKeyRow) apiKey() domain.APIKey {
+	scopes := []domain.Role{}
+	for _, scope := range strings.Split(r.Scopes, ",") {
+		if scope != "" {
+			scopes = append(scopes, domain.Role(scope))
+		}
+	}
+	return domain.APIKey{
+		ID:         r.ID,
+		Name:       r.Name,
+		Prefix:     r.Prefix,
+		Hash:       r.Hash,
+		Scopes:     scopes,
+		CreatedAt:  r.CreatedAt,
+		ExpiresAt:  r.ExpiresAt,
+		LastUsedAt: r.LastUsedAt,
+		RevokedAt:  r.RevokedAt,
+	}
+}
+
+// APIKeysRepository represents list of dependencies for repository.
+type APIKeysRepository struct {
+	db *gorm.DB
+}
+
+// NewAPIKeysRepository creates and returns new APIKeysRepository.
+func NewAPIKeysRepository(db *gorm.DB) *APIKeysRepository {
+	return &APIKeysRepository{
+		db: db,
+	}
+}
+
+// Create is used to create an API key in the db.
+func (ar APIKeysRepository) Create(ctx context.Context, key domain.APIKey) error {
+	row := newAPIKeyRow(key)
+	return conn(ctx, ar.db).Create(&row).Error
+}
+
+// GetByPrefix fetches the API key with a given prefix.
+func (ar APIKeysRepository) GetByPrefix(ctx context.Context, prefix string) (domain.APIKey, error) {
+	var row apiKeyRow
+	result := conn(ctx, ar.db).Where("prefix = ?", prefix).First(&row)
+	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return domain.APIKey{}, domain.ErrAPIKeyNotfound
+	}
+	if result.Error != nil {
+		return domain.APIKey{}, result.Error
+	}
+	return row.apiKey(), nil
+}
+
+// List fetches every API key, ordered by creation date.
+func (ar APIKeysRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	var rows []apiKeyRow
+	if err := conn(ctx, ar.db).Order("created_at, id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	keys := make([]domain.APIKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, row.apiKey())
+	}
+	return keys, nil
+}
+
+// Revoke records when an API key got revoked, keeping the first date if already revoked.
+func (ar APIKeysRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	db := conn(ctx, ar.db)
+	result := db.Model(&apiKeyRow{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+	return ar.exists(db, id)
+}
+
+// Touch records when an API key was last used.
+func (ar APIKeysRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	db := conn(ctx, ar.db)
+	result := db.Model(&apiKeyRow{}).Where("id = ?", id).Update("last_used_at", at)
+	if result.Error != nil || result.RowsAffected > 0 {
+		return result.Error
+	}
+	return ar.exists(db, id)
+}
+
+// exists returns domain.ErrAPIKeyNotfound unless an API key exists.
+func (ar APIKeysRepository) exists(db *gorm.DB, id uuid.UUID) error {
+	var count int64
+	if err := db.Model(&apiKeyRow{}).Where("id = ?", id).Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return domain.ErrAPIKeyNotfound
+	}
+	return nil
+}
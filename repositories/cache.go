@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/goakshit/isildur/core/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var _ ports.Cache = (*Cache)(nil)
+
+// cacheSweepEvery is the number of values stored between two deletions of the expired entries.
+const cacheSweepEvery = 1024
+
+// cacheEntryRow is the cache_entry table row.
+type cacheEntryRow struct {
+	CacheKey  string `gorm:"primaryKey"`
+	Value     []byte
+	ExpiresAt time.Time
+}
+
+func (cacheEntryRow) TableName() string {
+	return "cache_entry"
+}
+
+// Cache is the gorm implementation of ports.Cache, sharing the entries between the replicas
+// through the database.
+type Cache struct {
+	db *gorm.DB
+
+	mu   sync.Mutex
+	sets int
+}
+
+// NewCache creates and returns new Cache.
+func NewCache(db *gorm.DB) *Cache {
+	return &Cache{
+		db: db,
+	}
+}
+
+// Get returns the value of a key, unless it is missing or expired at now. The entries are read
+// on the primary database, not to miss an invalidation on a replica lagging behind.
+func (c *Cache) Get(ctx context.Context, key string, now time.Time) ([]byte, bool, error) {
+	var rows []cacheEntryRow
+	err := primary(ctx, c.db).Where("cache_key = ? AND expires_at > ?", key, now).Limit(1).Find(&rows).Error
+	if err != nil || len(rows) == 0 {
+		return nil, false, err
+	}
+	return rows[0].Value, true, nil
+}
+
+// Set stores the value of a key until expiresAt, replacing the previous one. It doesn't join the
+// transaction carried by ctx, if any.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, expiresAt time.Time) error {
+	db := primary(ctx, c.db)
+	if err := c.sweep(db); err != nil {
+		return err
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "cache_key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"value", "expires_at"}),
+	}).Create(&cacheEntryRow{CacheKey: key, Value: value, ExpiresAt: expiresAt}).Error
+}
+
+// Delete removes the keys, missing or not.
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	return primary(ctx, c.db).Where("cache_key IN ?", keys).Delete(&cacheEntryRow{}).Error
+}
+
+// sweep deletes the expired entries once in a while, so that the table doesn't grow with every
+// key ever cached.
+func (c *Cache) sweep(db *gorm.DB) error {
+	c.mu.Lock()
+	c.sets++
+	due := c.sets >= cacheSweepEvery
+	if due {
+		c.sets = 0
+	}
+	c.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return db.Where("expires_at <= ?", time.Now().UTC()).Delete(&cacheEntryRow{}).Error
+}
@@ -0,0 +1,208 @@
+// Package cached caches the reads of the repositories rarely written, in front of any storage.
+package cached
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/goakshit/isildur/platform/metrics"
+	"github.com/google/uuid"
+)
+
+var _ ports.ProductsRepository = (*ProductsRepository)(nil)
+
+const (
+	// productsCache labels the lookups of the products in the metrics.
+	productsCache = "products"
+	// generationKey holds the generation of the catalog the entries are cached for, changed on
+	// every write not to enumerate the entries to invalidate. The entries are prefixed with
+	// productsKeyPrefix and their generation.
+	generationKey     = "products:generation"
+	productsKeyPrefix = "products:"
+	// allProductsKey caches the whole catalog, productKeyPrefix a product by id and
+	// searchesKeyPrefix a search by query.
+	allProductsKey    = "all"
+	productKeyPrefix  = "product:"
+	searchesKeyPrefix = "searches:"
+)
+
+// Tiers of the caches, labelling their lookups in the metrics.
+const (
+	tierLocal  = "local"
+	tierShared = "shared"
+)
+
+// Options configures the caches of a repository.
+type Options struct {
+	// Local is the in-memory cache of the replica, looked up first.
+	Local    ports.Cache
+	LocalTTL time.Duration
+	// Shared, if set, is the cache shared between the replicas, looked up on a local miss.
+	Shared    ports.Cache
+	SharedTTL time.Duration
+	// Metrics counts the hits and misses of every tier, if set.
+	Metrics ports.Metrics
+	// Logger logs the failures of the shared cache, served from the repository instead.
+	Logger ports.Logger
+}
+
+// ProductsRepository caches the products read from a ports.ProductsRepository, invalidated when
+// a product is created through it. The products created elsewhere, e.g. through another replica,
+// are served once the local entries expire.
+type ProductsRepository struct {
+	repo ports.ProductsRepository
+	opts Options
+	now  func() time.Time
+}
+
+// NewProductsRepository creates and returns new ProductsRepository caching the reads of repo.
+func NewProductsRepository(repo ports.ProductsRepository, opts Options) *ProductsRepository {
+	if opts.Logger == nil {
+		opts.Logger = logger.Nop()
+	}
+	if opts.Metrics == nil {
+		opts.Metrics = metrics.Nop()
+	}
+	return &ProductsRepository{
+		repo: repo,
+		opts: opts,
+		now:  func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// GetAll fetches all the products, cached.
+func (pr *ProductsRepository) GetAll(ctx context.Context) (products []domain.Product, err error) {
+	err = pr.cached(ctx, allProductsKey, true, &products, func(ctx context.Context) error {
+		products, err = pr.repo.GetAll(ctx)
+		return err
+	})
+	return products, err
+}
+
+// GetByID fetches product for a given id, cached. The missing products aren't cached.
+func (pr *ProductsRepository) GetByID(ctx context.Context, id uuid.UUID) (product domain.Product, err error) {
+	err = pr.cached(ctx, productKeyPrefix+id.String(), true, &product, func(ctx context.Context) error {
+		product, err = pr.repo.GetByID(ctx, id)
+		return err
+	})
+	return product, err
+}
+
+// Search fetches a page of the products matching the query, cached in memory only. The queries
+// being as many as the clients send, they aren't written to the shared cache.
+func (pr *ProductsRepository) Search(ctx context.Context, query domain.ProductQuery) (products []domain.Product, err error) {
+	spec, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+	err = pr.cached(ctx, searchesKeyPrefix+string(spec), false, &products, func(ctx context.Context) error {
+		products, err = pr.repo.Search(ctx, query)
+		return err
+	})
+	return products, err
+}
+
+// Create is used to create a product, starting a new generation of the cached catalog.
+func (pr *ProductsRepository) Create(ctx context.Context, product domain.Product) error {
+	if err := pr.repo.Create(ctx, product); err != nil {
+		return err
+	}
+	pr.invalidate(ctx, generationKey)
+	return nil
+}
+
+// generation returns the generation of the catalog, starting a new one if it was invalidated.
+// The lookups of the generation aren't counted in the metrics, every search looking it up.
+func (pr *ProductsRepository) generation(ctx context.Context) (string, error) {
+	now := pr.now()
+	value, hit, err := pr.opts.Local.Get(ctx, generationKey, now)
+	if err != nil || hit {
+		return string(value), err
+	}
+	if pr.opts.Shared != nil {
+		value, hit, err = pr.opts.Shared.Get(ctx, generationKey, now)
+		if err != nil {
+			pr.opts.Logger.Warn(ctx, "failed to read the shared cache", "key", generationKey, "error", err)
+		}
+		if !hit {
+			value = []byte(uuid.NewString())
+			if err := pr.opts.Shared.Set(ctx, generationKey, value, now.Add(pr.opts.SharedTTL)); err != nil {
+				pr.opts.Logger.Warn(ctx, "failed to write the shared cache", "key", generationKey, "error", err)
+			}
+		}
+	} else {
+		value = []byte(uuid.NewString())
+	}
+	return string(value), pr.opts.Local.Set(ctx, generationKey, value, now.Add(pr.opts.LocalTTL))
+}
+
+// cached decodes into dst the value cached under key for the current generation of the catalog,
+// or loads it into dst with load and caches it, in the shared cache too if shared. The values
+// loaded before a write aren't read once it starts a new generation, and are loaded from the
+// primary database not to cache the products a lagging replica doesn't have yet. The reads
+// forced on the primary database skip the caches, not to be served stale products.
+func (pr *ProductsRepository) cached(ctx context.Context, key string, shared bool, dst interface{}, load func(context.Context) error) error {
+	if domain.PrimaryReadsFromContext(ctx) {
+		return load(ctx)
+	}
+	generation, err := pr.generation(ctx)
+	if err != nil {
+		return err
+	}
+	key = productsKeyPrefix + generation + ":" + key
+	now := pr.now()
+
+	value, hit, err := pr.opts.Local.Get(ctx, key, now)
+	if err != nil {
+		return err
+	}
+	pr.opts.Metrics.CacheLookup(productsCache, tierLocal, hit)
+	if hit {
+		return json.Unmarshal(value, dst)
+	}
+
+	shared = shared && pr.opts.Shared != nil
+	if shared {
+		value, hit, err = pr.opts.Shared.Get(ctx, key, now)
+		if err != nil {
+			pr.opts.Logger.Warn(ctx, "failed to read the shared cache", "key", key, "error", err)
+		} else {
+			pr.opts.Metrics.CacheLookup(productsCache, tierShared, hit)
+		}
+		if hit {
+			if err := json.Unmarshal(value, dst); err != nil {
+				return err
+			}
+			return pr.opts.Local.Set(ctx, key, value, now.Add(pr.opts.LocalTTL))
+		}
+	}
+
+	if err := load(domain.ContextWithPrimaryReads(ctx)); err != nil {
+		return err
+	}
+	if value, err = json.Marshal(dst); err != nil {
+		return err
+	}
+	if shared {
+		if err := pr.opts.Shared.Set(ctx, key, value, now.Add(pr.opts.SharedTTL)); err != nil {
+			pr.opts.Logger.Warn(ctx, "failed to write the shared cache", "key", key, "error", err)
+		}
+	}
+	return pr.opts.Local.Set(ctx, key, value, now.Add(pr.opts.LocalTTL))
+}
+
+// invalidate removes the keys from every tier. The product being created anyway, a failure of
+// the shared cache is logged, the other replicas serving the catalog cached until it expires.
+func (pr *ProductsRepository) invalidate(ctx context.Context, keys ...string) {
+	if pr.opts.Shared != nil {
+		if err := pr.opts.Shared.Delete(ctx, keys...); err != nil {
+			pr.opts.Logger.Error(ctx, "failed to invalidate the shared cache", "keys", keys, "error", err)
+		}
+	}
+	// The in-memory cache doesn't fail
+	_ = pr.opts.Local.Delete(ctx, keys...)
+}
@@ -0,0 +1,266 @@
+package cached
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/repositories/memory"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type ProductsRepositoryTestSuite struct {
+	suite.Suite
+	repo     *ports.MockProductsRepository
+	metrics  *ports.MockMetrics
+	shared   *memory.Cache
+	now      time.Time
+	products []domain.Product
+}
+
+func TestProductsRepositoryTestSuite(t *testing.T) {
+	suite.Run(t, new(ProductsRepositoryTestSuite))
+}
+
+func (ts *ProductsRepositoryTestSuite) SetupTest() {
+	ctrl := gomock.NewController(ts.T())
+	ts.repo = ports.NewMockProductsRepository(ctrl)
+	ts.metrics = ports.NewMockMetrics(ctrl)
+	ts.shared = memory.NewCache(16)
+	ts.now = time.Date(2022, time.June, 1, 12, 0, 0, 0, time.UTC)
+	ts.products = []domain.Product{
+		{ID: uuid.New(), Name: "YOGA 1", Description: "BASIC YOGA", MonthlyPrice: 5, InstructorName: "A. Dhar"},
+		{ID: uuid.New(), Name: "PILATES 1", MonthlyPrice: 7},
+	}
+}
+
+// replica returns a repository caching the products in its own memory, and in the shared cache
+// if shared.
+func (ts *ProductsRepositoryTestSuite) replica(shared bool) *ProductsRepository {
+	opts := Options{
+		Local:     memory.NewCache(16),
+		LocalTTL:  30 * time.Second,
+		SharedTTL: 10 * time.Minute,
+		Metrics:   ts.metrics,
+	}
+	if shared {
+		opts.Shared = ts.shared
+	}
+	pr := NewProductsRepository(ts.repo, opts)
+	pr.now = func() time.Time { return ts.now }
+	return pr
+}
+
+func (ts *ProductsRepositoryTestSuite) expectLookup(tier string, hit bool) {
+	ts.metrics.EXPECT().CacheLookup(productsCache, tier, hit)
+}
+
+func (ts *ProductsRepositoryTestSuite) TestGetAll_Local() {
+	ctx := context.Background()
+	pr := ts.replica(false)
+	ts.repo.EXPECT().GetAll(gomock.Any()).Return(ts.products, nil).Times(1)
+
+	ts.expectLookup(tierLocal, false)
+	products, err := pr.GetAll(ctx)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(ts.products, products)
+
+	ts.expectLookup(tierLocal, true)
+	products, err = pr.GetAll(ctx)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(ts.products, products)
+
+	// Loaded again once expired
+	ts.now = ts.now.Add(30 * time.Second)
+	ts.repo.EXPECT().GetAll(gomock.Any()).Return(ts.products[:1], nil).Times(1)
+	ts.expectLookup(tierLocal, false)
+	products, err = pr.GetAll(ctx)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(ts.products[:1], products)
+}
+
+func (ts *ProductsRepositoryTestSuite) TestGetAll_Shared() {
+	ctx := context.Background()
+	first, second := ts.replica(true), ts.replica(true)
+	ts.repo.EXPECT().GetAll(gomock.Any()).Return(ts.products, nil).Times(1)
+
+	ts.expectLookup(tierLocal, false)
+	ts.expectLookup(tierShared, false)
+	_, err := first.GetAll(ctx)
+	ts.Require().Nil(err)
+
+	// The second replica reads the products cached by the first one, then from its own memory
+	ts.expectLookup(tierLocal, false)
+	ts.expectLookup(tierShared, true)
+	products, err := second.GetAll(ctx)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(ts.products, products)
+
+	ts.expectLookup(tierLocal, true)
+	products, err = second.GetAll(ctx)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(ts.products, products)
+}
+
+func (ts *ProductsRepositoryTestSuite) TestCreate_Invalidates() {
+	ctx := context.Background()
+	pr := ts.replica(true)
+	product := domain.Product{ID: uuid.New(), Name: "BOXING 1", MonthlyPrice: 9}
+	ts.repo.EXPECT().GetAll(gomock.Any()).Return(ts.products, nil).Times(1)
+	ts.expectLookup(tierLocal, false)
+	ts.expectLookup(tierShared, false)
+	_, err := pr.GetAll(ctx)
+	ts.Require().Nil(err)
+
+	ts.repo.EXPECT().Create(gomock.Any(), product).Return(nil).Times(1)
+	ts.Require().Nil(pr.Create(ctx, product))
+
+	// Both tiers were invalidated
+	created := append(ts.products, product)
+	ts.repo.EXPECT().GetAll(gomock.Any()).Return(created, nil).Times(1)
+	ts.expectLookup(tierLocal, false)
+	ts.expectLookup(tierShared, false)
+	products, err := pr.GetAll(ctx)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(created, products)
+
+	// A failed creation doesn't invalidate anything
+	ts.repo.EXPECT().Create(gomock.Any(), product).Return(errors.New("duplicate key")).Times(1)
+	ts.Assert().EqualError(pr.Create(ctx, product), "duplicate key")
+	ts.expectLookup(tierLocal, true)
+	_, err = pr.GetAll(ctx)
+	ts.Require().Nil(err)
+}
+
+func (ts *ProductsRepositoryTestSuite) TestCreate_RacingRead() {
+	ctx := context.Background()
+	reader, writer, next := ts.replica(true), ts.replica(true), ts.replica(true)
+	product := domain.Product{ID: uuid.New(), Name: "BOXING 1", MonthlyPrice: 9}
+	ts.repo.EXPECT().Create(gomock.Any(), product).Return(nil).Times(1)
+
+	// The product is created while the catalog is loaded, from the primary database
+	ts.repo.EXPECT().GetAll(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]domain.Product, error) {
+		ts.Assert().True(domain.PrimaryReadsFromContext(ctx))
+		ts.Require().Nil(writer.Create(context.Background(), product))
+		return ts.products, nil
+	}).Times(1)
+	ts.expectLookup(tierLocal, false)
+	ts.expectLookup(tierShared, false)
+	products, err := reader.GetAll(ctx)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(ts.products, products)
+
+	// The stale catalog was cached for the previous generation, not served to the next reads
+	created := append(ts.products, product)
+	ts.repo.EXPECT().GetAll(gomock.Any()).Return(created, nil).Times(1)
+	ts.expectLookup(tierLocal, false)
+	ts.expectLookup(tierShared, false)
+	products, err = next.GetAll(ctx)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(created, products)
+}
+
+func (ts *ProductsRepositoryTestSuite) TestNilMetrics() {
+	pr := NewProductsRepository(ts.repo, Options{Local: memory.NewCache(16), LocalTTL: time.Minute})
+	ts.repo.EXPECT().GetAll(gomock.Any()).Return(ts.products, nil).Times(1)
+	for i := 0; i < 2; i++ {
+		products, err := pr.GetAll(context.Background())
+		ts.Require().Nil(err)
+		ts.Assert().Equal(ts.products, products)
+	}
+}
+
+func (ts *ProductsRepositoryTestSuite) TestSearch() {
+	ctx := context.Background()
+	first, second := ts.replica(true), ts.replica(true)
+	query := domain.ProductQuery{Search: "yoga", Limit: 10}
+	ts.repo.EXPECT().Search(gomock.Any(), query).Return(ts.products[:1], nil).Times(2)
+
+	ts.expectLookup(tierLocal, false)
+	products, err := first.Search(ctx, query)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(ts.products[:1], products)
+	ts.expectLookup(tierLocal, true)
+	products, err = first.Search(ctx, query)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(ts.products[:1], products)
+
+	// The searches are only cached in memory, the second replica searching again
+	ts.expectLookup(tierLocal, false)
+	products, err = second.Search(ctx, query)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(ts.products[:1], products)
+
+	// Another query is another search
+	other := domain.ProductQuery{Search: "yoga", Limit: 10, Offset: 10}
+	ts.repo.EXPECT().Search(gomock.Any(), other).Return([]domain.Product{}, nil).Times(1)
+	ts.expectLookup(tierLocal, false)
+	products, err = first.Search(ctx, other)
+	ts.Require().Nil(err)
+	ts.Assert().Empty(products)
+
+	// Creating a product through a replica starts a new generation for both, sharing it
+	product := domain.Product{ID: uuid.New(), Name: "YOGA 2", MonthlyPrice: 9}
+	ts.repo.EXPECT().Create(gomock.Any(), product).Return(nil).Times(1)
+	ts.Require().Nil(second.Create(ctx, product))
+	ts.now = ts.now.Add(30 * time.Second)
+	created := []domain.Product{ts.products[0], product}
+	ts.repo.EXPECT().Search(gomock.Any(), query).Return(created, nil).Times(1)
+	ts.expectLookup(tierLocal, false)
+	products, err = first.Search(ctx, query)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(created, products)
+
+	// Without writing any search to the shared cache
+	generation, hit, err := ts.shared.Get(ctx, generationKey, ts.now)
+	ts.Require().Nil(err)
+	ts.Require().True(hit)
+	spec, err := json.Marshal(query)
+	ts.Require().Nil(err)
+	_, hit, err = ts.shared.Get(ctx, productsKeyPrefix+string(generation)+":"+searchesKeyPrefix+string(spec), ts.now)
+	ts.Require().Nil(err)
+	ts.Assert().False(hit)
+}
+
+func (ts *ProductsRepositoryTestSuite) TestGetByID() {
+	ctx := context.Background()
+	pr := ts.replica(false)
+	product := ts.products[0]
+	ts.repo.EXPECT().GetByID(gomock.Any(), product.ID).Return(product, nil).Times(1)
+
+	ts.expectLookup(tierLocal, false)
+	got, err := pr.GetByID(ctx, product.ID)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(product, got)
+	ts.expectLookup(tierLocal, true)
+	got, err = pr.GetByID(ctx, product.ID)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(product, got)
+
+	// The missing products aren't cached
+	missing := uuid.New()
+	ts.repo.EXPECT().GetByID(gomock.Any(), missing).Return(domain.Product{}, domain.ErrProductNotfound).Times(2)
+	for i := 0; i < 2; i++ {
+		ts.expectLookup(tierLocal, false)
+		_, err = pr.GetByID(ctx, missing)
+		ts.Assert().ErrorIs(err, domain.ErrProductNotfound)
+	}
+}
+
+func (ts *ProductsRepositoryTestSuite) TestPrimaryReads() {
+	ctx := domain.ContextWithPrimaryReads(context.Background())
+	pr := ts.replica(true)
+	// Every read skips the caches, without any lookup
+	ts.repo.EXPECT().GetAll(gomock.Any()).Return(ts.products, nil).Times(2)
+	for i := 0; i < 2; i++ {
+		products, err := pr.GetAll(ctx)
+		ts.Require().Nil(err)
+		ts.Assert().Equal(ts.products, products)
+	}
+}
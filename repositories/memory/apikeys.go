@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/google/uuid"
+)
+
+var _ ports.APIKeysRepository = (*APIKeysRepository)(nil)
+
+// errDuplicateAPIKey is returned when an API key with the same id or prefix already exists.
+var errDuplicateAPIKey = errors.New("api key already exists")
+
+// APIKeysRepository is the in-memory implementation of ports.APIKeysRepository.
+type APIKeysRepository struct {
+	store *Store
+}
+
+// NewAPIKeysRepository creates and returns new APIKeysRepository.
+func NewAPIKeysRepository(store *Store) *APIKeysRepository {
+	return &APIKeysRepository{
+		store: store,
+	}
+}
+
+// Create is used to create an API key in the store.
+func (ar APIKeysRepository) Create(ctx context.Context, key domain.APIKey) error {
+	ar.store.mu.Lock()
+	defer ar.store.mu.Unlock()
+	for _, existing := range ar.store.apiKeys {
+		if existing.ID == key.ID || existing.Prefix == key.Prefix {
+			return errDuplicateAPIKey
+		}
+	}
+	ar.store.apiKeys[key.ID] = copyAPIKey(key)
+	return nil
+}
+
+// GetByPrefix fetches the API key with a given prefix.
+func (ar APIKeysRepository) GetByPrefix(ctx context.Context, prefix string) (domain.APIKey, error) {
+	ar.store.mu.RLock()
+	defer ar.store.mu.RUnlock()
+	for _, key := range ar.store.apiKeys {
+		if key.Prefix == prefix {
+			return copyAPIKey(key), nil
+		}
+	}
+	return domain.APIKey{}, domain.ErrAPIKeyNotfound
+}
+
+// List fetches every API key, ordered by creation date.
+func (ar APIKeysRepository) List(ctx context.Context) ([]domain.APIKey, error) {
+	ar.store.mu.RLock()
+	defer ar.store.mu.RUnlock()
+	keys := make([]domain.APIKey, 0, len(ar.store.apiKeys))
+	for _, key := range ar.store.apiKeys {
+		keys = append(keys, copyAPIKey(key))
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if !keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+		}
+		return keys[i].ID.String() < keys[j].ID.String()
+	})
+	return keys, nil
+}
+
+// Revoke records when an API key got revoked, keeping the first date if already revoked.
+func (ar APIKeysRepository) Revoke(ctx context.Context, id uuid.UUID, at time.Time) error {
+	ar.store.mu.Lock()
+	defer ar.store.mu.Unlock()
+	key, ok := ar.store.apiKeys[id]
+	if !ok {
+		return domain.ErrAPIKeyNotfound
+	}
+	if key.RevokedAt == nil {
+		key.RevokedAt = &at
+		ar.store.apiKeys[id] = key
+	}
+	return nil
+}
+
+// Touch records when an API key was last used.
+func (ar APIKeysRepository) Touch(ctx context.Context, id uuid.UUID, at time.Time) error {
+	ar.store.mu.Lock()
+	defer ar.store.mu.Unlock()
+	key, ok := ar.store.apiKeys[id]
+	if !ok {
+		return domain.ErrAPIKeyNotfound
+	}
+	key.LastUsedAt = &at
+	ar.store.apiKeys[id] = key
+	return nil
+}
+
+// copyAPIKey copies the scopes of a key, so that callers can't alter the stored key.
+func copyAPIKey(key domain.APIKey) domain.APIKey {
+	key.Scopes = append([]domain.Role{}, key.Scopes...)
+	return key
+}
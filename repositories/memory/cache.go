@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/goakshit/isildur/core/ports"
+)
+
+var _ ports.Cache = (*Cache)(nil)
+
+// cacheEntry is an entry of the cache, along with its key to forget it once evicted.
+type cacheEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// Cache is the in-memory implementation of ports.Cache, local to the process. It holds up to
+// size entries, evicting the least recently used one first.
+type Cache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	// recent orders the entries from the most to the least recently used.
+	recent *list.List
+}
+
+// NewCache creates and returns new Cache holding up to size entries.
+func NewCache(size int) *Cache {
+	return &Cache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		recent:  list.New(),
+	}
+}
+
+// Get returns the value of a key, unless it is missing or expired at now.
+func (c *Cache) Get(ctx context.Context, key string, now time.Time) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := elem.Value.(*cacheEntry)
+	if !now.Before(entry.expiresAt) {
+		c.remove(elem)
+		return nil, false, nil
+	}
+	c.recent.MoveToFront(elem)
+	return entry.value, true, nil
+}
+
+// Set stores the value of a key until expiresAt, evicting the least recently used entry when
+// the cache is full.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, expiresAt time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = &cacheEntry{key: key, value: value, expiresAt: expiresAt}
+		c.recent.MoveToFront(elem)
+		return nil
+	}
+	c.entries[key] = c.recent.PushFront(&cacheEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.recent.Len() > c.size {
+		c.remove(c.recent.Back())
+	}
+	return nil
+}
+
+// Delete removes the keys, missing or not.
+func (c *Cache) Delete(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if elem, ok := c.entries[key]; ok {
+			c.remove(elem)
+		}
+	}
+	return nil
+}
+
+func (c *Cache) remove(elem *list.Element) {
+	c.recent.Remove(elem)
+	delete(c.entries, elem.Value.(*cacheEntry).key)
+}
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/repositories"
+	"github.com/goakshit/isildur/repositories/repotest"
+)
+
+func TestMemoryRepositories_Contract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T, products []domain.Product) repositories.Repositories {
+		store := NewStore()
+		store.Seed(Fixtures{Products: products})
+		return NewRepositories(store)
+	})
+}
+
+func TestStore_LoadFixtures(t *testing.T) {
+	store := NewStore()
+	if err := store.LoadFixtures("../../build/fixtures/fixtures.json"); err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	if len(store.products) != 2 || len(store.subscriptions) != 1 {
+		t.Fatalf("unexpected fixtures loaded: %d products, %d subscriptions",
+			len(store.products), len(store.subscriptions))
+	}
+}
+
+func TestMemoryRateLimiter_Contract(t *testing.T) {
+	repotest.RunRateLimiter(t, func(t *testing.T) ports.RateLimiter {
+		return NewRateLimiter()
+	})
+}
+
+func TestMemoryRateLimiter_Sweep(t *testing.T) {
+	rl := NewRateLimiter()
+	limit := domain.RateLimit{Requests: 1, Per: time.Minute}
+	start := time.Date(2022, time.June, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < rateLimitSweepEvery-1; i++ {
+		if _, err := rl.Allow(context.Background(), fmt.Sprintf("client-%d", i), limit, start); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if len(rl.buckets) != rateLimitSweepEvery-1 {
+		t.Fatalf("expected %d buckets, got %d", rateLimitSweepEvery-1, len(rl.buckets))
+	}
+	// Every bucket is full again a minute later, only the new one is kept
+	if _, err := rl.Allow(context.Background(), "client-new", limit, start.Add(time.Minute)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(rl.buckets) != 1 {
+		t.Fatalf("expected the full buckets to be swept, %d left", len(rl.buckets))
+	}
+}
+
+func TestMemoryCache_Contract(t *testing.T) {
+	repotest.RunCache(t, func(t *testing.T) ports.Cache {
+		return NewCache(16)
+	})
+}
+
+func TestMemoryCache_Eviction(t *testing.T) {
+	ctx := context.Background()
+	cache := NewCache(2)
+	expiresAt := time.Date(2022, time.June, 1, 12, 0, 0, 0, time.UTC)
+	now := expiresAt.Add(-time.Minute)
+	for _, key := range []string{"a", "b"} {
+		if err := cache.Set(ctx, key, []byte(key), expiresAt); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// Reading a makes b the least recently used entry, evicted for c
+	if _, ok, _ := cache.Get(ctx, "a", now); !ok {
+		t.Fatalf("expected a to be cached")
+	}
+	if err := cache.Set(ctx, "c", []byte("c"), expiresAt); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cache.recent.Len() != 2 {
+		t.Fatalf("expected 2 entries, got %d", cache.recent.Len())
+	}
+	for key, expected := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok, _ := cache.Get(ctx, key, now); ok != expected {
+			t.Fatalf("expected %s cached: %v, got %v", key, expected, ok)
+		}
+	}
+}
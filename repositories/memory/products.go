@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/google/uuid"
+)
+
+var _ ports.ProductsRepository = (*ProductsRepository)(nil)
+
+// errDuplicateProduct is returned when a product with the same id already exists.
+var errDuplicateProduct = errors.New("product already exists")
+
+// ProductsRepository is the in-memory implementation of ports.ProductsRepository.
+type ProductsRepository struct {
+	store *Store
+}
+
+// NewProductsRepository creates and returns new ProductsRepository.
+func NewProductsRepository(store *Store) *ProductsRepository {
+	return &ProductsRepository{
+		store: store,
+	}
+}
+
+// GetByID returns product by id from the store.
+func (pr ProductsRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.Product, error) {
+	pr.store.mu.RLock()
+	defer pr.store.mu.RUnlock()
+	product, ok := pr.store.products[id]
+	if !ok {
+		return domain.Product{}, domain.ErrProductNotfound
+	}
+	return product, nil
+}
+
+// GetAll fetches all the products in the store, ordered by name.
+func (pr ProductsRepository) GetAll(ctx context.Context) ([]domain.Product, error) {
+	pr.store.mu.RLock()
+	defer pr.store.mu.RUnlock()
+	products := make([]domain.Product, 0, len(pr.store.products))
+	for _, p := range pr.store.products {
+		products = append(products, p)
+	}
+	sort.Slice(products, func(i, j int) bool {
+		if products[i].Name != products[j].Name {
+			return products[i].Name < products[j].Name
+		}
+		return products[i].ID.String() < products[j].ID.String()
+	})
+	return products, nil
+}
+
+// Search fetches a page of the products matching the query. Every term searched must appear in
+// the name or the description, the products being ordered by name unless sorted otherwise.
+func (pr ProductsRepository) Search(ctx context.Context, query domain.ProductQuery) ([]domain.Product, error) {
+	if query.Sort != "" && query.Sort != domain.ProductSortName && query.Sort != domain.ProductSortPrice {
+		return nil, fmt.Errorf("%w: unknown sort %q", domain.ErrInvalidQuery, query.Sort)
+	}
+	all, err := pr.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	terms := strings.Fields(strings.ToLower(query.Search))
+	products := []domain.Product{}
+	for _, p := range all {
+		if matchesProduct(p, query, terms) {
+			products = append(products, p)
+		}
+	}
+	if query.Sort == domain.ProductSortPrice {
+		sort.SliceStable(products, func(i, j int) bool {
+			if products[i].MonthlyPrice != products[j].MonthlyPrice {
+				return (products[i].MonthlyPrice < products[j].MonthlyPrice) != query.Descending
+			}
+			return products[i].ID.String() < products[j].ID.String()
+		})
+	} else if query.Sort == domain.ProductSortName && query.Descending {
+		sort.SliceStable(products, func(i, j int) bool {
+			if products[i].Name != products[j].Name {
+				return products[i].Name > products[j].Name
+			}
+			return products[i].ID.String() < products[j].ID.String()
+		})
+	}
+	return paginateProducts(products, query.Limit, query.Offset), nil
+}
+
+// matchesProduct reports whether a product matches the filters of a query and every term
+// searched.
+func matchesProduct(p domain.Product, query domain.ProductQuery, terms []string) bool {
+	if query.Instructor != "" && !strings.EqualFold(p.InstructorName, query.Instructor) {
+		return false
+	}
+	if (query.MinPrice > 0 && p.MonthlyPrice < query.MinPrice) ||
+		(query.MaxPrice > 0 && p.MonthlyPrice > query.MaxPrice) {
+		return false
+	}
+	name, description := strings.ToLower(p.Name), strings.ToLower(p.Description)
+	for _, term := range terms {
+		if !strings.Contains(name, term) && !strings.Contains(description, term) {
+			return false
+		}
+	}
+	return true
+}
+
+// paginateProducts returns the page of products selected by limit and offset.
+func paginateProducts(products []domain.Product, limit, offset int) []domain.Product {
+	if offset >= len(products) {
+		return []domain.Product{}
+	}
+	products = products[offset:]
+	if limit > 0 && limit < len(products) {
+		products = products[:limit]
+	}
+	return products
+}
+
+// Create is used to create a product in the store.
+func (pr ProductsRepository) Create(ctx context.Context, product domain.Product) error {
+	pr.store.mu.Lock()
+	defer pr.store.mu.Unlock()
+	if _, ok := pr.store.products[product.ID]; ok {
+		return errDuplicateProduct
+	}
+	pr.store.products[product.ID] = product
+	return nil
+}
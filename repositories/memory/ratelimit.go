@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+)
+
+var _ ports.RateLimiter = (*RateLimiter)(nil)
+
+// rateLimitSweepEvery is the number of requests between two sweeps of the full buckets.
+const rateLimitSweepEvery = 1024
+
+// limitedBucket is a token bucket along with the limit it was last used with.
+type limitedBucket struct {
+	domain.TokenBucket
+	limit domain.RateLimit
+}
+
+// RateLimiter is the in-memory implementation of ports.RateLimiter. The buckets are local to
+// the process, so every replica limits the clients on its own.
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]limitedBucket
+	calls   int
+}
+
+// NewRateLimiter creates and returns new RateLimiter.
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		buckets: make(map[string]limitedBucket),
+	}
+}
+
+// Allow takes a token at now from the bucket of a key, created full for the limit if missing.
+func (rl *RateLimiter) Allow(
+	ctx context.Context,
+	key string,
+	limit domain.RateLimit,
+	now time.Time,
+) (domain.RateLimitResult, error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	rl.sweep(now)
+
+	bucket, ok := rl.buckets[key]
+	if !ok {
+		bucket.TokenBucket = limit.NewBucket(now)
+	}
+	next, result := limit.Take(bucket.TokenBucket, now)
+	rl.buckets[key] = limitedBucket{TokenBucket: next, limit: limit}
+	return result, nil
+}
+
+// sweep forgets the buckets full again once in a while, a full bucket being the same as a
+// missing one, so that the memory used doesn't grow with every client ever seen.
+func (rl *RateLimiter) sweep(now time.Time) {
+	rl.calls++
+	if rl.calls < rateLimitSweepEvery {
+		return
+	}
+	rl.calls = 0
+	for key, bucket := range rl.buckets {
+		if bucket.limit.Full(bucket.TokenBucket, now) {
+			delete(rl.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/google/uuid"
+)
+
+var _ ports.ReportsRepository = (*ReportsRepository)(nil)
+
+// ReportsRepository is the in-memory implementation of ports.ReportsRepository.
+type ReportsRepository struct {
+	store *Store
+}
+
+// NewReportsRepository creates and returns new ReportsRepository.
+func NewReportsRepository(store *Store) *ReportsRepository {
+	return &ReportsRepository{
+		store: store,
+	}
+}
+
+// Revenue sums the monthly amount, before tax, of the subscriptions active at asOf per product.
+func (rr ReportsRepository) Revenue(ctx context.Context, asOf time.Time) ([]domain.ProductRevenue, error) {
+	rr.store.mu.RLock()
+	defer rr.store.mu.RUnlock()
+	byProduct := map[uuid.UUID]*domain.ProductRevenue{}
+	for _, sub := range rr.store.subscriptions {
+		if !activeAt(sub, asOf) {
+			continue
+		}
+		revenue, ok := byProduct[sub.ProductID]
+		if !ok {
+			revenue = &domain.ProductRevenue{
+				ProductID:   sub.ProductID,
+				ProductName: rr.store.products[sub.ProductID].Name,
+			}
+			byProduct[sub.ProductID] = revenue
+		}
+		revenue.ActiveSubscriptions++
+		revenue.MRR += (sub.TotalCost - sub.Tax) / float64(sub.DurationInMonths)
+	}
+
+	revenues := make([]domain.ProductRevenue, 0, len(byProduct))
+	for _, revenue := range byProduct {
+		revenues = append(revenues, *revenue)
+	}
+	sort.Slice(revenues, func(i, j int) bool {
+		return productLess(revenues[i].ProductName, revenues[i].ProductID, revenues[j].ProductName, revenues[j].ProductID)
+	})
+	return revenues, nil
+}
+
+// ActiveCustomers counts the distinct customers with a subscription active at asOf.
+func (rr ReportsRepository) ActiveCustomers(ctx context.Context, asOf time.Time) (int, error) {
+	rr.store.mu.RLock()
+	defer rr.store.mu.RUnlock()
+	customers := map[string]struct{}{}
+	for _, sub := range rr.store.subscriptions {
+		if sub.CustomerID != "" && activeAt(sub, asOf) {
+			customers[sub.CustomerID] = struct{}{}
+		}
+	}
+	return len(customers), nil
+}
+
+// Activity counts the subscriptions started, cancelled and paused in [from, to) per product.
+func (rr ReportsRepository) Activity(ctx context.Context, from, to time.Time) ([]domain.ProductActivity, error) {
+	rr.store.mu.RLock()
+	defer rr.store.mu.RUnlock()
+	within := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(to)
+	}
+	byProduct := map[uuid.UUID]*domain.ProductActivity{}
+	for _, sub := range rr.store.subscriptions {
+		started := within(sub.StartDate)
+		churned := sub.CancelledAt != nil && within(*sub.CancelledAt)
+		paused := sub.PausedAt != nil && within(*sub.PausedAt)
+		if !started && !churned && !paused {
+			continue
+		}
+		activity, ok := byProduct[sub.ProductID]
+		if !ok {
+			activity = &domain.ProductActivity{
+				ProductID:   sub.ProductID,
+				ProductName: rr.store.products[sub.ProductID].Name,
+			}
+			byProduct[sub.ProductID] = activity
+		}
+		if started {
+			activity.New++
+		}
+		if churned {
+			activity.Churned++
+		}
+		if paused {
+			activity.Paused++
+		}
+	}
+
+	activities := make([]domain.ProductActivity, 0, len(byProduct))
+	for _, activity := range byProduct {
+		activities = append(activities, *activity)
+	}
+	sort.Slice(activities, func(i, j int) bool {
+		return productLess(activities[i].ProductName, activities[i].ProductID, activities[j].ProductName, activities[j].ProductID)
+	})
+	return activities, nil
+}
+
+// activeAt reports whether a subscription runs at a date, neither cancelled nor paused by then,
+// like the gorm repository. Cancelled or paused subscriptions without a date are never active.
+func activeAt(sub domain.Subscription, at time.Time) bool {
+	if sub.StartDate.After(at) || !sub.EndDate.After(at) {
+		return false
+	}
+	if sub.Status == domain.SubscriptionStatusCancel && (sub.CancelledAt == nil || !sub.CancelledAt.After(at)) {
+		return false
+	}
+	if sub.PausedAt == nil {
+		return sub.Status != domain.SubscriptionStatusPaused
+	}
+	if sub.PausedAt.After(at) {
+		return true
+	}
+	// Paused by then, unless resumed since
+	return sub.ResumedAt != nil && sub.ResumedAt.After(*sub.PausedAt) && !sub.ResumedAt.After(at)
+}
+
+// productLess orders report rows by product name then id, like the gorm repository.
+func productLess(nameI string, idI uuid.UUID, nameJ string, idJ uuid.UUID) bool {
+	if nameI != nameJ {
+		return nameI < nameJ
+	}
+	return idI.String() < idJ.String()
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/google/uuid"
+)
+
+var _ ports.RevenueEntriesRepository = (*RevenueEntriesRepository)(nil)
+
+// errDuplicateRevenueEntry is returned when a revenue entry with the same id already exists.
+var errDuplicateRevenueEntry = errors.New("revenue entry already exists")
+
+// RevenueEntriesRepository is the in-memory implementation of ports.RevenueEntriesRepository.
+type RevenueEntriesRepository struct {
+	store *Store
+}
+
+// NewRevenueEntriesRepository creates and returns new RevenueEntriesRepository.
+func NewRevenueEntriesRepository(store *Store) *RevenueEntriesRepository {
+	return &RevenueEntriesRepository{
+		store: store,
+	}
+}
+
+// CreateBatch is used to create several revenue entries in the store at once.
+// Either all of them are created or none.
+func (rr RevenueEntriesRepository) CreateBatch(ctx context.Context, entries []domain.RevenueEntry) error {
+	rr.store.mu.Lock()
+	defer rr.store.mu.Unlock()
+	seen := make(map[uuid.UUID]struct{}, len(entries))
+	for _, entry := range entries {
+		if _, ok := rr.store.revenueEntries[entry.ID]; ok {
+			return errDuplicateRevenueEntry
+		}
+		if _, ok := seen[entry.ID]; ok {
+			return errDuplicateRevenueEntry
+		}
+		seen[entry.ID] = struct{}{}
+	}
+	for _, entry := range entries {
+		rr.store.revenueEntries[entry.ID] = entry
+	}
+	return nil
+}
+
+// ListBySubscription fetches the revenue entries of a subscription, ordered by month.
+func (rr RevenueEntriesRepository) ListBySubscription(ctx context.Context, subscriptionID uuid.UUID) ([]domain.RevenueEntry, error) {
+	rr.store.mu.RLock()
+	defer rr.store.mu.RUnlock()
+	entries := []domain.RevenueEntry{}
+	for _, entry := range rr.store.revenueEntries {
+		if entry.SubscriptionID == subscriptionID {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Month < entries[j].Month
+	})
+	return entries, nil
+}
+
+// Update saves the status and the dates of the given revenue entries.
+// Unknown entries are ignored, like the gorm repository.
+func (rr RevenueEntriesRepository) Update(ctx context.Context, entries []domain.RevenueEntry) error {
+	rr.store.mu.Lock()
+	defer rr.store.mu.Unlock()
+	for _, entry := range entries {
+		stored, ok := rr.store.revenueEntries[entry.ID]
+		if !ok {
+			continue
+		}
+		stored.Status = entry.Status
+		stored.PeriodStart = entry.PeriodStart
+		stored.PeriodEnd = entry.PeriodEnd
+		stored.RecognizeOn = entry.RecognizeOn
+		stored.RefundedOn = entry.RefundedOn
+		rr.store.revenueEntries[entry.ID] = stored
+	}
+	return nil
+}
+
+// PeriodClose sums per product the revenue recognized and refunded in [from, to), and the
+// revenue booked before to but still deferred at to.
+func (rr RevenueEntriesRepository) PeriodClose(ctx context.Context, from, to time.Time) ([]domain.ProductRecognition, error) {
+	rr.store.mu.RLock()
+	defer rr.store.mu.RUnlock()
+	within := func(t time.Time) bool {
+		return !t.Before(from) && t.Before(to)
+	}
+	byProduct := map[uuid.UUID]*domain.ProductRecognition{}
+	for _, entry := range rr.store.revenueEntries {
+		if !entry.BookedOn.Before(to) {
+			continue
+		}
+		recognition, ok := byProduct[entry.ProductID]
+		if !ok {
+			recognition = &domain.ProductRecognition{
+				ProductID:   entry.ProductID,
+				ProductName: rr.store.products[entry.ProductID].Name,
+			}
+			byProduct[entry.ProductID] = recognition
+		}
+		switch entry.Status {
+		case domain.RevenueEntryScheduled:
+			if within(entry.RecognizeOn) {
+				recognition.Recognized += entry.Amount
+			} else if !entry.RecognizeOn.Before(to) {
+				recognition.Deferred += entry.Amount
+			}
+		case domain.RevenueEntrySuspended:
+			recognition.Deferred += entry.Amount
+		case domain.RevenueEntryRefunded:
+			if entry.RefundedOn == nil {
+				continue
+			}
+			if within(*entry.RefundedOn) {
+				recognition.Refunded += entry.Amount
+			} else if !entry.RefundedOn.Before(to) {
+				recognition.Deferred += entry.Amount
+			}
+		}
+	}
+
+	recognitions := make([]domain.ProductRecognition, 0, len(byProduct))
+	for _, recognition := range byProduct {
+		recognitions = append(recognitions, *recognition)
+	}
+	sort.Slice(recognitions, func(i, j int) bool {
+		return productLess(recognitions[i].ProductName, recognitions[i].ProductID, recognitions[j].ProductName, recognitions[j].ProductID)
+	})
+	return recognitions, nil
+}
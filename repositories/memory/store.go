@@ -0,0 +1,109 @@
+// Package memory holds thread-safe in-memory implementations of the storage ports,
+// meant for local development and tests.
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/repositories"
+	"github.com/google/uuid"
+)
+
+// Store holds the in-memory tables shared by the repositories.
+type Store struct {
+	mu             sync.RWMutex
+	products       map[uuid.UUID]domain.Product
+	subscriptions  map[uuid.UUID]domain.Subscription
+	revenueEntries map[uuid.UUID]domain.RevenueEntry
+	apiKeys        map[uuid.UUID]domain.APIKey
+}
+
+// NewStore creates and returns an empty Store.
+func NewStore() *Store {
+	return &Store{
+		products:       make(map[uuid.UUID]domain.Product),
+		subscriptions:  make(map[uuid.UUID]domain.Subscription),
+		revenueEntries: make(map[uuid.UUID]domain.RevenueEntry),
+		apiKeys:        make(map[uuid.UUID]domain.APIKey),
+	}
+}
+
+// NewRepositories returns repositories backed by the given store.
+// The in-memory storage doesn't support transactions, so a no-op TxManager is used.
+func NewRepositories(store *Store) repositories.Repositories {
+	return repositories.Repositories{
+		Products:       NewProductsRepository(store),
+		Subscriptions:  NewSubscriptionsRepository(store),
+		Reports:        NewReportsRepository(store),
+		RevenueEntries: NewRevenueEntriesRepository(store),
+		APIKeys:        NewAPIKeysRepository(store),
+		Tx:             repositories.NoopTxManager{},
+	}
+}
+
+// Fixtures represents the structure of the fixtures file used to seed the store.
+type Fixtures struct {
+	Products      []domain.Product      `json:"products"`
+	Subscriptions []SubscriptionFixture `json:"subscriptions"`
+}
+
+// SubscriptionFixture represents a subscription entry in the fixtures file.
+type SubscriptionFixture struct {
+	ID               uuid.UUID                 `json:"id"`
+	ProductID        uuid.UUID                 `json:"product_id"`
+	CustomerID       string                    `json:"customer_id"`
+	DurationInMonths int8                      `json:"duration_in_months"`
+	Tax              float64                   `json:"tax"`
+	TotalCost        float64                   `json:"total_cost"`
+	Status           domain.SubscriptionStatus `json:"status"`
+	StartDate        time.Time                 `json:"start_date"`
+	EndDate          time.Time                 `json:"end_date"`
+	CancelledAt      *time.Time                `json:"cancelled_at"`
+	PausedAt         *time.Time                `json:"paused_at"`
+	ResumedAt        *time.Time                `json:"resumed_at"`
+}
+
+// LoadFixtures seeds the store with the fixtures from a JSON file.
+func (s *Store) LoadFixtures(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return fmt.Errorf("failed to parse fixtures file %s: %w", path, err)
+	}
+	s.Seed(fixtures)
+	return nil
+}
+
+// Seed adds the given fixtures to the store, replacing entities with the same id.
+func (s *Store) Seed(fixtures Fixtures) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range fixtures.Products {
+		s.products[p.ID] = p
+	}
+	for _, f := range fixtures.Subscriptions {
+		s.subscriptions[f.ID] = domain.Subscription{
+			ID:               f.ID,
+			ProductID:        f.ProductID,
+			CustomerID:       f.CustomerID,
+			DurationInMonths: f.DurationInMonths,
+			Tax:              f.Tax,
+			TotalCost:        f.TotalCost,
+			Status:           f.Status,
+			StartDate:        f.StartDate,
+			EndDate:          f.EndDate,
+			Version:          1,
+			CancelledAt:      f.CancelledAt,
+			PausedAt:         f.PausedAt,
+			ResumedAt:        f.ResumedAt,
+		}
+	}
+}
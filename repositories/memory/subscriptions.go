@@ -0,0 +1,240 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/google/uuid"
+)
+
+var _ ports.SubscriptionsRepository = (*SubscriptionsRepository)(nil)
+
+// errDuplicateSubscription is returned when a subscription with the same id already exists.
+var errDuplicateSubscription = errors.New("subscription already exists")
+
+// SubscriptionsRepository is the in-memory implementation of ports.SubscriptionsRepository.
+type SubscriptionsRepository struct {
+	store *Store
+}
+
+// NewSubscriptionsRepository creates and returns new SubscriptionsRepository.
+func NewSubscriptionsRepository(store *Store) *SubscriptionsRepository {
+	return &SubscriptionsRepository{
+		store: store,
+	}
+}
+
+// GetByID fetches subscription for a given id.
+func (sr SubscriptionsRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.Subscription, error) {
+	sr.store.mu.RLock()
+	defer sr.store.mu.RUnlock()
+	sub, ok := sr.store.subscriptions[id]
+	if !ok {
+		return domain.Subscription{}, domain.ErrSubscriptionNotfound
+	}
+	return sub, nil
+}
+
+// List fetches the subscriptions matching the filter, ordered by start date.
+func (sr SubscriptionsRepository) List(ctx context.Context, filter domain.SubscriptionFilter) ([]domain.Subscription, error) {
+	sr.store.mu.RLock()
+	defer sr.store.mu.RUnlock()
+	subscriptions := []domain.Subscription{}
+	for _, sub := range sr.store.subscriptions {
+		if matchesFilter(sub, filter) {
+			subscriptions = append(subscriptions, sub)
+		}
+	}
+	sortSubscriptions(subscriptions)
+	return paginate(subscriptions, filter.Limit, filter.Offset), nil
+}
+
+// Stream calls fn for every subscription matching the filter, joined with its product.
+// The matching subscriptions are copied first, so that fn runs without holding the lock.
+func (sr SubscriptionsRepository) Stream(
+	ctx context.Context,
+	filter domain.SubscriptionFilter,
+	fn func(domain.SubscriptionExportRow) error,
+) error {
+	subscriptions, err := sr.List(ctx, filter)
+	if err != nil {
+		return err
+	}
+	sr.store.mu.RLock()
+	rows := make([]domain.SubscriptionExportRow, 0, len(subscriptions))
+	for _, sub := range subscriptions {
+		product := sr.store.products[sub.ProductID]
+		rows = append(rows, domain.SubscriptionExportRow{
+			ID:                  sub.ID,
+			ProductID:           sub.ProductID,
+			ProductName:         product.Name,
+			ProductMonthlyPrice: product.MonthlyPrice,
+			CustomerID:          sub.CustomerID,
+			DurationInMonths:    sub.DurationInMonths,
+			Tax:                 sub.Tax,
+			TotalCost:           sub.TotalCost,
+			Status:              sub.Status,
+			StartDate:           sub.StartDate,
+			EndDate:             sub.EndDate,
+			Version:             sub.Version,
+		})
+	}
+	sr.store.mu.RUnlock()
+
+	for _, row := range rows {
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Create is used to create a subscription in the store.
+func (sr SubscriptionsRepository) Create(ctx context.Context, sub domain.Subscription) error {
+	sr.store.mu.Lock()
+	defer sr.store.mu.Unlock()
+	if _, ok := sr.store.subscriptions[sub.ID]; ok {
+		return errDuplicateSubscription
+	}
+	if sub.Version == 0 {
+		sub.Version = 1
+	}
+	sr.store.subscriptions[sub.ID] = sub
+	return nil
+}
+
+// CreateBatch is used to create several subscriptions in the store at once.
+// Either all of them are created or none.
+func (sr SubscriptionsRepository) CreateBatch(ctx context.Context, subs []domain.Subscription) error {
+	sr.store.mu.Lock()
+	defer sr.store.mu.Unlock()
+	seen := make(map[uuid.UUID]struct{}, len(subs))
+	for _, sub := range subs {
+		if _, ok := sr.store.subscriptions[sub.ID]; ok {
+			return errDuplicateSubscription
+		}
+		if _, ok := seen[sub.ID]; ok {
+			return errDuplicateSubscription
+		}
+		seen[sub.ID] = struct{}{}
+	}
+	for _, sub := range subs {
+		if sub.Version == 0 {
+			sub.Version = 1
+		}
+		sr.store.subscriptions[sub.ID] = sub
+	}
+	return nil
+}
+
+// Patch updates the data in subscription for a given id, if it is still at the
+// expected version. The version is incremented as part of the same update.
+func (sr SubscriptionsRepository) Patch(ctx context.Context, id uuid.UUID, version int, update map[string]interface{}) error {
+	sr.store.mu.Lock()
+	defer sr.store.mu.Unlock()
+	sub, ok := sr.store.subscriptions[id]
+	if !ok {
+		return domain.ErrSubscriptionNotfound
+	}
+	if sub.Version != version {
+		return domain.ErrSubscriptionVersionMismatch
+	}
+	for column, value := range update {
+		if err := setSubscriptionColumn(&sub, column, value); err != nil {
+			return err
+		}
+	}
+	sub.Version++
+	sr.store.subscriptions[id] = sub
+	return nil
+}
+
+// setSubscriptionColumn sets the field matching a db column name, mirroring gorm updates.
+func setSubscriptionColumn(sub *domain.Subscription, column string, value interface{}) error {
+	var ok bool
+	switch column {
+	case "status":
+		switch v := value.(type) {
+		case domain.SubscriptionStatus:
+			sub.Status, ok = v, true
+		case string:
+			sub.Status, ok = domain.SubscriptionStatus(v), true
+		}
+	case "duration_in_months":
+		sub.DurationInMonths, ok = value.(int8)
+	case "tax":
+		sub.Tax, ok = value.(float64)
+	case "total_cost":
+		sub.TotalCost, ok = value.(float64)
+	case "start_date":
+		sub.StartDate, ok = value.(time.Time)
+	case "end_date":
+		sub.EndDate, ok = value.(time.Time)
+	case "cancelled_at":
+		sub.CancelledAt, ok = timeColumnValue(value)
+	case "paused_at":
+		sub.PausedAt, ok = timeColumnValue(value)
+	case "resumed_at":
+		sub.ResumedAt, ok = timeColumnValue(value)
+	default:
+		return fmt.Errorf("unknown subscription column %q", column)
+	}
+	if !ok {
+		return fmt.Errorf("invalid value %v for subscription column %q", value, column)
+	}
+	return nil
+}
+
+// timeColumnValue converts the value of a nullable time column, nil clearing it.
+func timeColumnValue(value interface{}) (*time.Time, bool) {
+	switch v := value.(type) {
+	case nil:
+		return nil, true
+	case time.Time:
+		return &v, true
+	case *time.Time:
+		return v, true
+	}
+	return nil, false
+}
+
+// matchesFilter checks a subscription against the non zero criteria of the filter.
+func matchesFilter(sub domain.Subscription, filter domain.SubscriptionFilter) bool {
+	if filter.ProductID != uuid.Nil && sub.ProductID != filter.ProductID {
+		return false
+	}
+	if filter.CustomerID != "" && sub.CustomerID != filter.CustomerID {
+		return false
+	}
+	if filter.Status != "" && sub.Status != filter.Status {
+		return false
+	}
+	return true
+}
+
+// sortSubscriptions orders subscriptions by start date then id, like the gorm repository.
+func sortSubscriptions(subscriptions []domain.Subscription) {
+	sort.Slice(subscriptions, func(i, j int) bool {
+		if !subscriptions[i].StartDate.Equal(subscriptions[j].StartDate) {
+			return subscriptions[i].StartDate.Before(subscriptions[j].StartDate)
+		}
+		return subscriptions[i].ID.String() < subscriptions[j].ID.String()
+	})
+}
+
+// paginate returns the page of subscriptions selected by limit and offset.
+func paginate(subscriptions []domain.Subscription, limit, offset int) []domain.Subscription {
+	if offset >= len(subscriptions) {
+		return []domain.Subscription{}
+	}
+	subscriptions = subscriptions[offset:]
+	if limit > 0 && limit < len(subscriptions) {
+		subscriptions = subscriptions[:limit]
+	}
+	return subscriptions
+}
@@ -3,11 +3,14 @@ package repositories
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/goakshit/isildur/core/domain"
 	"github.com/goakshit/isildur/core/ports"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var _ ports.ProductsRepository = (*ProductsRepository)(nil)
@@ -27,7 +30,7 @@ func NewProductsRepository(db *gorm.DB) *ProductsRepository {
 // GetByID returns product by id from db.
 func (cr ProductsRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.Product, error) {
 	var product domain.Product
-	result := cr.db.WithContext(ctx).Where(domain.Product{
+	result := conn(ctx, cr.db).Where(domain.Product{
 		ID: id,
 	}).First(&product)
 	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -39,6 +42,79 @@ func (cr ProductsRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.
 // GetAll fetches all the products in the database.
 func (cr ProductsRepository) GetAll(ctx context.Context) ([]domain.Product, error) {
 	var products []domain.Product
-	result := cr.db.WithContext(ctx).Find(&products)
+	result := conn(ctx, cr.db).Find(&products)
 	return products, result.Error
 }
+
+// productSearchVector is the text searched in the products on postgres, matching the expression
+// of the product_search_idx index.
+const productSearchVector = "to_tsvector('english', coalesce(name, '') || ' ' || coalesce(description, ''))"
+
+// productSortColumns maps the sorts of the products to their column.
+var productSortColumns = map[domain.ProductSort]string{
+	domain.ProductSortName:  "name",
+	domain.ProductSortPrice: "monthly_price",
+}
+
+// Search fetches a page of the products matching the query. On postgres the search is a full
+// text search of the name and the description, ranked by relevance unless sorted otherwise.
+// Elsewhere every term must appear in the name or the description.
+func (cr ProductsRepository) Search(ctx context.Context, query domain.ProductQuery) ([]domain.Product, error) {
+	products := []domain.Product{}
+	db := conn(ctx, cr.db).Model(&domain.Product{})
+	fullText := db.Dialector.Name() == "postgres"
+
+	if query.Search != "" {
+		if fullText {
+			db = db.Where(productSearchVector+" @@ websearch_to_tsquery('english', ?)", query.Search)
+		} else {
+			for _, term := range strings.Fields(strings.ToLower(query.Search)) {
+				pattern := "%" + escapeLike(term) + "%"
+				db = db.Where(`(lower(name) LIKE ? ESCAPE '\' OR lower(description) LIKE ? ESCAPE '\')`, pattern, pattern)
+			}
+		}
+	}
+	if query.Instructor != "" {
+		db = db.Where("lower(instructor_name) = lower(?)", query.Instructor)
+	}
+	if query.MinPrice > 0 {
+		db = db.Where("monthly_price >= ?", query.MinPrice)
+	}
+	if query.MaxPrice > 0 {
+		db = db.Where("monthly_price <= ?", query.MaxPrice)
+	}
+
+	column, sorted := productSortColumns[query.Sort]
+	if query.Sort != "" && !sorted {
+		return nil, fmt.Errorf("%w: unknown sort %q", domain.ErrInvalidQuery, query.Sort)
+	}
+	switch {
+	case sorted:
+		db = db.Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: query.Descending}).
+			Order("id")
+	case query.Search != "" && fullText:
+		db = db.Clauses(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "ts_rank(" + productSearchVector + ", websearch_to_tsquery('english', ?)) DESC, name, id",
+			Vars: []interface{}{query.Search},
+		}})
+	default:
+		db = db.Order("name, id")
+	}
+	if query.Limit > 0 {
+		db = db.Limit(query.Limit)
+	}
+	if query.Offset > 0 {
+		db = db.Offset(query.Offset)
+	}
+	return products, db.Find(&products).Error
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// Create is used to create a product in the db.
+func (cr ProductsRepository) Create(ctx context.Context, product domain.Product) error {
+	return conn(ctx, cr.db).Create(&product).Error
+}
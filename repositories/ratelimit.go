@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var _ ports.RateLimiter = (*RateLimiter)(nil)
+
+const (
+	// rateLimitMaxAttempts is the number of times a bucket update is retried when another
+	// replica updated the same bucket concurrently.
+	rateLimitMaxAttempts = 5
+	// rateLimitSweepEvery is the number of requests between two deletions of the full buckets.
+	rateLimitSweepEvery = 1024
+)
+
+// errRateLimitContention is returned when a bucket couldn't be updated after several attempts.
+var errRateLimitContention = errors.New("rate limit bucket updated concurrently")
+
+// rateLimitBucketRow is the rate_limit_bucket table row. The version is incremented on every
+// update, so that concurrent updates from several replicas don't lose tokens. FullAt is when
+// the bucket is full again, from which on it can be deleted.
+type rateLimitBucketRow struct {
+	BucketKey  string `gorm:"primaryKey"`
+	Tokens     float64
+	RefilledAt time.Time
+	FullAt     time.Time
+	Version    int64
+}
+
+func (rateLimitBucketRow) TableName() string {
+	return "rate_limit_bucket"
+}
+
+// RateLimiter is the gorm implementation of ports.RateLimiter, sharing the buckets between
+// the replicas through the database.
+type RateLimiter struct {
+	db *gorm.DB
+
+	mu    sync.Mutex
+	calls int
+}
+
+// NewRateLimiter creates and returns new RateLimiter.
+func NewRateLimiter(db *gorm.DB) *RateLimiter {
+	return &RateLimiter{
+		db: db,
+	}
+}
+
+// Allow takes a token at now from the bucket of a key, created full for the limit if missing.
+// It doesn't join the transaction carried by ctx, if any, the bucket being updated anyway, and
+// reads the buckets on the primary database, a replica lagging behind the version checks.
+func (rl *RateLimiter) Allow(
+	ctx context.Context,
+	key string,
+	limit domain.RateLimit,
+	now time.Time,
+) (domain.RateLimitResult, error) {
+	db := primary(ctx, rl.db)
+	if err := rl.sweep(db, now); err != nil {
+		return domain.RateLimitResult{}, err
+	}
+
+	for attempt := 0; attempt < rateLimitMaxAttempts; attempt++ {
+		var rows []rateLimitBucketRow
+		if err := db.Where("bucket_key = ?", key).Limit(1).Find(&rows).Error; err != nil {
+			return domain.RateLimitResult{}, err
+		}
+
+		if len(rows) == 0 {
+			bucket, result := limit.Take(limit.NewBucket(now), now)
+			created := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rateLimitBucketRow{
+				BucketKey:  key,
+				Tokens:     bucket.Tokens,
+				RefilledAt: bucket.UpdatedAt,
+				FullAt:     now.Add(result.Reset),
+				Version:    1,
+			})
+			if created.Error != nil {
+				return domain.RateLimitResult{}, created.Error
+			}
+			if created.RowsAffected == 1 {
+				return result, nil
+			}
+			continue
+		}
+
+		row := rows[0]
+		bucket, result := limit.Take(domain.TokenBucket{Tokens: row.Tokens, UpdatedAt: row.RefilledAt}, now)
+		updated := db.Model(&rateLimitBucketRow{}).
+			Where("bucket_key = ? AND version = ?", key, row.Version).
+			Updates(map[string]interface{}{
+				"tokens":      bucket.Tokens,
+				"refilled_at": bucket.UpdatedAt,
+				"full_at":     now.Add(result.Reset),
+				"version":     row.Version + 1,
+			})
+		if updated.Error != nil {
+			return domain.RateLimitResult{}, updated.Error
+		}
+		if updated.RowsAffected == 1 {
+			return result, nil
+		}
+	}
+	return domain.RateLimitResult{}, errRateLimitContention
+}
+
+// sweep deletes the buckets full again once in a while, a full bucket being the same as a
+// missing one, so that the table doesn't grow with every client ever seen.
+func (rl *RateLimiter) sweep(db *gorm.DB, now time.Time) error {
+	rl.mu.Lock()
+	rl.calls++
+	due := rl.calls >= rateLimitSweepEvery
+	if due {
+		rl.calls = 0
+	}
+	rl.mu.Unlock()
+
+	if !due {
+		return nil
+	}
+	return db.Where("full_at <= ?", now).Delete(&rateLimitBucketRow{}).Error
+}
@@ -0,0 +1,111 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var _ ports.ReportsRepository = (*ReportsRepository)(nil)
+
+// activeAt selects the subscriptions running at a date, neither cancelled nor paused by then.
+// Cancelled or paused subscriptions without a date, e.g. imported ones, are never active.
+const activeAt = `subscription.start_date <= @at AND subscription.end_date > @at
+	AND (subscription.status <> @cancelled OR subscription.cancelled_at > @at)
+	AND (subscription.status <> @paused OR subscription.paused_at IS NOT NULL)
+	AND (subscription.paused_at IS NULL OR subscription.paused_at > @at
+		OR (subscription.resumed_at > subscription.paused_at AND subscription.resumed_at <= @at))`
+
+// ReportsRepository represents list of dependencies for repository.
+type ReportsRepository struct {
+	db *gorm.DB
+}
+
+// NewReportsRepository creates and returns new ReportsRepository.
+func NewReportsRepository(db *gorm.DB) *ReportsRepository {
+	return &ReportsRepository{
+		db: db,
+	}
+}
+
+// activeArgs returns the named arguments of the activeAt condition.
+func activeArgs(asOf time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"at":        asOf.UTC(),
+		"cancelled": domain.SubscriptionStatusCancel,
+		"paused":    domain.SubscriptionStatusPaused,
+	}
+}
+
+// Revenue sums the monthly amount, before tax, of the subscriptions active at asOf per product.
+func (rr ReportsRepository) Revenue(ctx context.Context, asOf time.Time) ([]domain.ProductRevenue, error) {
+	revenues := []domain.ProductRevenue{}
+	result := conn(ctx, rr.db).Table("subscription").
+		Select(`subscription.product_id AS product_id, product.name AS product_name,
+			COUNT(*) AS active_subscriptions,
+			SUM((subscription.total_cost - subscription.tax) * 1.0 / subscription.duration_in_months) AS mrr`).
+		Joins("LEFT JOIN product ON product.id = subscription.product_id").
+		Where(activeAt, activeArgs(asOf)).
+		Group("subscription.product_id, product.name").
+		Order("product.name, subscription.product_id").
+		Scan(&revenues)
+	return revenues, result.Error
+}
+
+// ActiveCustomers counts the distinct customers with a subscription active at asOf.
+func (rr ReportsRepository) ActiveCustomers(ctx context.Context, asOf time.Time) (int, error) {
+	var count int64
+	result := conn(ctx, rr.db).Table("subscription").
+		Where(activeAt, activeArgs(asOf)).
+		Where("subscription.customer_id <> ''").
+		Distinct("subscription.customer_id").
+		Count(&count)
+	return int(count), result.Error
+}
+
+// activityRow represents a row of the activity query, the counts being aliased to avoid
+// reserved words.
+type activityRow struct {
+	ProductID   uuid.UUID
+	ProductName string
+	NewCount    int
+	ChurnCount  int
+	PauseCount  int
+}
+
+// Activity counts the subscriptions started, cancelled and paused in [from, to) per product.
+func (rr ReportsRepository) Activity(ctx context.Context, from, to time.Time) ([]domain.ProductActivity, error) {
+	args := map[string]interface{}{"from": from.UTC(), "to": to.UTC()}
+	rows := []activityRow{}
+	result := conn(ctx, rr.db).Table("subscription").
+		Select(`subscription.product_id AS product_id, product.name AS product_name,
+			SUM(CASE WHEN subscription.start_date >= @from AND subscription.start_date < @to THEN 1 ELSE 0 END) AS new_count,
+			SUM(CASE WHEN subscription.cancelled_at >= @from AND subscription.cancelled_at < @to THEN 1 ELSE 0 END) AS churn_count,
+			SUM(CASE WHEN subscription.paused_at >= @from AND subscription.paused_at < @to THEN 1 ELSE 0 END) AS pause_count`, args).
+		Joins("LEFT JOIN product ON product.id = subscription.product_id").
+		Where(`(subscription.start_date >= @from AND subscription.start_date < @to)
+			OR (subscription.cancelled_at >= @from AND subscription.cancelled_at < @to)
+			OR (subscription.paused_at >= @from AND subscription.paused_at < @to)`, args).
+		Group("subscription.product_id, product.name").
+		Order("product.name, subscription.product_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	activities := make([]domain.ProductActivity, 0, len(rows))
+	for _, row := range rows {
+		activities = append(activities, domain.ProductActivity{
+			ProductID:   row.ProductID,
+			ProductName: row.ProductName,
+			New:         row.NewCount,
+			Churned:     row.ChurnCount,
+			Paused:      row.PauseCount,
+		})
+	}
+	return activities, nil
+}
@@ -0,0 +1,29 @@
+// Package repositories holds the gorm backed implementations of the storage ports.
+package repositories
+
+import (
+	"github.com/goakshit/isildur/core/ports"
+	"gorm.io/gorm"
+)
+
+// Repositories groups the storage dependencies used by the services.
+type Repositories struct {
+	Products       ports.ProductsRepository
+	Subscriptions  ports.SubscriptionsRepository
+	Reports        ports.ReportsRepository
+	RevenueEntries ports.RevenueEntriesRepository
+	APIKeys        ports.APIKeysRepository
+	Tx             ports.TxManager
+}
+
+// NewGormRepositories returns repositories backed by the given gorm db client.
+func NewGormRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		Products:       NewProductsRepository(db),
+		Subscriptions:  NewSubscriptionsRepository(db),
+		Reports:        NewReportsRepository(db),
+		RevenueEntries: NewRevenueEntriesRepository(db),
+		APIKeys:        NewAPIKeysRepository(db),
+		Tx:             NewTxManager(db),
+	}
+}
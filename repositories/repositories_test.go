@@ -0,0 +1,155 @@
+package repositories_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/config"
+	"github.com/goakshit/isildur/platform/database"
+	"github.com/goakshit/isildur/platform/logger"
+	"github.com/goakshit/isildur/platform/migrations"
+	"github.com/goakshit/isildur/repositories"
+	"github.com/goakshit/isildur/repositories/repotest"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// TestGormRepositories_SQLiteContract runs the contract suite against a fresh sqlite
+// database per test, so it doesn't need any external service.
+func TestGormRepositories_SQLiteContract(t *testing.T) {
+	repotest.Run(t, func(t *testing.T, products []domain.Product) repositories.Repositories {
+		db := sqliteDB(t)
+		migrate(t, db)
+		if err := db.Create(&products).Error; err != nil {
+			t.Fatalf("failed to seed products: %v", err)
+		}
+		return repositories.NewGormRepositories(db)
+	})
+}
+
+// TestGormRateLimiter_SQLiteContract runs the rate limiter contract suite against a fresh
+// sqlite database per test.
+func TestGormRateLimiter_SQLiteContract(t *testing.T) {
+	repotest.RunRateLimiter(t, func(t *testing.T) ports.RateLimiter {
+		db := sqliteDB(t)
+		migrate(t, db)
+		return repositories.NewRateLimiter(db)
+	})
+}
+
+// TestGormCache_SQLiteContract runs the cache contract suite against a fresh sqlite database per
+// test.
+func TestGormCache_SQLiteContract(t *testing.T) {
+	repotest.RunCache(t, func(t *testing.T) ports.Cache {
+		db := sqliteDB(t)
+		migrate(t, db)
+		return repositories.NewCache(db)
+	})
+}
+
+// TestGormRepositories_PostgresContract runs the contract suite against postgres.
+// It is skipped unless TEST_POSTGRES_DSN points to a disposable database.
+func TestGormRepositories_PostgresContract(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		NamingStrategy: schema.NamingStrategy{SingularTable: true},
+	})
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	migrate(t, db)
+
+	repotest.Run(t, func(t *testing.T, products []domain.Product) repositories.Repositories {
+		if err := db.Exec("TRUNCATE product, subscription, revenue_entry, api_key").Error; err != nil {
+			t.Fatalf("failed to truncate tables: %v", err)
+		}
+		if err := db.Create(&products).Error; err != nil {
+			t.Fatalf("failed to seed products: %v", err)
+		}
+		return repositories.NewGormRepositories(db)
+	})
+}
+
+// TestGormRepositories_Replica checks the reads go to the replica, unless the context forces
+// them on the primary, and the writes go to the primary.
+func TestGormRepositories_Replica(t *testing.T) {
+	db, replica := sqliteDB(t), sqliteDB(t)
+	migrate(t, db)
+	migrate(t, replica)
+	// Only the replica holds the product, as if the primary had lost it
+	product := domain.Product{ID: uuid.New(), Name: "Replicated", MonthlyPrice: 10}
+	if err := replica.Create(&product).Error; err != nil {
+		t.Fatalf("failed to seed the replica: %v", err)
+	}
+	cfg := &config.CFG{DB: config.DBConfig{Driver: config.DriverSQLite, ReplicaDSN: replicaPath(t, replica)}}
+	if err := database.UseReplica(db, cfg); err != nil {
+		t.Fatalf("failed to use the replica: %v", err)
+	}
+	repos := repositories.NewGormRepositories(db)
+	ctx := context.Background()
+
+	products, err := repos.Products.GetAll(ctx)
+	if err != nil || len(products) != 1 {
+		t.Fatalf("expected the product of the replica, got %v, %v", products, err)
+	}
+	products, err = repos.Products.GetAll(domain.ContextWithPrimaryReads(ctx))
+	if err != nil || len(products) != 0 {
+		t.Fatalf("expected no product on the primary, got %v, %v", products, err)
+	}
+
+	// The product created goes to the primary, then read from it inside a transaction
+	created := domain.Product{ID: uuid.New(), Name: "Created", MonthlyPrice: 10}
+	if err := repos.Products.Create(ctx, created); err != nil {
+		t.Fatalf("failed to create the product: %v", err)
+	}
+	err = repositories.NewTxManager(db).WithinTransaction(ctx, func(ctx context.Context) error {
+		_, err := repos.Products.GetByID(ctx, created.ID)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("expected the product created on the primary, got %v", err)
+	}
+}
+
+// replicaPath returns the path of the file of a sqlite database.
+func replicaPath(t *testing.T, db *gorm.DB) string {
+	var path string
+	if err := db.Raw("SELECT file FROM pragma_database_list WHERE name = 'main'").Scan(&path).Error; err != nil {
+		t.Fatalf("failed to read the database file: %v", err)
+	}
+	return path
+}
+
+// sqliteDB opens a fresh sqlite database, closed at the end of the test.
+func sqliteDB(t *testing.T) *gorm.DB {
+	cfg := &config.CFG{DB: config.DBConfig{
+		Driver: config.DriverSQLite,
+		Path:   filepath.Join(t.TempDir(), "isildur.db"),
+	}}
+	db := database.GetGormClient(cfg, logger.Nop())
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func migrate(t *testing.T, db *gorm.DB) {
+	m, err := migrations.New(db, logger.Nop())
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("failed to migrate: %v", err)
+	}
+}
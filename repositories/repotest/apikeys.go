@@ -0,0 +1,99 @@
+package repotest
+
+import (
+	"context"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/google/uuid"
+)
+
+// newAPIKey returns an API key created on a given day of June, expiring at the end of the year.
+func newAPIKey(prefix string, day int, scopes ...domain.Role) domain.APIKey {
+	expiresAt := time.Date(2022, time.December, 31, 0, 0, 0, 0, time.UTC)
+	return domain.APIKey{
+		ID:        uuid.New(),
+		Name:      "partner " + prefix,
+		Prefix:    prefix,
+		Hash:      "hash-" + prefix,
+		Scopes:    append([]domain.Role{}, scopes...),
+		CreatedAt: time.Date(2022, time.June, day, 0, 0, 0, 0, time.UTC),
+		ExpiresAt: &expiresAt,
+	}
+}
+
+// assertAPIKeyEqual compares API keys ignoring the time location,
+// which isn't preserved by every storage.
+func (ts *ContractTestSuite) assertAPIKeyEqual(expected, actual domain.APIKey) {
+	ts.Assert().True(expected.CreatedAt.Equal(actual.CreatedAt))
+	for _, dates := range [][2]*time.Time{
+		{expected.ExpiresAt, actual.ExpiresAt},
+		{expected.LastUsedAt, actual.LastUsedAt},
+		{expected.RevokedAt, actual.RevokedAt},
+	} {
+		ts.Require().Equal(dates[0] == nil, dates[1] == nil)
+		if dates[0] != nil {
+			ts.Assert().True(dates[0].Equal(*dates[1]), "%v != %v", dates[0], dates[1])
+		}
+	}
+	expected.CreatedAt, expected.ExpiresAt, expected.LastUsedAt, expected.RevokedAt = time.Time{}, nil, nil, nil
+	actual.CreatedAt, actual.ExpiresAt, actual.LastUsedAt, actual.RevokedAt = time.Time{}, nil, nil, nil
+	ts.Assert().Equal(expected, actual)
+}
+
+func (ts *ContractTestSuite) TestAPIKeys_CreateAndGet() {
+	ctx := context.Background()
+	key := newAPIKey("a1b2c3d4", 1, domain.RoleSupport, domain.RoleAdmin)
+	ts.Require().Nil(ts.repos.APIKeys.Create(ctx, key))
+
+	got, err := ts.repos.APIKeys.GetByPrefix(ctx, key.Prefix)
+	ts.Require().Nil(err)
+	ts.assertAPIKeyEqual(key, got)
+
+	_, err = ts.repos.APIKeys.GetByPrefix(ctx, "00000000")
+	ts.Assert().ErrorIs(err, domain.ErrAPIKeyNotfound)
+
+	// Prefixes are unique
+	ts.Assert().NotNil(ts.repos.APIKeys.Create(ctx, newAPIKey(key.Prefix, 2)))
+}
+
+func (ts *ContractTestSuite) TestAPIKeys_List() {
+	ctx := context.Background()
+	keys := []domain.APIKey{
+		newAPIKey("a1b2c3d4", 1, domain.RoleAdmin),
+		newAPIKey("e5f6a7b8", 2),
+		newAPIKey("c9d0e1f2", 3, domain.RoleCustomer),
+	}
+	// Stored out of order, listed by creation date
+	for _, i := range []int{2, 0, 1} {
+		ts.Require().Nil(ts.repos.APIKeys.Create(ctx, keys[i]))
+	}
+
+	got, err := ts.repos.APIKeys.List(ctx)
+	ts.Require().Nil(err)
+	ts.Require().Len(got, 3)
+	for i := range keys {
+		ts.assertAPIKeyEqual(keys[i], got[i])
+	}
+}
+
+func (ts *ContractTestSuite) TestAPIKeys_RevokeAndTouch() {
+	ctx := context.Background()
+	key := newAPIKey("a1b2c3d4", 1, domain.RoleAdmin)
+	ts.Require().Nil(ts.repos.APIKeys.Create(ctx, key))
+
+	usedAt := time.Date(2022, time.June, 10, 12, 0, 0, 0, time.UTC)
+	ts.Require().Nil(ts.repos.APIKeys.Touch(ctx, key.ID, usedAt))
+	revokedAt := time.Date(2022, time.June, 11, 0, 0, 0, 0, time.UTC)
+	ts.Require().Nil(ts.repos.APIKeys.Revoke(ctx, key.ID, revokedAt))
+	// Revoking again keeps the first date
+	ts.Require().Nil(ts.repos.APIKeys.Revoke(ctx, key.ID, revokedAt.AddDate(0, 0, 1)))
+
+	got, err := ts.repos.APIKeys.GetByPrefix(ctx, key.Prefix)
+	ts.Require().Nil(err)
+	key.LastUsedAt, key.RevokedAt = &usedAt, &revokedAt
+	ts.assertAPIKeyEqual(key, got)
+
+	ts.Assert().ErrorIs(ts.repos.APIKeys.Revoke(ctx, uuid.New(), revokedAt), domain.ErrAPIKeyNotfound)
+	ts.Assert().ErrorIs(ts.repos.APIKeys.Touch(ctx, uuid.New(), usedAt), domain.ErrAPIKeyNotfound)
+}
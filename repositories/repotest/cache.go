@@ -0,0 +1,81 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/stretchr/testify/suite"
+)
+
+// CacheFactory returns a fresh cache without any entry.
+type CacheFactory func(t *testing.T) ports.Cache
+
+// RunCache runs the cache contract test suite against the caches returned by factory.
+func RunCache(t *testing.T, factory CacheFactory) {
+	suite.Run(t, &CacheTestSuite{factory: factory})
+}
+
+// CacheTestSuite verifies the behaviour shared by all the cache implementations.
+type CacheTestSuite struct {
+	suite.Suite
+	factory CacheFactory
+	cache   ports.Cache
+	start   time.Time
+}
+
+// SetupTest creates a fresh cache for every test.
+func (ts *CacheTestSuite) SetupTest() {
+	ts.cache = ts.factory(ts.T())
+	ts.start = time.Date(2022, time.June, 1, 12, 0, 0, 0, time.UTC)
+}
+
+func (ts *CacheTestSuite) get(key string, at time.Duration) ([]byte, bool) {
+	value, ok, err := ts.cache.Get(context.Background(), key, ts.start.Add(at))
+	ts.Require().Nil(err)
+	return value, ok
+}
+
+func (ts *CacheTestSuite) set(key, value string, ttl time.Duration) {
+	ts.Require().Nil(ts.cache.Set(context.Background(), key, []byte(value), ts.start.Add(ttl)))
+}
+
+func (ts *CacheTestSuite) TestCache_GetSet() {
+	_, ok := ts.get("products:all", 0)
+	ts.Assert().False(ok)
+
+	ts.set("products:all", "[]", time.Minute)
+	value, ok := ts.get("products:all", 0)
+	ts.Assert().True(ok)
+	ts.Assert().Equal([]byte("[]"), value)
+
+	// The value is replaced, along with its expiry
+	ts.set("products:all", `[{"name":"YOGA 1"}]`, 2*time.Minute)
+	value, ok = ts.get("products:all", time.Minute)
+	ts.Assert().True(ok)
+	ts.Assert().Equal([]byte(`[{"name":"YOGA 1"}]`), value)
+}
+
+func (ts *CacheTestSuite) TestCache_Expiry() {
+	ts.set("products:all", "[]", time.Minute)
+
+	_, ok := ts.get("products:all", time.Minute-time.Second)
+	ts.Assert().True(ok)
+	_, ok = ts.get("products:all", time.Minute)
+	ts.Assert().False(ok)
+}
+
+func (ts *CacheTestSuite) TestCache_Delete() {
+	ts.set("products:all", "[]", time.Minute)
+	ts.set("products:1", "{}", time.Minute)
+	ts.set("products:2", "{}", time.Minute)
+
+	ts.Require().Nil(ts.cache.Delete(context.Background(), "products:all", "products:1", "products:missing"))
+	_, ok := ts.get("products:all", 0)
+	ts.Assert().False(ok)
+	_, ok = ts.get("products:1", 0)
+	ts.Assert().False(ok)
+	_, ok = ts.get("products:2", 0)
+	ts.Assert().True(ok)
+}
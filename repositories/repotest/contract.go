@@ -0,0 +1,416 @@
+// Package repotest holds the contract test suite every storage implementation must pass,
+// so that the in-memory and the gorm repositories behave the same.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/repositories"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+// Factory returns fresh, empty repositories seeded with the given products.
+type Factory func(t *testing.T, products []domain.Product) repositories.Repositories
+
+// Run runs the contract test suite against the repositories returned by factory.
+func Run(t *testing.T, factory Factory) {
+	suite.Run(t, &ContractTestSuite{factory: factory})
+}
+
+// ContractTestSuite verifies the behaviour shared by all the storage implementations.
+type ContractTestSuite struct {
+	suite.Suite
+	factory  Factory
+	products []domain.Product
+	repos    repositories.Repositories
+}
+
+// SetupTest creates fresh repositories for every test.
+func (ts *ContractTestSuite) SetupTest() {
+	ts.products = []domain.Product{
+		{
+			ID:             uuid.New(),
+			Name:           "YOGA L1",
+			Description:    "Basic yoga lessons",
+			MonthlyPrice:   5,
+			InstructorName: "A. Dhar",
+		},
+		{
+			ID:             uuid.New(),
+			Name:           "YOGA L2",
+			Description:    "Intermediate yoga lessons",
+			MonthlyPrice:   7,
+			InstructorName: "A. Dhar",
+		},
+	}
+	ts.repos = ts.factory(ts.T(), ts.products)
+}
+
+func (ts *ContractTestSuite) newSubscription() domain.Subscription {
+	startDate := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+	return domain.Subscription{
+		ID:               uuid.New(),
+		ProductID:        ts.products[0].ID,
+		CustomerID:       "customer-1",
+		DurationInMonths: 3,
+		Tax:              1.05,
+		TotalCost:        16.05,
+		Status:           domain.SubscriptionStatusActive,
+		StartDate:        startDate,
+		EndDate:          startDate.AddDate(0, 3, 0),
+		Version:          1,
+	}
+}
+
+// assertSubscriptionEqual compares subscriptions ignoring the time location,
+// which isn't preserved by every storage.
+func (ts *ContractTestSuite) assertSubscriptionEqual(expected, actual domain.Subscription) {
+	ts.Assert().True(expected.StartDate.Equal(actual.StartDate), "start date %v != %v", expected.StartDate, actual.StartDate)
+	ts.Assert().True(expected.EndDate.Equal(actual.EndDate), "end date %v != %v", expected.EndDate, actual.EndDate)
+	expected.StartDate, actual.StartDate = time.Time{}, time.Time{}
+	expected.EndDate, actual.EndDate = time.Time{}, time.Time{}
+	ts.Assert().Equal(expected, actual)
+}
+
+func (ts *ContractTestSuite) TestProducts_GetAll() {
+	products, err := ts.repos.Products.GetAll(context.Background())
+	ts.Require().Nil(err)
+	ts.Assert().ElementsMatch(ts.products, products)
+}
+
+func (ts *ContractTestSuite) TestProducts_GetByID() {
+	product, err := ts.repos.Products.GetByID(context.Background(), ts.products[1].ID)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(ts.products[1], product)
+}
+
+func (ts *ContractTestSuite) TestProducts_GetByIDNotFound() {
+	_, err := ts.repos.Products.GetByID(context.Background(), uuid.New())
+	ts.Assert().ErrorIs(err, domain.ErrProductNotfound)
+}
+
+func (ts *ContractTestSuite) TestProducts_Create() {
+	ctx := context.Background()
+	product := domain.Product{
+		ID:             uuid.New(),
+		Name:           "PILATES",
+		Description:    "Pilates lessons",
+		MonthlyPrice:   9.5,
+		InstructorName: "B. Kaur",
+	}
+	ts.Require().Nil(ts.repos.Products.Create(ctx, product))
+
+	got, err := ts.repos.Products.GetByID(ctx, product.ID)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(product, got)
+	ts.Assert().NotNil(ts.repos.Products.Create(ctx, product))
+}
+
+func (ts *ContractTestSuite) TestProducts_Search() {
+	ctx := context.Background()
+	pilates := domain.Product{
+		ID:             uuid.New(),
+		Name:           "PILATES",
+		Description:    "Pilates lessons",
+		MonthlyPrice:   9.5,
+		InstructorName: "B. Kaur",
+	}
+	ts.Require().Nil(ts.repos.Products.Create(ctx, pilates))
+	basic, intermediate := ts.products[0], ts.products[1]
+
+	tt := []struct {
+		name     string
+		query    domain.ProductQuery
+		expected []domain.Product
+		// ordered compares the order of the products, not ranked by relevance
+		ordered bool
+	}{
+		{
+			name:     "Search",
+			query:    domain.ProductQuery{Search: "yoga"},
+			expected: []domain.Product{basic, intermediate},
+		},
+		{
+			name:     "SearchEveryTerm",
+			query:    domain.ProductQuery{Search: "Basic YOGA"},
+			expected: []domain.Product{basic},
+		},
+		{
+			name:     "SearchWildcards",
+			query:    domain.ProductQuery{Search: "%"},
+			expected: []domain.Product{},
+		},
+		{
+			name:     "Instructor",
+			query:    domain.ProductQuery{Instructor: "a. dhar", Sort: domain.ProductSortName},
+			expected: []domain.Product{basic, intermediate},
+			ordered:  true,
+		},
+		{
+			name:     "PriceRange",
+			query:    domain.ProductQuery{MinPrice: 6, MaxPrice: 9.5, Sort: domain.ProductSortPrice},
+			expected: []domain.Product{intermediate, pilates},
+			ordered:  true,
+		},
+		{
+			name:     "SortDescending",
+			query:    domain.ProductQuery{Search: "lessons", Sort: domain.ProductSortPrice, Descending: true},
+			expected: []domain.Product{pilates, intermediate, basic},
+			ordered:  true,
+		},
+		{
+			name:     "DefaultSort",
+			query:    domain.ProductQuery{},
+			expected: []domain.Product{pilates, basic, intermediate},
+			ordered:  true,
+		},
+		{
+			name:     "Page",
+			query:    domain.ProductQuery{Sort: domain.ProductSortPrice, Limit: 1, Offset: 1},
+			expected: []domain.Product{intermediate},
+			ordered:  true,
+		},
+	}
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			products, err := ts.repos.Products.Search(ctx, tc.query)
+			ts.Require().Nil(err)
+			if tc.ordered {
+				ts.Assert().Equal(tc.expected, products)
+			} else {
+				ts.Assert().ElementsMatch(tc.expected, products)
+			}
+		})
+	}
+
+	_, err := ts.repos.Products.Search(ctx, domain.ProductQuery{Sort: "rating"})
+	ts.Assert().ErrorIs(err, domain.ErrInvalidQuery)
+}
+
+func (ts *ContractTestSuite) TestSubscriptions_CreateAndGetByID() {
+	ctx := context.Background()
+	sub := ts.newSubscription()
+	ts.Require().Nil(ts.repos.Subscriptions.Create(ctx, sub))
+
+	got, err := ts.repos.Subscriptions.GetByID(ctx, sub.ID)
+	ts.Require().Nil(err)
+	ts.assertSubscriptionEqual(sub, got)
+}
+
+func (ts *ContractTestSuite) TestSubscriptions_CreateDuplicate() {
+	ctx := context.Background()
+	sub := ts.newSubscription()
+	ts.Require().Nil(ts.repos.Subscriptions.Create(ctx, sub))
+	ts.Assert().NotNil(ts.repos.Subscriptions.Create(ctx, sub))
+}
+
+func (ts *ContractTestSuite) TestSubscriptions_CreateBatch() {
+	ctx := context.Background()
+	batch := []domain.Subscription{ts.newSubscription(), ts.newSubscription()}
+	ts.Require().Nil(ts.repos.Subscriptions.CreateBatch(ctx, batch))
+	for _, sub := range batch {
+		got, err := ts.repos.Subscriptions.GetByID(ctx, sub.ID)
+		ts.Require().Nil(err)
+		ts.assertSubscriptionEqual(sub, got)
+	}
+
+	// A batch with an existing subscription is rejected as a whole
+	duplicate := []domain.Subscription{ts.newSubscription(), batch[0]}
+	err := ts.repos.Tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		return ts.repos.Subscriptions.CreateBatch(ctx, duplicate)
+	})
+	ts.Assert().NotNil(err)
+	_, err = ts.repos.Subscriptions.GetByID(ctx, duplicate[0].ID)
+	ts.Assert().ErrorIs(err, domain.ErrSubscriptionNotfound)
+}
+
+func (ts *ContractTestSuite) TestSubscriptions_GetByIDNotFound() {
+	_, err := ts.repos.Subscriptions.GetByID(context.Background(), uuid.New())
+	ts.Assert().ErrorIs(err, domain.ErrSubscriptionNotfound)
+}
+
+func (ts *ContractTestSuite) TestSubscriptions_List() {
+	ctx := context.Background()
+	var created []domain.Subscription
+	for i := 0; i < 4; i++ {
+		sub := ts.newSubscription()
+		sub.StartDate = sub.StartDate.AddDate(0, 0, i)
+		sub.EndDate = sub.StartDate.AddDate(0, 3, 0)
+		if i%2 == 1 {
+			sub.ProductID = ts.products[1].ID
+			sub.Status = domain.SubscriptionStatusPaused
+		}
+		if i == 3 {
+			sub.CustomerID = "customer-2"
+		}
+		ts.Require().Nil(ts.repos.Subscriptions.Create(ctx, sub))
+		created = append(created, sub)
+	}
+
+	all, err := ts.repos.Subscriptions.List(ctx, domain.SubscriptionFilter{})
+	ts.Require().Nil(err)
+	ts.Require().Len(all, 4)
+	for i := range all {
+		ts.assertSubscriptionEqual(created[i], all[i])
+	}
+
+	paused, err := ts.repos.Subscriptions.List(ctx, domain.SubscriptionFilter{
+		Status: domain.SubscriptionStatusPaused,
+	})
+	ts.Require().Nil(err)
+	ts.Require().Len(paused, 2)
+	ts.Assert().Equal(created[1].ID, paused[0].ID)
+	ts.Assert().Equal(created[3].ID, paused[1].ID)
+
+	byProduct, err := ts.repos.Subscriptions.List(ctx, domain.SubscriptionFilter{
+		ProductID: ts.products[0].ID,
+	})
+	ts.Require().Nil(err)
+	ts.Require().Len(byProduct, 2)
+
+	byCustomer, err := ts.repos.Subscriptions.List(ctx, domain.SubscriptionFilter{
+		CustomerID: "customer-2",
+	})
+	ts.Require().Nil(err)
+	ts.Require().Len(byCustomer, 1)
+	ts.Assert().Equal(created[3].ID, byCustomer[0].ID)
+
+	page, err := ts.repos.Subscriptions.List(ctx, domain.SubscriptionFilter{Limit: 2, Offset: 1})
+	ts.Require().Nil(err)
+	ts.Require().Len(page, 2)
+	ts.Assert().Equal(created[1].ID, page[0].ID)
+	ts.Assert().Equal(created[2].ID, page[1].ID)
+
+	empty, err := ts.repos.Subscriptions.List(ctx, domain.SubscriptionFilter{Offset: 10})
+	ts.Require().Nil(err)
+	ts.Assert().Empty(empty)
+}
+
+func (ts *ContractTestSuite) TestSubscriptions_Stream() {
+	ctx := context.Background()
+	first, second := ts.newSubscription(), ts.newSubscription()
+	second.StartDate = second.StartDate.AddDate(0, 1, 0)
+	second.ProductID = ts.products[1].ID
+	second.Status = domain.SubscriptionStatusPaused
+	ts.Require().Nil(ts.repos.Subscriptions.Create(ctx, second))
+	ts.Require().Nil(ts.repos.Subscriptions.Create(ctx, first))
+
+	var rows []domain.SubscriptionExportRow
+	collect := func(row domain.SubscriptionExportRow) error {
+		rows = append(rows, row)
+		return nil
+	}
+	ts.Require().Nil(ts.repos.Subscriptions.Stream(ctx, domain.SubscriptionFilter{}, collect))
+	ts.Require().Len(rows, 2)
+	ts.Assert().Equal(first.ID, rows[0].ID)
+	ts.Assert().Equal(first.CustomerID, rows[0].CustomerID)
+	ts.Assert().Equal(ts.products[0].Name, rows[0].ProductName)
+	ts.Assert().Equal(ts.products[0].MonthlyPrice, rows[0].ProductMonthlyPrice)
+	ts.Assert().True(first.StartDate.Equal(rows[0].StartDate))
+	ts.Assert().Equal(second.ID, rows[1].ID)
+	ts.Assert().Equal(ts.products[1].ID, rows[1].ProductID)
+	ts.Assert().Equal(ts.products[1].Name, rows[1].ProductName)
+
+	rows = nil
+	ts.Require().Nil(ts.repos.Subscriptions.Stream(ctx, domain.SubscriptionFilter{
+		Status: domain.SubscriptionStatusPaused,
+	}, collect))
+	ts.Require().Len(rows, 1)
+	ts.Assert().Equal(second.ID, rows[0].ID)
+
+	// Errors returned by fn stop the stream
+	stop := errors.New("stop")
+	calls := 0
+	err := ts.repos.Subscriptions.Stream(ctx, domain.SubscriptionFilter{}, func(domain.SubscriptionExportRow) error {
+		calls++
+		return stop
+	})
+	ts.Assert().ErrorIs(err, stop)
+	ts.Assert().Equal(1, calls)
+}
+
+func (ts *ContractTestSuite) TestSubscriptions_Patch() {
+	ctx := context.Background()
+	sub := ts.newSubscription()
+	ts.Require().Nil(ts.repos.Subscriptions.Create(ctx, sub))
+
+	err := ts.repos.Subscriptions.Patch(ctx, sub.ID, 1, map[string]interface{}{
+		"status": domain.SubscriptionStatusPaused,
+	})
+	ts.Require().Nil(err)
+
+	got, err := ts.repos.Subscriptions.GetByID(ctx, sub.ID)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(domain.SubscriptionStatusPaused, got.Status)
+	ts.Assert().Equal(2, got.Version)
+}
+
+func (ts *ContractTestSuite) TestSubscriptions_PatchStatusDates() {
+	ctx := context.Background()
+	sub := ts.newSubscription()
+	ts.Require().Nil(ts.repos.Subscriptions.Create(ctx, sub))
+
+	pausedAt := time.Date(2022, time.June, 10, 8, 30, 0, 0, time.UTC)
+	err := ts.repos.Subscriptions.Patch(ctx, sub.ID, 1, map[string]interface{}{
+		"status":    domain.SubscriptionStatusPaused,
+		"paused_at": pausedAt,
+	})
+	ts.Require().Nil(err)
+
+	got, err := ts.repos.Subscriptions.GetByID(ctx, sub.ID)
+	ts.Require().Nil(err)
+	ts.Require().NotNil(got.PausedAt)
+	ts.Assert().True(pausedAt.Equal(*got.PausedAt))
+	ts.Assert().Nil(got.CancelledAt)
+	ts.Assert().Nil(got.ResumedAt)
+
+	// nil clears the date
+	ts.Require().Nil(ts.repos.Subscriptions.Patch(ctx, sub.ID, 2, map[string]interface{}{
+		"paused_at": nil,
+	}))
+	got, err = ts.repos.Subscriptions.GetByID(ctx, sub.ID)
+	ts.Require().Nil(err)
+	ts.Assert().Nil(got.PausedAt)
+}
+
+func (ts *ContractTestSuite) TestSubscriptions_PatchStaleVersion() {
+	ctx := context.Background()
+	sub := ts.newSubscription()
+	ts.Require().Nil(ts.repos.Subscriptions.Create(ctx, sub))
+	update := map[string]interface{}{
+		"status": domain.SubscriptionStatusPaused,
+	}
+	ts.Require().Nil(ts.repos.Subscriptions.Patch(ctx, sub.ID, 1, update))
+
+	err := ts.repos.Subscriptions.Patch(ctx, sub.ID, 1, update)
+	ts.Assert().ErrorIs(err, domain.ErrSubscriptionVersionMismatch)
+}
+
+func (ts *ContractTestSuite) TestSubscriptions_PatchNotFound() {
+	err := ts.repos.Subscriptions.Patch(context.Background(), uuid.New(), 1, map[string]interface{}{
+		"status": domain.SubscriptionStatusPaused,
+	})
+	ts.Assert().ErrorIs(err, domain.ErrSubscriptionNotfound)
+}
+
+func (ts *ContractTestSuite) TestTx_WithinTransaction() {
+	ctx := context.Background()
+	sub := ts.newSubscription()
+	err := ts.repos.Tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := ts.repos.Subscriptions.Create(ctx, sub); err != nil {
+			return err
+		}
+		// Reads inside the unit of work see its own writes
+		_, err := ts.repos.Subscriptions.GetByID(ctx, sub.ID)
+		return err
+	})
+	ts.Require().Nil(err)
+
+	_, err = ts.repos.Subscriptions.GetByID(ctx, sub.ID)
+	ts.Assert().Nil(err)
+}
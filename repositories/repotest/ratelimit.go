@@ -0,0 +1,104 @@
+package repotest
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/stretchr/testify/suite"
+)
+
+// RateLimiterFactory returns a fresh rate limiter without any bucket.
+type RateLimiterFactory func(t *testing.T) ports.RateLimiter
+
+// RunRateLimiter runs the rate limiter contract test suite against the limiters returned by factory.
+func RunRateLimiter(t *testing.T, factory RateLimiterFactory) {
+	suite.Run(t, &RateLimiterTestSuite{factory: factory})
+}
+
+// RateLimiterTestSuite verifies the behaviour shared by all the rate limiter implementations.
+type RateLimiterTestSuite struct {
+	suite.Suite
+	factory RateLimiterFactory
+	limiter ports.RateLimiter
+	start   time.Time
+	limit   domain.RateLimit
+}
+
+// SetupTest creates a fresh rate limiter for every test.
+func (ts *RateLimiterTestSuite) SetupTest() {
+	ts.limiter = ts.factory(ts.T())
+	ts.start = time.Date(2022, time.June, 1, 12, 0, 0, 0, time.UTC)
+	ts.limit = domain.RateLimit{Requests: 3, Per: time.Minute}
+}
+
+func (ts *RateLimiterTestSuite) allow(key string, at time.Duration) domain.RateLimitResult {
+	result, err := ts.limiter.Allow(context.Background(), key, ts.limit, ts.start.Add(at))
+	ts.Require().Nil(err)
+	return result
+}
+
+func (ts *RateLimiterTestSuite) TestRateLimiter_Burst() {
+	for i := 0; i < 3; i++ {
+		result := ts.allow("client-1", 0)
+		ts.Assert().True(result.Allowed)
+		ts.Assert().Equal(3, result.Limit)
+		ts.Assert().Equal(2-i, result.Remaining)
+		ts.Assert().Equal(time.Duration(i+1)*20*time.Second, result.Reset)
+	}
+
+	result := ts.allow("client-1", 0)
+	ts.Assert().False(result.Allowed)
+	ts.Assert().Equal(0, result.Remaining)
+	ts.Assert().Equal(20*time.Second, result.RetryAfter)
+	ts.Assert().Equal(time.Minute, result.Reset)
+
+	// Every client has its own bucket
+	ts.Assert().True(ts.allow("client-2", 0).Allowed)
+}
+
+func (ts *RateLimiterTestSuite) TestRateLimiter_Refill() {
+	for i := 0; i < 3; i++ {
+		ts.Require().True(ts.allow("client-1", 0).Allowed)
+	}
+
+	result := ts.allow("client-1", 10*time.Second)
+	ts.Assert().False(result.Allowed)
+	ts.Assert().Equal(10*time.Second, result.RetryAfter)
+
+	// A token is earned every 20 seconds
+	result = ts.allow("client-1", 20*time.Second)
+	ts.Assert().True(result.Allowed)
+	ts.Assert().Equal(0, result.Remaining)
+	ts.Assert().False(ts.allow("client-1", 20*time.Second).Allowed)
+
+	// The bucket doesn't hold more than the limit
+	result = ts.allow("client-1", time.Hour)
+	ts.Assert().True(result.Allowed)
+	ts.Assert().Equal(2, result.Remaining)
+}
+
+func (ts *RateLimiterTestSuite) TestRateLimiter_Concurrent() {
+	ts.limit = domain.RateLimit{Requests: 10, Per: time.Hour}
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := ts.limiter.Allow(context.Background(), "client-1", ts.limit, ts.start)
+			mu.Lock()
+			defer mu.Unlock()
+			ts.Assert().Nil(err)
+			if result.Allowed {
+				allowed++
+			}
+		}()
+	}
+	wg.Wait()
+	ts.Assert().Equal(10, allowed)
+}
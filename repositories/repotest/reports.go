@@ -0,0 +1,133 @@
+package repotest
+
+import (
+	"context"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/google/uuid"
+)
+
+// reportFixtures creates the dataset the reports are checked against. The monthly amount,
+// before tax, is 5 for the first product and 7 for the second one.
+func (ts *ContractTestSuite) reportFixtures() {
+	date := func(month time.Month, day int) *time.Time {
+		d := time.Date(2022, month, day, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	subscription := func(
+		product domain.Product,
+		customerID string,
+		start *time.Time,
+		months int8,
+		status domain.SubscriptionStatus,
+	) domain.Subscription {
+		cost := product.MonthlyPrice * float64(months)
+		tax := cost * 0.07
+		return domain.Subscription{
+			ID:               uuid.New(),
+			ProductID:        product.ID,
+			CustomerID:       customerID,
+			DurationInMonths: months,
+			Tax:              tax,
+			TotalCost:        cost + tax,
+			Status:           status,
+			StartDate:        *start,
+			EndDate:          start.AddDate(0, int(months), 0),
+			Version:          1,
+		}
+	}
+	l1, l2 := ts.products[0], ts.products[1]
+	active, paused, cancelled := domain.SubscriptionStatusActive, domain.SubscriptionStatusPaused, domain.SubscriptionStatusCancel
+
+	churned := subscription(l2, "customer-3", date(time.July, 1), 1, cancelled)
+	churned.CancelledAt = date(time.July, 10)
+	churnedLater := subscription(l2, "customer-4", date(time.June, 1), 3, cancelled)
+	churnedLater.CancelledAt = date(time.August, 1)
+	pausedSub := subscription(l1, "customer-5", date(time.June, 1), 3, paused)
+	pausedSub.PausedAt = date(time.July, 5)
+	resumed := subscription(l1, "customer-6", date(time.June, 1), 3, active)
+	resumed.PausedAt, resumed.ResumedAt = date(time.June, 10), date(time.July, 1)
+
+	subscriptions := []domain.Subscription{
+		subscription(l1, "customer-1", date(time.June, 1), 3, active),
+		subscription(l1, "customer-2", date(time.May, 1), 6, active),
+		subscription(l2, "customer-1", date(time.January, 1), 12, active),
+		churned,
+		churnedLater,
+		pausedSub,
+		resumed,
+		// Not started yet, already ended, and imported as cancelled without a date
+		subscription(l1, "customer-7", date(time.August, 1), 3, domain.SubscriptionStatusInactive),
+		subscription(l1, "customer-8", date(time.March, 1), 3, active),
+		subscription(l2, "", date(time.July, 1), 1, cancelled),
+	}
+	ts.Require().Nil(ts.repos.Subscriptions.CreateBatch(context.Background(), subscriptions))
+}
+
+func (ts *ContractTestSuite) TestReports_Revenue() {
+	ts.reportFixtures()
+	ctx := context.Background()
+
+	revenues, err := ts.repos.Reports.Revenue(ctx, time.Date(2022, time.July, 15, 0, 0, 0, 0, time.UTC))
+	ts.Require().Nil(err)
+	ts.Require().Len(revenues, 2)
+	ts.Assert().Equal(ts.products[0].ID, revenues[0].ProductID)
+	ts.Assert().Equal(ts.products[0].Name, revenues[0].ProductName)
+	ts.Assert().Equal(3, revenues[0].ActiveSubscriptions)
+	ts.Assert().InDelta(15, revenues[0].MRR, 1e-9)
+	ts.Assert().Equal(ts.products[1].ID, revenues[1].ProductID)
+	ts.Assert().Equal(2, revenues[1].ActiveSubscriptions)
+	ts.Assert().InDelta(14, revenues[1].MRR, 1e-9)
+
+	// The paused subscription is active again before its pause
+	revenues, err = ts.repos.Reports.Revenue(ctx, time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC))
+	ts.Require().Nil(err)
+	ts.Require().Len(revenues, 2)
+	ts.Assert().Equal(4, revenues[0].ActiveSubscriptions)
+	ts.Assert().Equal(3, revenues[1].ActiveSubscriptions)
+
+	revenues, err = ts.repos.Reports.Revenue(ctx, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC))
+	ts.Require().Nil(err)
+	ts.Assert().Empty(revenues)
+}
+
+func (ts *ContractTestSuite) TestReports_ActiveCustomers() {
+	ts.reportFixtures()
+
+	count, err := ts.repos.Reports.ActiveCustomers(context.Background(), time.Date(2022, time.July, 15, 0, 0, 0, 0, time.UTC))
+	ts.Require().Nil(err)
+	ts.Assert().Equal(4, count)
+}
+
+func (ts *ContractTestSuite) TestReports_Activity() {
+	ts.reportFixtures()
+	ctx := context.Background()
+
+	activities, err := ts.repos.Reports.Activity(ctx,
+		time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC),
+	)
+	ts.Require().Nil(err)
+	ts.Assert().Equal([]domain.ProductActivity{
+		{ProductID: ts.products[0].ID, ProductName: ts.products[0].Name, New: 3, Paused: 1},
+		{ProductID: ts.products[1].ID, ProductName: ts.products[1].Name, New: 1},
+	}, activities)
+
+	activities, err = ts.repos.Reports.Activity(ctx,
+		time.Date(2022, time.July, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, time.August, 1, 0, 0, 0, 0, time.UTC),
+	)
+	ts.Require().Nil(err)
+	ts.Assert().Equal([]domain.ProductActivity{
+		{ProductID: ts.products[0].ID, ProductName: ts.products[0].Name, Paused: 1},
+		{ProductID: ts.products[1].ID, ProductName: ts.products[1].Name, New: 2, Churned: 1},
+	}, activities)
+
+	activities, err = ts.repos.Reports.Activity(ctx,
+		time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC),
+	)
+	ts.Require().Nil(err)
+	ts.Assert().Empty(activities)
+}
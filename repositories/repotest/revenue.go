@@ -0,0 +1,131 @@
+package repotest
+
+import (
+	"context"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/google/uuid"
+)
+
+// newRevenueEntries returns the 3 monthly entries, worth 5 each, of a subscription to a
+// product starting on the 1st of June and booked on the 20th of May.
+func (ts *ContractTestSuite) newRevenueEntries(product domain.Product) []domain.RevenueEntry {
+	subscriptionID := uuid.New()
+	start := time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC)
+	entries := []domain.RevenueEntry{}
+	for i := 0; i < 3; i++ {
+		entries = append(entries, domain.RevenueEntry{
+			ID:             uuid.New(),
+			SubscriptionID: subscriptionID,
+			ProductID:      product.ID,
+			Month:          int8(i + 1),
+			PeriodStart:    start.AddDate(0, i, 0),
+			PeriodEnd:      start.AddDate(0, i+1, 0),
+			Amount:         5,
+			Status:         domain.RevenueEntryScheduled,
+			RecognizeOn:    start.AddDate(0, i+1, 0),
+			BookedOn:       time.Date(2022, time.May, 20, 0, 0, 0, 0, time.UTC),
+		})
+	}
+	return entries
+}
+
+// assertRevenueEntriesEqual compares revenue entries ignoring the time location,
+// which isn't preserved by every storage.
+func (ts *ContractTestSuite) assertRevenueEntriesEqual(expected, actual []domain.RevenueEntry) {
+	ts.Require().Len(actual, len(expected))
+	for i := range expected {
+		e, a := expected[i], actual[i]
+		for _, dates := range [][2]time.Time{
+			{e.PeriodStart, a.PeriodStart}, {e.PeriodEnd, a.PeriodEnd},
+			{e.RecognizeOn, a.RecognizeOn}, {e.BookedOn, a.BookedOn},
+		} {
+			ts.Assert().True(dates[0].Equal(dates[1]), "%v != %v", dates[0], dates[1])
+		}
+		ts.Assert().Equal(e.RefundedOn == nil, a.RefundedOn == nil)
+		if e.RefundedOn != nil && a.RefundedOn != nil {
+			ts.Assert().True(e.RefundedOn.Equal(*a.RefundedOn))
+		}
+		e.PeriodStart, e.PeriodEnd, e.RecognizeOn, e.BookedOn, e.RefundedOn = time.Time{}, time.Time{}, time.Time{}, time.Time{}, nil
+		a.PeriodStart, a.PeriodEnd, a.RecognizeOn, a.BookedOn, a.RefundedOn = time.Time{}, time.Time{}, time.Time{}, time.Time{}, nil
+		ts.Assert().Equal(e, a)
+	}
+}
+
+func (ts *ContractTestSuite) TestRevenueEntries_CreateBatchAndList() {
+	ctx := context.Background()
+	entries := ts.newRevenueEntries(ts.products[0])
+	other := ts.newRevenueEntries(ts.products[1])
+	// Stored out of order, listed by month
+	ts.Require().Nil(ts.repos.RevenueEntries.CreateBatch(ctx, []domain.RevenueEntry{entries[2], entries[0], entries[1]}))
+	ts.Require().Nil(ts.repos.RevenueEntries.CreateBatch(ctx, other))
+	ts.Require().Nil(ts.repos.RevenueEntries.CreateBatch(ctx, nil))
+
+	got, err := ts.repos.RevenueEntries.ListBySubscription(ctx, entries[0].SubscriptionID)
+	ts.Require().Nil(err)
+	ts.assertRevenueEntriesEqual(entries, got)
+
+	got, err = ts.repos.RevenueEntries.ListBySubscription(ctx, uuid.New())
+	ts.Require().Nil(err)
+	ts.Assert().Empty(got)
+
+	ts.Assert().NotNil(ts.repos.RevenueEntries.CreateBatch(ctx, entries[:1]))
+}
+
+func (ts *ContractTestSuite) TestRevenueEntries_Update() {
+	ctx := context.Background()
+	entries := ts.newRevenueEntries(ts.products[0])
+	ts.Require().Nil(ts.repos.RevenueEntries.CreateBatch(ctx, entries))
+
+	refundedOn := time.Date(2022, time.July, 10, 0, 0, 0, 0, time.UTC)
+	entries[1].Status = domain.RevenueEntrySuspended
+	entries[1].PeriodStart = entries[1].PeriodStart.AddDate(0, 0, 10)
+	entries[1].PeriodEnd = entries[1].PeriodEnd.AddDate(0, 0, 10)
+	entries[1].RecognizeOn = entries[1].PeriodEnd
+	entries[2].Status = domain.RevenueEntryRefunded
+	entries[2].RefundedOn = &refundedOn
+	ts.Require().Nil(ts.repos.RevenueEntries.Update(ctx, entries[1:]))
+
+	got, err := ts.repos.RevenueEntries.ListBySubscription(ctx, entries[0].SubscriptionID)
+	ts.Require().Nil(err)
+	ts.assertRevenueEntriesEqual(entries, got)
+}
+
+func (ts *ContractTestSuite) TestRevenueEntries_PeriodClose() {
+	ctx := context.Background()
+	entries := ts.newRevenueEntries(ts.products[0])
+	refundedOn := time.Date(2022, time.July, 10, 0, 0, 0, 0, time.UTC)
+	entries[1].Status, entries[1].RefundedOn = domain.RevenueEntryRefunded, &refundedOn
+	entries[2].Status = domain.RevenueEntrySuspended
+	other := ts.newRevenueEntries(ts.products[1])
+	ts.Require().Nil(ts.repos.RevenueEntries.CreateBatch(ctx, append(entries, other...)))
+
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2022, month, day, 0, 0, 0, 0, time.UTC)
+	}
+	recognitions, err := ts.repos.RevenueEntries.PeriodClose(ctx, date(time.July, 1), date(time.August, 1))
+	ts.Require().Nil(err)
+	ts.Require().Len(recognitions, 2)
+	ts.Assert().Equal(ts.products[0].ID, recognitions[0].ProductID)
+	ts.Assert().Equal(ts.products[0].Name, recognitions[0].ProductName)
+	ts.Assert().InDelta(5, recognitions[0].Recognized, 1e-9)
+	ts.Assert().InDelta(5, recognitions[0].Refunded, 1e-9)
+	ts.Assert().InDelta(5, recognitions[0].Deferred, 1e-9)
+	ts.Assert().Equal(ts.products[1].ID, recognitions[1].ProductID)
+	ts.Assert().InDelta(5, recognitions[1].Recognized, 1e-9)
+	ts.Assert().InDelta(0, recognitions[1].Refunded, 1e-9)
+	ts.Assert().InDelta(10, recognitions[1].Deferred, 1e-9)
+
+	// Everything is deferred before the first month is over, refunds included
+	recognitions, err = ts.repos.RevenueEntries.PeriodClose(ctx, date(time.June, 1), date(time.July, 1))
+	ts.Require().Nil(err)
+	ts.Require().Len(recognitions, 2)
+	ts.Assert().InDelta(0, recognitions[0].Recognized, 1e-9)
+	ts.Assert().InDelta(15, recognitions[0].Deferred, 1e-9)
+
+	// Nothing booked yet
+	recognitions, err = ts.repos.RevenueEntries.PeriodClose(ctx, date(time.April, 1), date(time.May, 1))
+	ts.Require().Nil(err)
+	ts.Assert().Empty(recognitions)
+}
@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+var _ ports.RevenueEntriesRepository = (*RevenueEntriesRepository)(nil)
+
+// RevenueEntriesRepository represents list of dependencies for repository.
+type RevenueEntriesRepository struct {
+	db *gorm.DB
+}
+
+// NewRevenueEntriesRepository creates and returns new RevenueEntriesRepository.
+func NewRevenueEntriesRepository(db *gorm.DB) *RevenueEntriesRepository {
+	return &RevenueEntriesRepository{
+		db: db,
+	}
+}
+
+// CreateBatch is used to create several revenue entries in the db at once.
+func (rr RevenueEntriesRepository) CreateBatch(ctx context.Context, entries []domain.RevenueEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	return conn(ctx, rr.db).CreateInBatches(&entries, createBatchSize).Error
+}
+
+// ListBySubscription fetches the revenue entries of a subscription, ordered by month.
+func (rr RevenueEntriesRepository) ListBySubscription(ctx context.Context, subscriptionID uuid.UUID) ([]domain.RevenueEntry, error) {
+	entries := []domain.RevenueEntry{}
+	result := conn(ctx, rr.db).
+		Where("subscription_id = ?", subscriptionID).
+		Order("month").
+		Find(&entries)
+	return entries, result.Error
+}
+
+// Update saves the status and the dates of the given revenue entries.
+func (rr RevenueEntriesRepository) Update(ctx context.Context, entries []domain.RevenueEntry) error {
+	db := conn(ctx, rr.db)
+	for _, entry := range entries {
+		err := db.Model(&domain.RevenueEntry{}).
+			Where("id = ?", entry.ID).
+			Updates(map[string]interface{}{
+				"status":       entry.Status,
+				"period_start": entry.PeriodStart,
+				"period_end":   entry.PeriodEnd,
+				"recognize_on": entry.RecognizeOn,
+				"refunded_on":  entry.RefundedOn,
+			}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PeriodClose sums per product the revenue recognized and refunded in [from, to), and the
+// revenue booked before to but still deferred at to.
+func (rr RevenueEntriesRepository) PeriodClose(ctx context.Context, from, to time.Time) ([]domain.ProductRecognition, error) {
+	args := map[string]interface{}{
+		"from":      from.UTC(),
+		"to":        to.UTC(),
+		"scheduled": domain.RevenueEntryScheduled,
+		"suspended": domain.RevenueEntrySuspended,
+		"refunded":  domain.RevenueEntryRefunded,
+	}
+	recognitions := []domain.ProductRecognition{}
+	result := conn(ctx, rr.db).Table("revenue_entry").
+		Select(`revenue_entry.product_id AS product_id, product.name AS product_name,
+			SUM(CASE WHEN revenue_entry.status = @scheduled
+				AND revenue_entry.recognize_on >= @from AND revenue_entry.recognize_on < @to
+				THEN revenue_entry.amount ELSE 0 END) AS recognized,
+			SUM(CASE WHEN revenue_entry.status = @refunded
+				AND revenue_entry.refunded_on >= @from AND revenue_entry.refunded_on < @to
+				THEN revenue_entry.amount ELSE 0 END) AS refunded,
+			SUM(CASE WHEN revenue_entry.status = @suspended
+				OR (revenue_entry.status = @scheduled AND revenue_entry.recognize_on >= @to)
+				OR (revenue_entry.status = @refunded AND revenue_entry.refunded_on >= @to)
+				THEN revenue_entry.amount ELSE 0 END) AS deferred`, args).
+		Joins("LEFT JOIN product ON product.id = revenue_entry.product_id").
+		Where("revenue_entry.booked_on < @to", args).
+		Group("revenue_entry.product_id, product.name").
+		Order("product.name, revenue_entry.product_id").
+		Scan(&recognitions)
+	return recognitions, result.Error
+}
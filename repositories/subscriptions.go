@@ -2,7 +2,9 @@ package repositories
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
 
 	"github.com/goakshit/isildur/core/domain"
 	"github.com/goakshit/isildur/core/ports"
@@ -12,6 +14,15 @@ import (
 
 var _ ports.SubscriptionsRepository = (*SubscriptionsRepository)(nil)
 
+const (
+	// createBatchSize is the number of rows inserted per statement, kept below the
+	// bind parameter limits of postgres and sqlite.
+	createBatchSize = 100
+
+	// streamFetchSize is the number of rows fetched at once from the export cursor.
+	streamFetchSize = 1000
+)
+
 // SubscriptionsRepository represents list of dependencies for repository.
 type SubscriptionsRepository struct {
 	db *gorm.DB
@@ -27,7 +38,7 @@ func NewSubscriptionsRepository(db *gorm.DB) *SubscriptionsRepository {
 // GetByID fetches subscription for a given id.
 func (sr SubscriptionsRepository) GetByID(ctx context.Context, id uuid.UUID) (domain.Subscription, error) {
 	subscription := domain.Subscription{}
-	result := sr.db.WithContext(ctx).Where(domain.Subscription{
+	result := conn(ctx, sr.db).Where(domain.Subscription{
 		ID: id,
 	}).First(&subscription)
 	if result.Error != nil && errors.Is(result.Error, gorm.ErrRecordNotFound) {
@@ -36,25 +47,138 @@ func (sr SubscriptionsRepository) GetByID(ctx context.Context, id uuid.UUID) (do
 	return subscription, result.Error
 }
 
+// List fetches the subscriptions matching the filter, ordered by start date.
+func (sr SubscriptionsRepository) List(ctx context.Context, filter domain.SubscriptionFilter) ([]domain.Subscription, error) {
+	subscriptions := []domain.Subscription{}
+	result := applyFilter(conn(ctx, sr.db).Model(&domain.Subscription{}), filter).
+		Order("start_date, id").
+		Find(&subscriptions)
+	return subscriptions, result.Error
+}
+
+// Stream calls fn for every subscription matching the filter, joined with its product.
+// On postgres the rows are fetched in chunks through a server side cursor, elsewhere they
+// are read one by one from the result set, so the memory usage doesn't grow with the rows.
+func (sr SubscriptionsRepository) Stream(
+	ctx context.Context,
+	filter domain.SubscriptionFilter,
+	fn func(domain.SubscriptionExportRow) error,
+) error {
+	query := func(db *gorm.DB) *gorm.DB {
+		return applyFilter(db.Table("subscription"), filter).
+			Select("subscription.*, product.name AS product_name, product.monthly_price AS product_monthly_price").
+			Joins("LEFT JOIN product ON product.id = subscription.product_id").
+			Order("subscription.start_date, subscription.id")
+	}
+
+	db := conn(ctx, sr.db)
+	if db.Dialector.Name() != "postgres" {
+		rows, err := query(db).Rows()
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		return scanExportRows(db, rows, fn)
+	}
+
+	stmt := query(db.Session(&gorm.Session{DryRun: true})).Find(&[]domain.SubscriptionExportRow{}).Statement
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DECLARE subscription_export NO SCROLL CURSOR FOR "+stmt.SQL.String(), stmt.Vars...).Error; err != nil {
+			return err
+		}
+		for {
+			rows, err := tx.Raw(fmt.Sprintf("FETCH FORWARD %d FROM subscription_export", streamFetchSize)).Rows()
+			if err != nil {
+				return err
+			}
+			var count int
+			err = scanExportRows(tx, rows, func(row domain.SubscriptionExportRow) error {
+				count++
+				return fn(row)
+			})
+			rows.Close()
+			if err != nil || count == 0 {
+				return err
+			}
+		}
+	})
+}
+
+// scanExportRows calls fn for every row of the result set.
+func scanExportRows(db *gorm.DB, rows *sql.Rows, fn func(domain.SubscriptionExportRow) error) error {
+	for rows.Next() {
+		var row domain.SubscriptionExportRow
+		if err := db.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// applyFilter adds the conditions and pagination of a filter to a subscriptions query.
+func applyFilter(query *gorm.DB, filter domain.SubscriptionFilter) *gorm.DB {
+	if filter.ProductID != uuid.Nil {
+		query = query.Where("subscription.product_id = ?", filter.ProductID)
+	}
+	if filter.CustomerID != "" {
+		query = query.Where("subscription.customer_id = ?", filter.CustomerID)
+	}
+	if filter.Status != "" {
+		query = query.Where("subscription.status = ?", filter.Status)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+	return query
+}
+
 // Create is used to create a subscription in the db.
 func (sr SubscriptionsRepository) Create(ctx context.Context, sub domain.Subscription) error {
-	return sr.db.WithContext(ctx).Create(&sub).Error
+	return conn(ctx, sr.db).Create(&sub).Error
+}
+
+// CreateBatch is used to create several subscriptions in the db at once.
+func (sr SubscriptionsRepository) CreateBatch(ctx context.Context, subs []domain.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+	return conn(ctx, sr.db).CreateInBatches(&subs, createBatchSize).Error
 }
 
-// Patch updates the data in subscription for a given id.
-func (sr SubscriptionsRepository) Patch(ctx context.Context, id uuid.UUID, update map[string]interface{}) error {
-	updateOP := sr.db.WithContext(ctx).Model(&domain.Subscription{}).
-		Where(&domain.Subscription{
-			ID: id,
-		}).
-		Updates(update)
+// Patch updates the data in subscription for a given id, if it is still at the
+// expected version. The version is incremented as part of the same update.
+func (sr SubscriptionsRepository) Patch(ctx context.Context, id uuid.UUID, version int, update map[string]interface{}) error {
+	columns := make(map[string]interface{}, len(update)+1)
+	for k, v := range update {
+		columns[k] = v
+	}
+	columns["version"] = gorm.Expr("version + 1")
+
+	updateOP := conn(ctx, sr.db).Model(&domain.Subscription{}).
+		Where("id = ? AND version = ?", id, version).
+		Updates(columns)
 	if updateOP.Error != nil {
 		return updateOP.Error
 	}
 
-	// Incorrect ID
 	if updateOP.RowsAffected == 0 {
-		return domain.ErrSubscriptionNotfound
+		// Either the ID is incorrect or someone else updated it in the meantime.
+		var count int64
+		if err := conn(ctx, sr.db).Model(&domain.Subscription{}).
+			Where(&domain.Subscription{ID: id}).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return domain.ErrSubscriptionNotfound
+		}
+		return domain.ErrSubscriptionVersionMismatch
 	}
 	return nil
 }
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+var (
+	_ ports.TxManager = (*TxManager)(nil)
+	_ ports.TxManager = (*NoopTxManager)(nil)
+)
+
+// txKey is the context key under which the ongoing gorm transaction is stored.
+type txKey struct{}
+
+// TxManager runs repository calls inside a single gorm transaction.
+type TxManager struct {
+	db *gorm.DB
+}
+
+// NewTxManager creates and returns new TxManager.
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{
+		db: db,
+	}
+}
+
+// WithinTransaction runs fn inside a transaction which is carried in the context passed to fn.
+// If the context already carries a transaction, fn joins it instead of starting a new one.
+func (tm TxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+	return tm.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+// NoopTxManager runs fn without any transaction. It is meant for storages which
+// don't support transactions and for tests.
+type NoopTxManager struct{}
+
+// WithinTransaction runs fn with the given context.
+func (NoopTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// conn returns the transaction carried in the context if any, otherwise the db client, on the
+// primary database if the context forces the reads on it.
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	if domain.PrimaryReadsFromContext(ctx) {
+		return primary(ctx, db)
+	}
+	return db.WithContext(ctx)
+}
+
+// primary returns the db client sending every query to the primary database, even with a read
+// replica configured.
+func primary(ctx context.Context, db *gorm.DB) *gorm.DB {
+	// The session makes the client reusable across the queries
+	return db.WithContext(ctx).Clauses(dbresolver.Write).Session(&gorm.Session{})
+}
@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const (
+	// apiKeyPrefixBytes and apiKeySecretBytes are the random bytes of the public and secret
+	// parts of the keys. The prefix is unique, long enough for the keys not to collide.
+	apiKeyPrefixBytes = 8
+	apiKeySecretBytes = 32
+
+	// apiKeyTouchInterval is the least time between two updates of the last use of a key, so
+	// that every request doesn't write to the database.
+	apiKeyTouchInterval = time.Minute
+)
+
+var _ ports.APIKeyService = (*APIKeyService)(nil)
+
+// APIKeyService represents required dependencies for the service.
+type APIKeyService struct {
+	keysRepo ports.APIKeysRepository
+	now      func() time.Time
+}
+
+// NewAPIKeyService creates and returns new APIKeyService.
+func NewAPIKeyService(k ports.APIKeysRepository) *APIKeyService {
+	return &APIKeyService{
+		keysRepo: k,
+		now:      time.Now,
+	}
+}
+
+// CreateAPIKey creates an API key granting the scopes, until expiresAt if set. The key is
+// only returned here, its hash being stored. Only admins can create API keys.
+func (as APIKeyService) CreateAPIKey(
+	ctx context.Context,
+	name string,
+	scopes []domain.Role,
+	expiresAt *time.Time,
+) (_ domain.NewAPIKey, err error) {
+	ctx, end := startSpan(ctx, "APIKeyService.CreateAPIKey")
+	defer end(&err)
+	if err := authorize(ctx, actionManageAPIKeys); err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	now := as.now().UTC()
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return domain.NewAPIKey{}, fmt.Errorf("%w: name is required", domain.ErrInvalidAPIKey)
+	}
+	if len(scopes) == 0 {
+		return domain.NewAPIKey{}, fmt.Errorf("%w: at least one scope is required", domain.ErrInvalidAPIKey)
+	}
+	for _, scope := range scopes {
+		if domain.MapStringToRole(string(scope)) == "" {
+			return domain.NewAPIKey{}, fmt.Errorf("%w: unknown scope %q", domain.ErrInvalidAPIKey, scope)
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(now) {
+		return domain.NewAPIKey{}, fmt.Errorf("%w: expiry must be in the future", domain.ErrInvalidAPIKey)
+	}
+
+	prefix, err := randomString(apiKeyPrefixBytes, hex.EncodeToString)
+	if err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	secret, err := randomString(apiKeySecretBytes, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	key := domain.APIKeyPrefix + prefix + "_" + secret
+	apiKey := domain.APIKey{
+		ID:        uuid.New(),
+		Name:      name,
+		Prefix:    prefix,
+		Hash:      hashAPIKey(key),
+		Scopes:    scopes,
+		CreatedAt: now,
+		ExpiresAt: expiresAt,
+	}
+	if err := as.keysRepo.Create(ctx, apiKey); err != nil {
+		return domain.NewAPIKey{}, err
+	}
+	return domain.NewAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// ListAPIKeys lists every API key, revoked and expired ones included. Only admins can list
+// API keys.
+func (as APIKeyService) ListAPIKeys(ctx context.Context) (_ []domain.APIKey, err error) {
+	ctx, end := startSpan(ctx, "APIKeyService.ListAPIKeys")
+	defer end(&err)
+	if err := authorize(ctx, actionManageAPIKeys); err != nil {
+		return nil, err
+	}
+	return as.keysRepo.List(ctx)
+}
+
+// RevokeAPIKey revokes an API key for good. Only admins can revoke API keys.
+func (as APIKeyService) RevokeAPIKey(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, end := startSpan(ctx, "APIKeyService.RevokeAPIKey", attribute.String("api_key.id", id.String()))
+	defer end(&err)
+	if err := authorize(ctx, actionManageAPIKeys); err != nil {
+		return err
+	}
+	if id == uuid.Nil {
+		return domain.ErrAPIKeyNotfound
+	}
+	return as.keysRepo.Revoke(ctx, id, as.now().UTC())
+}
+
+// AuthenticateAPIKey checks an API key against the stored hash and returns a principal with
+// the scopes of the key as roles. Unknown, revoked and expired keys are reported as
+// domain.ErrUnauthenticated.
+func (as APIKeyService) AuthenticateAPIKey(ctx context.Context, key string) (_ domain.Principal, err error) {
+	ctx, end := startSpan(ctx, "APIKeyService.AuthenticateAPIKey")
+	defer end(&err)
+	prefix, _, ok := strings.Cut(strings.TrimPrefix(key, domain.APIKeyPrefix), "_")
+	if !strings.HasPrefix(key, domain.APIKeyPrefix) || !ok {
+		return domain.Principal{}, fmt.Errorf("%w: malformed api key", domain.ErrUnauthenticated)
+	}
+	apiKey, err := as.keysRepo.GetByPrefix(ctx, prefix)
+	if errors.Is(err, domain.ErrAPIKeyNotfound) {
+		return domain.Principal{}, fmt.Errorf("%w: unknown api key", domain.ErrUnauthenticated)
+	}
+	if err != nil {
+		return domain.Principal{}, err
+	}
+
+	now := as.now().UTC()
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(key)), []byte(apiKey.Hash)) != 1 {
+		return domain.Principal{}, fmt.Errorf("%w: unknown api key", domain.ErrUnauthenticated)
+	}
+	if !apiKey.Active(now) {
+		return domain.Principal{}, fmt.Errorf("%w: api key revoked or expired", domain.ErrUnauthenticated)
+	}
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= apiKeyTouchInterval {
+		if err := as.keysRepo.Touch(ctx, apiKey.ID, now); err != nil {
+			return domain.Principal{}, err
+		}
+	}
+	return domain.Principal{
+		Subject: "api-key:" + apiKey.ID.String(),
+		Roles:   apiKey.Scopes,
+	}, nil
+}
+
+// hashAPIKey returns the hex encoded SHA-256 of a key. The keys being long random strings,
+// a slow password hash isn't needed.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString encodes n random bytes.
+func randomString(n int, encode func([]byte) string) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return encode(b), nil
+}
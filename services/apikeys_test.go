@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/repositories"
+	"github.com/goakshit/isildur/repositories/memory"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type APIKeyServiceTestSuite struct {
+	suite.Suite
+	repos   repositories.Repositories
+	service *APIKeyService
+	today   time.Time
+}
+
+func TestAPIKeyServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(APIKeyServiceTestSuite))
+}
+
+func (ts *APIKeyServiceTestSuite) SetupTest() {
+	ts.repos = memory.NewRepositories(memory.NewStore())
+	ts.service = NewAPIKeyService(ts.repos.APIKeys)
+	ts.today = date(time.June, 1)
+	ts.service.now = func() time.Time { return ts.today }
+}
+
+func (ts *APIKeyServiceTestSuite) create(scopes ...domain.Role) domain.NewAPIKey {
+	key, err := ts.service.CreateAPIKey(context.Background(), "Partner gym", scopes, nil)
+	ts.Require().Nil(err)
+	return key
+}
+
+func (ts *APIKeyServiceTestSuite) TestAPIKeyService_CreateAPIKey() {
+	ctx := context.Background()
+	expiresAt := date(time.December, 31)
+	key, err := ts.service.CreateAPIKey(ctx, " Partner gym ", []domain.Role{domain.RoleSupport}, &expiresAt)
+	ts.Require().Nil(err)
+	ts.Assert().Equal("Partner gym", key.Name)
+	ts.Assert().True(strings.HasPrefix(key.Key, domain.APIKeyPrefix+key.Prefix+"_"))
+	ts.Assert().Len(key.Prefix, 16)
+
+	// Only the hash is stored
+	stored, err := ts.repos.APIKeys.GetByPrefix(ctx, key.Prefix)
+	ts.Require().Nil(err)
+	ts.Assert().NotContains(stored.Hash, key.Key[len(domain.APIKeyPrefix+key.Prefix+"_"):])
+	ts.Assert().Equal(hashAPIKey(key.Key), stored.Hash)
+
+	yesterday := date(time.May, 31)
+	tc := []struct {
+		Name      string
+		ctx       context.Context
+		name      string
+		scopes    []domain.Role
+		expiresAt *time.Time
+		err       error
+	}{
+		{Name: "Missing name", ctx: ctx, name: " ", scopes: []domain.Role{domain.RoleAdmin}, err: domain.ErrInvalidAPIKey},
+		{Name: "Missing scopes", ctx: ctx, name: "Partner gym", err: domain.ErrInvalidAPIKey},
+		{Name: "Unknown scope", ctx: ctx, name: "Partner gym", scopes: []domain.Role{"root"}, err: domain.ErrInvalidAPIKey},
+		{Name: "Expired", ctx: ctx, name: "Partner gym", scopes: []domain.Role{domain.RoleAdmin}, expiresAt: &yesterday, err: domain.ErrInvalidAPIKey},
+		{Name: "Not an admin", ctx: asRoles(domain.RoleSupport), name: "Partner gym", scopes: []domain.Role{domain.RoleAdmin}, err: domain.ErrForbidden},
+	}
+	for _, tt := range tc {
+		ts.Run(tt.Name, func() {
+			_, err := ts.service.CreateAPIKey(tt.ctx, tt.name, tt.scopes, tt.expiresAt)
+			ts.Assert().ErrorIs(err, tt.err)
+		})
+	}
+}
+
+func (ts *APIKeyServiceTestSuite) TestAPIKeyService_AuthenticateAPIKey() {
+	ctx := context.Background()
+	key := ts.create(domain.RoleSupport)
+
+	principal, err := ts.service.AuthenticateAPIKey(ctx, key.Key)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(domain.Principal{
+		Subject: "api-key:" + key.ID.String(),
+		Roles:   []domain.Role{domain.RoleSupport},
+	}, principal)
+
+	tc := []struct {
+		Name string
+		key  string
+	}{
+		{Name: "Wrong secret", key: domain.APIKeyPrefix + key.Prefix + "_wrong"},
+		{Name: "Unknown prefix", key: domain.APIKeyPrefix + "00000000_secret"},
+		{Name: "Malformed key", key: "a1b2c3d4"},
+		{Name: "Missing secret", key: domain.APIKeyPrefix + key.Prefix},
+	}
+	for _, tt := range tc {
+		ts.Run(tt.Name, func() {
+			_, err := ts.service.AuthenticateAPIKey(ctx, tt.key)
+			ts.Assert().ErrorIs(err, domain.ErrUnauthenticated)
+		})
+	}
+}
+
+func (ts *APIKeyServiceTestSuite) TestAPIKeyService_LastUsed() {
+	ctx := context.Background()
+	key := ts.create(domain.RoleCustomer)
+	lastUsed := func() *time.Time {
+		stored, err := ts.repos.APIKeys.GetByPrefix(ctx, key.Prefix)
+		ts.Require().Nil(err)
+		return stored.LastUsedAt
+	}
+	ts.Assert().Nil(lastUsed())
+
+	firstUse := ts.today
+	_, err := ts.service.AuthenticateAPIKey(ctx, key.Key)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(firstUse, *lastUsed())
+
+	// Not recorded again within the touch interval
+	ts.today = firstUse.Add(apiKeyTouchInterval / 2)
+	_, err = ts.service.AuthenticateAPIKey(ctx, key.Key)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(firstUse, *lastUsed())
+
+	ts.today = firstUse.Add(apiKeyTouchInterval)
+	_, err = ts.service.AuthenticateAPIKey(ctx, key.Key)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(ts.today, *lastUsed())
+}
+
+func (ts *APIKeyServiceTestSuite) TestAPIKeyService_ExpiryAndRevocation() {
+	ctx := context.Background()
+	expiresAt := date(time.July, 1)
+	expiring, err := ts.service.CreateAPIKey(ctx, "Expiring", []domain.Role{domain.RoleCustomer}, &expiresAt)
+	ts.Require().Nil(err)
+	revoked := ts.create(domain.RoleAdmin)
+
+	ts.Require().Nil(ts.service.RevokeAPIKey(ctx, revoked.ID))
+	_, err = ts.service.AuthenticateAPIKey(ctx, revoked.Key)
+	ts.Assert().ErrorIs(err, domain.ErrUnauthenticated)
+
+	_, err = ts.service.AuthenticateAPIKey(ctx, expiring.Key)
+	ts.Assert().Nil(err)
+	ts.today = expiresAt
+	_, err = ts.service.AuthenticateAPIKey(ctx, expiring.Key)
+	ts.Assert().ErrorIs(err, domain.ErrUnauthenticated)
+
+	// Revoked and expired keys are still listed
+	keys, err := ts.service.ListAPIKeys(ctx)
+	ts.Require().Nil(err)
+	ts.Assert().Len(keys, 2)
+
+	ts.Assert().ErrorIs(ts.service.RevokeAPIKey(ctx, uuid.New()), domain.ErrAPIKeyNotfound)
+	ts.Assert().ErrorIs(ts.service.RevokeAPIKey(asRoles(domain.RoleSupport), revoked.ID), domain.ErrForbidden)
+	_, err = ts.service.ListAPIKeys(asRoles(domain.RoleCustomer))
+	ts.Assert().ErrorIs(err, domain.ErrForbidden)
+}
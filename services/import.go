@@ -0,0 +1,335 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/constants"
+	"github.com/google/uuid"
+)
+
+var _ ports.SubscriptionImporter = (*ImportService)(nil)
+
+// defaultImportBatchSize is the number of subscriptions inserted per transaction by default.
+const defaultImportBatchSize = 500
+
+// importDateFormats are the accepted start date formats, the api one and ISO 8601.
+var importDateFormats = []string{constants.DateFormat, "2006-01-02"}
+
+// ImportService represents required dependencies for importing subscriptions.
+type ImportService struct {
+	prodRepo ports.ProductsRepository
+	subsRepo ports.SubscriptionsRepository
+	tx       ports.TxManager
+	revenue  ports.RevenueRecognizer
+	metrics  ports.Metrics
+}
+
+// NewImportService
+func NewImportService(
+	s ports.SubscriptionsRepository,
+	p ports.ProductsRepository,
+	tx ports.TxManager,
+	r ports.RevenueRecognizer,
+	m ports.Metrics,
+) *ImportService {
+	return &ImportService{
+		subsRepo: s,
+		prodRepo: p,
+		tx:       tx,
+		revenue:  r,
+		metrics:  m,
+	}
+}
+
+// importRow represents a single subscription row to import.
+type importRow struct {
+	ProductID        string `json:"product_id"`
+	StartDate        string `json:"start_date"`
+	DurationInMonths string `json:"duration_in_months"`
+	Status           string `json:"status"`
+	CustomerID       string `json:"customer_id"`
+}
+
+// UnmarshalJSON accepts the duration both as a number and as a string.
+func (r *importRow) UnmarshalJSON(data []byte) error {
+	type alias importRow
+	aux := struct {
+		*alias
+		DurationInMonths json.RawMessage `json:"duration_in_months"`
+	}{alias: (*alias)(r)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	r.DurationInMonths = strings.Trim(string(aux.DurationInMonths), `"`)
+	return nil
+}
+
+// ImportSubscriptions validates every row with the same rules as CreateSubscription and
+// inserts the valid ones in batches, one transaction per batch, a failed batch being retried
+// row by row. Invalid rows are reported and skipped. An error is only returned when the input can't be read at all, or when the
+// caller isn't an admin.
+func (is ImportService) ImportSubscriptions(
+	ctx context.Context,
+	r io.Reader,
+	opts domain.ImportOptions,
+) (_ domain.ImportReport, err error) {
+	ctx, end := startSpan(ctx, "ImportService.ImportSubscriptions")
+	defer end(&err)
+	report := domain.ImportReport{
+		DryRun: opts.DryRun,
+		Errors: []domain.ImportRowError{},
+	}
+	if err := authorize(ctx, actionImportSubscriptions); err != nil {
+		return report, err
+	}
+	next, err := newRowReader(r, opts.Format)
+	if err != nil {
+		return report, err
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+
+	products := map[uuid.UUID]domain.Product{}
+	var batch []domain.Subscription
+	var batchLines []int
+	// record reports the subscriptions of the lines as imported, or as failed with err
+	record := func(subs []domain.Subscription, lines []int, err error) {
+		if err != nil {
+			for _, line := range lines {
+				report.Errors = append(report.Errors, domain.ImportRowError{Line: line, Error: err.Error()})
+			}
+			report.Failed += len(subs)
+			return
+		}
+		report.Imported += len(subs)
+		countCreated(is.metrics, subs)
+	}
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := is.insert(ctx, batch); err != nil && len(batch) > 1 {
+			// A single row usually fails the whole batch, the rows are retried one by one to
+			// report it and import the others
+			for i := range batch {
+				record(batch[i:i+1], batchLines[i:i+1], is.insert(ctx, batch[i:i+1]))
+			}
+		} else {
+			record(batch, batchLines, err)
+		}
+		batch, batchLines = batch[:0], batchLines[:0]
+	}
+
+	for {
+		line, row, err, readErr := next()
+		if errors.Is(readErr, io.EOF) {
+			break
+		} else if readErr != nil {
+			return report, readErr
+		}
+		report.Total++
+		if err == nil {
+			var sub domain.Subscription
+			if sub, err = is.validateRow(ctx, row, opts, products); err == nil {
+				if opts.DryRun {
+					report.Imported++
+					continue
+				}
+				batch, batchLines = append(batch, sub), append(batchLines, line)
+				if len(batch) >= opts.BatchSize {
+					flush()
+				}
+				continue
+			}
+		}
+		report.Failed++
+		report.Errors = append(report.Errors, domain.ImportRowError{Line: line, Error: err.Error()})
+	}
+	flush()
+	return report, nil
+}
+
+// insert creates the subscriptions and schedules their revenue in a single transaction.
+func (is ImportService) insert(ctx context.Context, subs []domain.Subscription) error {
+	return is.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := is.subsRepo.CreateBatch(ctx, subs); err != nil {
+			return err
+		}
+		return is.revenue.ScheduleRevenue(ctx, subs, time.Now().UTC())
+	})
+}
+
+// validateRow parses a row and applies the subscription business rules on it.
+// Products are cached in the given map, as most rows share a handful of them.
+func (is ImportService) validateRow(
+	ctx context.Context,
+	row importRow,
+	opts domain.ImportOptions,
+	products map[uuid.UUID]domain.Product,
+) (domain.Subscription, error) {
+	pID, err := uuid.Parse(strings.TrimSpace(row.ProductID))
+	if err != nil || pID == uuid.Nil {
+		return domain.Subscription{}, domain.ErrProductIDIsInvalid
+	}
+	startDate, err := parseImportDate(strings.TrimSpace(row.StartDate))
+	if err != nil {
+		return domain.Subscription{}, domain.ErrInvalidStartDate
+	}
+	duration, err := strconv.ParseInt(strings.TrimSpace(row.DurationInMonths), 10, 8)
+	if err != nil || duration <= 0 {
+		return domain.Subscription{}, domain.ErrInvalidSubscriptionDuration
+	}
+	customerID := strings.TrimSpace(row.CustomerID)
+	if customerID == "" {
+		return domain.Subscription{}, domain.ErrInvalidCustomerID
+	}
+
+	status, err := initialStatus(startDate, opts.AllowPastStartDate)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	if s := strings.TrimSpace(row.Status); s != "" {
+		if status = domain.MapStringToSubscriptionStatus(s); status == "" {
+			return domain.Subscription{}, domain.ErrInvalidSubscriptionStatusPassed
+		}
+	}
+
+	product, ok := products[pID]
+	if !ok {
+		if product, err = is.prodRepo.GetByID(ctx, pID); err != nil {
+			return domain.Subscription{}, err
+		}
+		products[pID] = product
+	}
+
+	sub := newSubscription(product, int8(duration), startDate, status)
+	sub.CustomerID = customerID
+	// The past subscriptions already ended are imported inactive, unless their status is given
+	if strings.TrimSpace(row.Status) == "" && !sub.EndDate.After(time.Now()) {
+		sub.Status = domain.SubscriptionStatusInactive
+	}
+	return sub, nil
+}
+
+func parseImportDate(value string) (time.Time, error) {
+	var err error
+	for _, layout := range importDateFormats {
+		var date time.Time
+		if date, err = time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+	return time.Time{}, err
+}
+
+// rowReader returns the next row with its line number, or the error which made the row
+// unreadable. readErr is io.EOF once done, or the error which stopped the reading.
+type rowReader func() (line int, row importRow, err error, readErr error)
+
+// newRowReader returns a rowReader for the given format.
+func newRowReader(r io.Reader, format string) (rowReader, error) {
+	switch format {
+	case domain.ImportFormatCSV, "":
+		return newCSVRowReader(r)
+	case domain.ImportFormatJSONL:
+		return newJSONLRowReader(r), nil
+	default:
+		return nil, fmt.Errorf("%w: %q", domain.ErrInvalidImportFormat, format)
+	}
+}
+
+// newCSVRowReader reads csv rows, mapping the columns through the header line.
+func newCSVRowReader(r io.Reader) (rowReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read csv header: %v", domain.ErrInvalidImportFormat, err)
+	}
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"product_id", "start_date", "duration_in_months", "customer_id"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("%w: missing csv column %s", domain.ErrInvalidImportFormat, required)
+		}
+	}
+
+	return func() (int, importRow, error, error) {
+		record, err := reader.Read()
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return parseErr.StartLine, importRow{}, err, nil
+		} else if err != nil {
+			return 0, importRow{}, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return record[i]
+			}
+			return ""
+		}
+		return line, importRow{
+			ProductID:        field("product_id"),
+			StartDate:        field("start_date"),
+			DurationInMonths: field("duration_in_months"),
+			Status:           field("status"),
+			CustomerID:       field("customer_id"),
+		}, nil, nil
+	}, nil
+}
+
+// newJSONLRowReader reads one json row per line, skipping blank lines.
+func newJSONLRowReader(r io.Reader) rowReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	return func() (int, importRow, error, error) {
+		for scanner.Scan() {
+			line++
+			text := strings.TrimSpace(scanner.Text())
+			if text == "" {
+				continue
+			}
+			var row importRow
+			if err := json.Unmarshal([]byte(text), &row); err != nil {
+				return line, importRow{}, fmt.Errorf("invalid json: %v", err), nil
+			}
+			return line, row, nil, nil
+		}
+		if err := scanner.Err(); err != nil {
+			return 0, importRow{}, nil, fmt.Errorf("failed to read line %d: %w", line+1, err)
+		}
+		return 0, importRow{}, nil, io.EOF
+	}
+}
+
+// WriteImportErrors writes the failed rows of a report as csv.
+func WriteImportErrors(w io.Writer, report domain.ImportReport) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"line", "error"}); err != nil {
+		return err
+	}
+	for _, rowErr := range report.Errors {
+		if err := writer.Write([]string{strconv.Itoa(rowErr.Line), rowErr.Error}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
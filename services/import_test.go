@@ -0,0 +1,217 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/constants"
+	"github.com/goakshit/isildur/repositories"
+	"github.com/goakshit/isildur/repositories/memory"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type ImportServiceTestSuite struct {
+	suite.Suite
+	product domain.Product
+	repos   repositories.Repositories
+	metrics *ports.MockMetrics
+	service *ImportService
+}
+
+func TestImportServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ImportServiceTestSuite))
+}
+
+func (ts *ImportServiceTestSuite) SetupTest() {
+	ts.product = domain.Product{
+		ID:             uuid.New(),
+		Name:           "YOGA 1",
+		Description:    "BASIC YOGA",
+		MonthlyPrice:   5,
+		InstructorName: "A. Dhar",
+	}
+	store := memory.NewStore()
+	store.Seed(memory.Fixtures{Products: []domain.Product{ts.product}})
+	ts.repos = memory.NewRepositories(store)
+	ts.metrics = ports.NewMockMetrics(gomock.NewController(ts.T()))
+	recognition := NewRecognitionService(ts.repos.RevenueEntries, ts.repos.Subscriptions, ts.repos.Tx, ts.metrics)
+	ts.service = NewImportService(ts.repos.Subscriptions, ts.repos.Products, ts.repos.Tx, recognition, ts.metrics)
+}
+
+func (ts *ImportServiceTestSuite) count() int {
+	subs, err := ts.repos.Subscriptions.List(context.Background(), domain.SubscriptionFilter{})
+	ts.Require().Nil(err)
+	return len(subs)
+}
+
+func (ts *ImportServiceTestSuite) TestImportSubscriptions_CSV() {
+	tomorrow := time.Now().AddDate(0, 0, 2).Format(constants.DateFormat)
+	pID := ts.product.ID.String()
+	input := "product_id,start_date,duration_in_months,status,customer_id\n" +
+		pID + "," + tomorrow + ",3,,customer-1\n" +
+		pID + ",2020-01-01,12,cancelled,customer-2\n" +
+		"not-a-uuid," + tomorrow + ",3,,customer-3\n" +
+		uuid.NewString() + "," + tomorrow + ",3,,customer-4\n" +
+		pID + "," + tomorrow + ",0,,customer-5\n" +
+		pID + "," + tomorrow + ",3,expired,customer-6\n" +
+		pID + "," + tomorrow + ",3,,\n"
+
+	ts.metrics.EXPECT().SubscriptionsCreated(ts.product.ID, domain.SubscriptionStatusInactive, 1).Times(1)
+	report, err := ts.service.ImportSubscriptions(context.Background(), strings.NewReader(input), domain.ImportOptions{
+		Format: domain.ImportFormatCSV,
+	})
+	ts.Require().Nil(err)
+	ts.Assert().Equal(7, report.Total)
+	ts.Assert().Equal(1, report.Imported)
+	ts.Assert().Equal(6, report.Failed)
+	ts.Assert().Equal([]domain.ImportRowError{
+		{Line: 3, Error: domain.ErrInvalidStartDate.Error()},
+		{Line: 4, Error: domain.ErrProductIDIsInvalid.Error()},
+		{Line: 5, Error: domain.ErrProductNotfound.Error()},
+		{Line: 6, Error: domain.ErrInvalidSubscriptionDuration.Error()},
+		{Line: 7, Error: domain.ErrInvalidSubscriptionStatusPassed.Error()},
+		{Line: 8, Error: domain.ErrInvalidCustomerID.Error()},
+	}, report.Errors)
+
+	subs, err := ts.repos.Subscriptions.List(context.Background(), domain.SubscriptionFilter{})
+	ts.Require().Nil(err)
+	ts.Require().Len(subs, 1)
+	ts.Assert().Equal("customer-1", subs[0].CustomerID)
+	ts.Assert().Equal(domain.SubscriptionStatusInactive, subs[0].Status)
+	ts.Assert().InDelta(16.05, subs[0].TotalCost, 0.001)
+
+	// The revenue of the imported subscriptions is scheduled with them
+	entries, err := ts.repos.RevenueEntries.ListBySubscription(context.Background(), subs[0].ID)
+	ts.Require().Nil(err)
+	ts.Assert().Len(entries, 3)
+}
+
+func (ts *ImportServiceTestSuite) TestImportSubscriptions_JSONLAllowPastStartDate() {
+	pID := ts.product.ID.String()
+	input := `{"product_id":"` + pID + `","start_date":"2020-01-01","duration_in_months":12,"status":"cancelled","customer_id":"customer-1"}` + "\n" +
+		"\n" +
+		`{"product_id":"` + pID + `","start_date":"01-02-2020","duration_in_months":"6","customer_id":"customer-2"}` + "\n" +
+		`{"product_id":` + "\n"
+
+	// Counted once every batch is committed
+	ts.metrics.EXPECT().SubscriptionsCreated(ts.product.ID, domain.SubscriptionStatusCancel, 1).Times(1)
+	ts.metrics.EXPECT().SubscriptionsCreated(ts.product.ID, domain.SubscriptionStatusInactive, 1).Times(1)
+	report, err := ts.service.ImportSubscriptions(context.Background(), strings.NewReader(input), domain.ImportOptions{
+		Format:             domain.ImportFormatJSONL,
+		AllowPastStartDate: true,
+		BatchSize:          1,
+	})
+	ts.Require().Nil(err)
+	ts.Assert().Equal(3, report.Total)
+	ts.Assert().Equal(2, report.Imported)
+	ts.Require().Len(report.Errors, 1)
+	ts.Assert().Equal(4, report.Errors[0].Line)
+	ts.Assert().Equal(2, ts.count())
+}
+
+func (ts *ImportServiceTestSuite) TestImportSubscriptions_PastStatus() {
+	pID := ts.product.ID.String()
+	lastMonth := time.Now().AddDate(0, -1, 0).Format(constants.DateFormat)
+	input := "customer_id,product_id,start_date,duration_in_months,status\n" +
+		"ended," + pID + ",2020-01-01,3,\n" +
+		"running," + pID + "," + lastMonth + ",3,\n" +
+		"cancelled," + pID + ",2020-01-01,3,cancelled\n"
+
+	ts.metrics.EXPECT().SubscriptionsCreated(ts.product.ID, gomock.Any(), 1).Times(3)
+	report, err := ts.service.ImportSubscriptions(context.Background(), strings.NewReader(input), domain.ImportOptions{
+		AllowPastStartDate: true,
+	})
+	ts.Require().Nil(err)
+	ts.Require().Equal(3, report.Imported)
+
+	subs, err := ts.repos.Subscriptions.List(context.Background(), domain.SubscriptionFilter{})
+	ts.Require().Nil(err)
+	statuses := map[string]domain.SubscriptionStatus{}
+	for _, sub := range subs {
+		statuses[sub.CustomerID] = sub.Status
+	}
+	// The ended subscriptions are inactive, unless their status is given
+	ts.Assert().Equal(map[string]domain.SubscriptionStatus{
+		"ended":     domain.SubscriptionStatusInactive,
+		"running":   domain.SubscriptionStatusActive,
+		"cancelled": domain.SubscriptionStatusCancel,
+	}, statuses)
+}
+
+// failingSubscriptions fails to create the batches holding a subscription of customerID.
+type failingSubscriptions struct {
+	ports.SubscriptionsRepository
+	customerID string
+}
+
+func (r failingSubscriptions) CreateBatch(ctx context.Context, subs []domain.Subscription) error {
+	for _, sub := range subs {
+		if sub.CustomerID == r.customerID {
+			return errors.New("value too long for type character varying")
+		}
+	}
+	return r.SubscriptionsRepository.CreateBatch(ctx, subs)
+}
+
+func (ts *ImportServiceTestSuite) TestImportSubscriptions_FailedBatch() {
+	subsRepo := failingSubscriptions{SubscriptionsRepository: ts.repos.Subscriptions, customerID: "customer-2"}
+	recognition := NewRecognitionService(ts.repos.RevenueEntries, subsRepo, ts.repos.Tx, ts.metrics)
+	service := NewImportService(subsRepo, ts.repos.Products, ts.repos.Tx, recognition, ts.metrics)
+	tomorrow := time.Now().AddDate(0, 0, 2).Format(constants.DateFormat)
+	input := "customer_id,product_id,start_date,duration_in_months\n"
+	for _, customerID := range []string{"customer-1", "customer-2", "customer-3"} {
+		input += customerID + "," + ts.product.ID.String() + "," + tomorrow + ",3\n"
+	}
+
+	// The batch is retried row by row, only the row failing it is reported
+	ts.metrics.EXPECT().SubscriptionsCreated(ts.product.ID, domain.SubscriptionStatusInactive, 1).Times(2)
+	report, err := service.ImportSubscriptions(context.Background(), strings.NewReader(input), domain.ImportOptions{})
+	ts.Require().Nil(err)
+	ts.Assert().Equal(2, report.Imported)
+	ts.Assert().Equal(1, report.Failed)
+	ts.Assert().Equal([]domain.ImportRowError{
+		{Line: 3, Error: "value too long for type character varying"},
+	}, report.Errors)
+	ts.Assert().Equal(2, ts.count())
+}
+
+func (ts *ImportServiceTestSuite) TestImportSubscriptions_DryRun() {
+	input := "customer_id,product_id,start_date,duration_in_months\n" +
+		"customer-1," + ts.product.ID.String() + ",2020-01-01,3\n"
+
+	report, err := ts.service.ImportSubscriptions(context.Background(), strings.NewReader(input), domain.ImportOptions{
+		DryRun:             true,
+		AllowPastStartDate: true,
+	})
+	ts.Require().Nil(err)
+	ts.Assert().True(report.DryRun)
+	ts.Assert().Equal(1, report.Imported)
+	ts.Assert().Equal(0, ts.count())
+}
+
+func (ts *ImportServiceTestSuite) TestImportSubscriptions_InvalidInput() {
+	_, err := ts.service.ImportSubscriptions(context.Background(), strings.NewReader("a,b\n"), domain.ImportOptions{})
+	ts.Assert().ErrorIs(err, domain.ErrInvalidImportFormat)
+
+	_, err = ts.service.ImportSubscriptions(context.Background(), strings.NewReader(""), domain.ImportOptions{
+		Format: "xml",
+	})
+	ts.Assert().ErrorIs(err, domain.ErrInvalidImportFormat)
+}
+
+func (ts *ImportServiceTestSuite) TestWriteImportErrors() {
+	var buf bytes.Buffer
+	err := WriteImportErrors(&buf, domain.ImportReport{Errors: []domain.ImportRowError{
+		{Line: 3, Error: "invalid start date"},
+	}})
+	ts.Require().Nil(err)
+	ts.Assert().Equal("line,error\n3,invalid start date\n", buf.String())
+}
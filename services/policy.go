@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+
+	"github.com/goakshit/isildur/core/domain"
+)
+
+// action is an operation restricted to some roles.
+type action string
+
+const (
+	// actionReadAnySubscription reads, lists and updates the subscriptions of any customer.
+	actionReadAnySubscription action = "subscriptions:read_any"
+	// actionOverrideStatus sets a subscription to any status, see customerCanSetStatus.
+	actionOverrideStatus action = "subscriptions:override_status"
+	// actionExportSubscriptions exports the subscriptions of every customer.
+	actionExportSubscriptions action = "subscriptions:export"
+	// actionImportSubscriptions imports subscriptions for any customer.
+	actionImportSubscriptions action = "subscriptions:import"
+	// actionRefundSubscription cancels and refunds a subscription.
+	actionRefundSubscription action = "subscriptions:refund"
+	// actionManageProducts creates and updates the products.
+	actionManageProducts action = "products:manage"
+	// actionViewReports reads the revenue, activity and recognition reports.
+	actionViewReports action = "reports:view"
+	// actionManageAPIKeys creates, lists and revokes the API keys of the partners.
+	actionManageAPIKeys action = "api_keys:manage"
+)
+
+// policy lists the roles allowed to perform each action. Customers can only manage their own
+// subscriptions.
+var policy = map[action][]domain.Role{
+	actionReadAnySubscription: {domain.RoleSupport, domain.RoleAdmin},
+	actionOverrideStatus:      {domain.RoleSupport, domain.RoleAdmin},
+	actionExportSubscriptions: {domain.RoleSupport, domain.RoleAdmin},
+	actionRefundSubscription:  {domain.RoleSupport, domain.RoleAdmin},
+	actionImportSubscriptions: {domain.RoleAdmin},
+	actionManageProducts:      {domain.RoleAdmin},
+	actionViewReports:         {domain.RoleAdmin},
+	actionManageAPIKeys:       {domain.RoleAdmin},
+}
+
+// authorize returns domain.ErrForbidden unless the caller has a role allowed to perform the
+// action. Calls without a principal, e.g. from the admin CLI, are trusted.
+func authorize(ctx context.Context, a action) error {
+	p, ok := domain.PrincipalFromContext(ctx)
+	if !ok || p.HasRole(policy[a]...) {
+		return nil
+	}
+	return domain.ErrForbidden
+}
+
+// customerCanSetStatus reports whether customers may move their own subscription from a status
+// to another: pausing, resuming and cancelling it. Other changes are staff overrides.
+func customerCanSetStatus(from, to domain.SubscriptionStatus) bool {
+	switch to {
+	case domain.SubscriptionStatusCancel:
+		return true
+	case domain.SubscriptionStatusPaused:
+		return from == domain.SubscriptionStatusActive
+	case domain.SubscriptionStatusActive:
+		return from == domain.SubscriptionStatusPaused
+	}
+	return false
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/stretchr/testify/suite"
+)
+
+type PolicyTestSuite struct {
+	suite.Suite
+}
+
+func TestPolicyTestSuite(t *testing.T) {
+	suite.Run(t, new(PolicyTestSuite))
+}
+
+// asRoles returns a context authenticated as customer-1 with the roles.
+func asRoles(roles ...domain.Role) context.Context {
+	return domain.ContextWithPrincipal(context.Background(), domain.Principal{Subject: "customer-1", Roles: roles})
+}
+
+func (ts *PolicyTestSuite) TestAuthorize() {
+	tc := []struct {
+		Name    string
+		ctx     context.Context
+		action  action
+		allowed bool
+	}{
+		{
+			Name:    "No principal is trusted",
+			ctx:     context.Background(),
+			action:  actionManageProducts,
+			allowed: true,
+		},
+		{
+			Name:   "Customer can't export",
+			ctx:    asRoles(domain.RoleCustomer),
+			action: actionExportSubscriptions,
+		},
+		{
+			Name:    "Support can export",
+			ctx:     asRoles(domain.RoleSupport),
+			action:  actionExportSubscriptions,
+			allowed: true,
+		},
+		{
+			Name:   "Support can't manage products",
+			ctx:    asRoles(domain.RoleSupport),
+			action: actionManageProducts,
+		},
+		{
+			Name:    "Admin can manage products",
+			ctx:     asRoles(domain.RoleCustomer, domain.RoleAdmin),
+			action:  actionManageProducts,
+			allowed: true,
+		},
+		{
+			Name:   "Principal without roles",
+			ctx:    asRoles(),
+			action: actionViewReports,
+		},
+	}
+
+	for _, tt := range tc {
+		ts.Run(tt.Name, func() {
+			err := authorize(tt.ctx, tt.action)
+			if tt.allowed {
+				ts.Assert().Nil(err)
+			} else {
+				ts.Assert().ErrorIs(err, domain.ErrForbidden)
+			}
+		})
+	}
+}
+
+func (ts *PolicyTestSuite) TestCustomerCanSetStatus() {
+	tc := []struct {
+		Name    string
+		from    domain.SubscriptionStatus
+		to      domain.SubscriptionStatus
+		allowed bool
+	}{
+		{Name: "Pause", from: domain.SubscriptionStatusActive, to: domain.SubscriptionStatusPaused, allowed: true},
+		{Name: "Resume", from: domain.SubscriptionStatusPaused, to: domain.SubscriptionStatusActive, allowed: true},
+		{Name: "Cancel", from: domain.SubscriptionStatusInactive, to: domain.SubscriptionStatusCancel, allowed: true},
+		{Name: "Activate before the start date", from: domain.SubscriptionStatusInactive, to: domain.SubscriptionStatusActive},
+		{Name: "Pause before the start date", from: domain.SubscriptionStatusInactive, to: domain.SubscriptionStatusPaused},
+		{Name: "Deactivate", from: domain.SubscriptionStatusActive, to: domain.SubscriptionStatusInactive},
+	}
+
+	for _, tt := range tc {
+		ts.Run(tt.Name, func() {
+			ts.Assert().Equal(tt.allowed, customerCanSetStatus(tt.from, tt.to))
+		})
+	}
+}
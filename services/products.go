@@ -2,10 +2,15 @@ package services
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/goakshit/isildur/core/domain"
 	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/constants"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var _ ports.ProductsService = (*ProductsService)(nil)
@@ -25,14 +30,95 @@ func NewProductsService(
 }
 
 // FetchAllProduct fetches all the products in the database.
-func (p ProductsService) FetchAllProducts(ctx context.Context) ([]domain.Product, error) {
+func (p ProductsService) FetchAllProducts(ctx context.Context) (_ []domain.Product, err error) {
+	ctx, end := startSpan(ctx, "ProductsService.FetchAllProducts")
+	defer end(&err)
 	return p.ProductsRepo.GetAll(ctx)
 }
 
+// maxSearchLength bounds the text searched in the products.
+const maxSearchLength = 200
+
+// productsQuerySpec declares the sorts of the products list.
+var productsQuerySpec = QuerySpec{
+	Sorts: []string{string(domain.ProductSortName), string(domain.ProductSortPrice)},
+}
+
+// ParseProductQuery parses and validates the search, the filters, the sort and the page of the
+// products list from the q, instructor, min_price, max_price, sort, limit and offset query
+// parameters. The first page of constants.DefaultPageSize products is listed unless a limit is
+// passed.
+func ParseProductQuery(values url.Values) (domain.ProductQuery, error) {
+	q := productsQuerySpec.Parse(values)
+	query := domain.ProductQuery{
+		Search:     q.String("q"),
+		Instructor: q.String("instructor"),
+		MinPrice:   q.Float("min_price"),
+		MaxPrice:   q.Float("max_price"),
+	}
+	if len(query.Search) > maxSearchLength {
+		q.fail(fmt.Errorf("%w: q must not exceed %d characters", domain.ErrInvalidQuery, maxSearchLength))
+	}
+	if query.MaxPrice > 0 && query.MinPrice > query.MaxPrice {
+		q.fail(fmt.Errorf("%w: min_price and max_price must be an increasing range", domain.ErrInvalidQuery))
+	}
+	sort, descending := q.Sort()
+	query.Sort, query.Descending = domain.ProductSort(sort), descending
+	query.Limit, query.Offset = q.Page()
+	if query.Limit == 0 {
+		query.Limit = constants.DefaultPageSize
+	}
+	return query, q.Err()
+}
+
+// SearchProducts fetches a page of the products matching a query, e.g. parsed by
+// ParseProductQuery. The first page of constants.DefaultPageSize products is listed unless a
+// limit is passed, bounded by constants.MaxPageSize.
+func (p ProductsService) SearchProducts(ctx context.Context, query domain.ProductQuery) (_ []domain.Product, err error) {
+	ctx, end := startSpan(ctx, "ProductsService.SearchProducts", attribute.String("products.search", query.Search))
+	defer end(&err)
+	if query.Sort != "" && !productsQuerySpec.allowsSort(string(query.Sort)) {
+		return nil, fmt.Errorf("%w: unknown sort %q", domain.ErrInvalidQuery, query.Sort)
+	}
+	if query.Offset < 0 {
+		return nil, domain.ErrInvalidPagination
+	}
+	if query.Limit <= 0 {
+		query.Limit = constants.DefaultPageSize
+	} else if query.Limit > constants.MaxPageSize {
+		query.Limit = constants.MaxPageSize
+	}
+	return p.ProductsRepo.Search(ctx, query)
+}
+
 // FetchProduct fetches product for a given id.
-func (p ProductsService) FetchProduct(ctx context.Context, id uuid.UUID) (domain.Product, error) {
+func (p ProductsService) FetchProduct(ctx context.Context, id uuid.UUID) (_ domain.Product, err error) {
+	ctx, end := startSpan(ctx, "ProductsService.FetchProduct", attribute.String("product.id", id.String()))
+	defer end(&err)
 	if id == uuid.Nil {
 		return domain.Product{}, domain.ErrProductIDIsInvalid
 	}
 	return p.ProductsRepo.GetByID(ctx, id)
 }
+
+// CreateProduct validates and creates a product, returning it with its new ID.
+// Only admins can create products.
+func (p ProductsService) CreateProduct(ctx context.Context, product domain.Product) (_ domain.Product, err error) {
+	ctx, end := startSpan(ctx, "ProductsService.CreateProduct")
+	defer end(&err)
+	if err := authorize(ctx, actionManageProducts); err != nil {
+		return domain.Product{}, err
+	}
+	product.Name = strings.TrimSpace(product.Name)
+	if product.Name == "" {
+		return domain.Product{}, fmt.Errorf("%w: name is required", domain.ErrInvalidProduct)
+	}
+	if product.MonthlyPrice <= 0 {
+		return domain.Product{}, fmt.Errorf("%w: monthly price must be positive", domain.ErrInvalidProduct)
+	}
+	product.ID = uuid.New()
+	if err := p.ProductsRepo.Create(ctx, product); err != nil {
+		return domain.Product{}, err
+	}
+	return product, nil
+}
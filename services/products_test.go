@@ -6,6 +6,7 @@ import (
 
 	"github.com/goakshit/isildur/core/domain"
 	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/constants"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
@@ -166,3 +167,123 @@ func (ts *ProductsServiceTestSuite) TestProductsService_FetchAllProducts() {
 		})
 	}
 }
+
+func (ts *ProductsServiceTestSuite) TestProductsService_SearchProducts() {
+	ctx := context.Background()
+	products := []domain.Product{{ID: uuid.New(), Name: "YOGA 1", MonthlyPrice: 5}}
+
+	tc := []struct {
+		Name          string
+		query         domain.ProductQuery
+		expectedQuery domain.ProductQuery
+		err           error
+		searchTimes   int
+	}{
+		{
+			Name:          "Search products",
+			query:         domain.ProductQuery{Search: "yoga", Sort: domain.ProductSortPrice, Limit: 10, Offset: 20},
+			expectedQuery: domain.ProductQuery{Search: "yoga", Sort: domain.ProductSortPrice, Limit: 10, Offset: 20},
+			searchTimes:   1,
+		},
+		{
+			Name:          "Search products with default limit",
+			query:         domain.ProductQuery{Search: "yoga"},
+			expectedQuery: domain.ProductQuery{Search: "yoga", Limit: constants.DefaultPageSize},
+			searchTimes:   1,
+		},
+		{
+			Name:          "Search products with negative limit",
+			query:         domain.ProductQuery{Limit: -1},
+			expectedQuery: domain.ProductQuery{Limit: constants.DefaultPageSize},
+			searchTimes:   1,
+		},
+		{
+			Name:          "Search products with limit too large",
+			query:         domain.ProductQuery{Limit: constants.MaxPageSize + 1},
+			expectedQuery: domain.ProductQuery{Limit: constants.MaxPageSize},
+			searchTimes:   1,
+		},
+		{
+			Name:  "Search products: unknown sort",
+			query: domain.ProductQuery{Sort: "rating"},
+			err:   domain.ErrInvalidQuery,
+		},
+		{
+			Name:  "Search products: negative offset",
+			query: domain.ProductQuery{Offset: -1},
+			err:   domain.ErrInvalidPagination,
+		},
+	}
+
+	for _, tt := range tc {
+		ts.Run(tt.Name, func() {
+			ts.productsRepo.EXPECT().
+				Search(gomock.Any(), tt.expectedQuery).
+				Times(tt.searchTimes).
+				Return(products, nil)
+
+			got, err := ts.service.SearchProducts(ctx, tt.query)
+			if tt.err != nil {
+				ts.Assert().ErrorIs(err, tt.err)
+			} else {
+				ts.Assert().Nil(err)
+				ts.Assert().Equal(products, got)
+			}
+		})
+	}
+}
+
+func (ts *ProductsServiceTestSuite) TestProductsService_CreateProduct() {
+	ctx := context.Background()
+
+	tc := []struct {
+		Name        string
+		product     domain.Product
+		err         error
+		createTimes int
+	}{
+		{
+			Name: "Create product success",
+			product: domain.Product{
+				Name:           " PILATES ",
+				Description:    "Pilates lessons",
+				MonthlyPrice:   9.5,
+				InstructorName: "B. Kaur",
+			},
+			createTimes: 1,
+		},
+		{
+			Name: "Create product: missing name",
+			product: domain.Product{
+				MonthlyPrice: 9.5,
+			},
+			err: domain.ErrInvalidProduct,
+		},
+		{
+			Name: "Create product: invalid price",
+			product: domain.Product{
+				Name:         "PILATES",
+				MonthlyPrice: 0,
+			},
+			err: domain.ErrInvalidProduct,
+		},
+	}
+
+	for _, tt := range tc {
+		ts.Run(tt.Name, func() {
+			ts.productsRepo.EXPECT().
+				Create(gomock.Any(), gomock.Any()).
+				Times(tt.createTimes).
+				Return(nil)
+
+			got, err := ts.service.CreateProduct(ctx, tt.product)
+			if tt.err != nil {
+				ts.Assert().ErrorIs(err, tt.err)
+			} else {
+				ts.Assert().Nil(err)
+				ts.Assert().NotEqual(uuid.Nil, got.ID)
+				ts.Assert().Equal("PILATES", got.Name)
+			}
+		})
+	}
+}
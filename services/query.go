@@ -0,0 +1,106 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/platform/constants"
+)
+
+// QuerySpec describes the sort and the pagination of a list, parsed from the query parameters
+// along with its filters by a Query. It is declared once per list, e.g. productsQuerySpec.
+type QuerySpec struct {
+	// Sorts lists the fields the list can be sorted by, passed in the sort parameter and
+	// descending when prefixed with -, e.g. sort=-price.
+	Sorts []string
+}
+
+// allowsSort reports whether the list can be sorted by a field.
+func (s QuerySpec) allowsSort(field string) bool {
+	for _, allowed := range s.Sorts {
+		if field == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Query reads the parameters of a list query, keeping the first invalid one as its error.
+type Query struct {
+	spec   QuerySpec
+	values url.Values
+	err    error
+}
+
+// Parse returns the query reading values, e.g. the query parameters of a request.
+func (s QuerySpec) Parse(values url.Values) *Query {
+	return &Query{spec: s, values: values}
+}
+
+// Err returns the first invalid parameter read, as an ErrInvalidQuery or ErrInvalidPagination.
+func (q *Query) Err() error {
+	return q.err
+}
+
+func (q *Query) fail(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+// String returns a text parameter without its surrounding spaces, empty if missing.
+func (q *Query) String(name string) string {
+	return strings.TrimSpace(q.values.Get(name))
+}
+
+// Float returns a non negative number parameter, 0 if missing.
+func (q *Query) Float(name string) float64 {
+	value := q.String(name)
+	if value == "" {
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || f < 0 {
+		q.fail(fmt.Errorf("%w: %s must be a non negative number", domain.ErrInvalidQuery, name))
+		return 0
+	}
+	return f
+}
+
+// Sort returns the field of the sort parameter, empty if missing, and whether it is descending.
+func (q *Query) Sort() (string, bool) {
+	value := q.String("sort")
+	if value == "" {
+		return "", false
+	}
+	field := strings.TrimPrefix(value, "-")
+	if q.spec.allowsSort(field) {
+		return field, field != value
+	}
+	q.fail(fmt.Errorf("%w: sort must be one of %s, prefixed with - to sort descending",
+		domain.ErrInvalidQuery, strings.Join(q.spec.Sorts, ", ")))
+	return "", false
+}
+
+// Page returns the limit and offset parameters, 0 if missing. The limit is bounded by
+// constants.MaxPageSize.
+func (q *Query) Page() (limit, offset int) {
+	return q.pageParam("limit", constants.MaxPageSize), q.pageParam("offset", -1)
+}
+
+// pageParam returns a non negative pagination parameter, bounded by max unless it is negative.
+func (q *Query) pageParam(name string, max int) int {
+	value := q.String(name)
+	if value == "" {
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 || (max >= 0 && n > max) {
+		q.fail(domain.ErrInvalidPagination)
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/platform/constants"
+	"github.com/stretchr/testify/suite"
+)
+
+type QueryTestSuite struct {
+	suite.Suite
+}
+
+func TestQueryTestSuite(t *testing.T) {
+	suite.Run(t, new(QueryTestSuite))
+}
+
+func (ts *QueryTestSuite) TestParseProductQuery() {
+	tc := []struct {
+		Name          string
+		query         string
+		expectedQuery domain.ProductQuery
+		err           error
+		errMessage    string
+	}{
+		{
+			Name:          "Empty",
+			expectedQuery: domain.ProductQuery{Limit: constants.DefaultPageSize},
+		},
+		{
+			Name:  "Every parameter",
+			query: "q=+basic+yoga+&instructor=A.+Dhar&min_price=2.5&max_price=10&sort=-price&limit=20&offset=40",
+			expectedQuery: domain.ProductQuery{
+				Search:     "basic yoga",
+				Instructor: "A. Dhar",
+				MinPrice:   2.5,
+				MaxPrice:   10,
+				Sort:       domain.ProductSortPrice,
+				Descending: true,
+				Limit:      20,
+				Offset:     40,
+			},
+		},
+		{
+			Name:          "Ascending sort",
+			query:         "sort=name",
+			expectedQuery: domain.ProductQuery{Sort: domain.ProductSortName, Limit: constants.DefaultPageSize},
+		},
+		{
+			Name:          "Single price",
+			query:         "min_price=5&max_price=5",
+			expectedQuery: domain.ProductQuery{MinPrice: 5, MaxPrice: 5, Limit: constants.DefaultPageSize},
+		},
+		{
+			Name:       "Decreasing price range",
+			query:      "min_price=10&max_price=5",
+			err:        domain.ErrInvalidQuery,
+			errMessage: "invalid query: min_price and max_price must be an increasing range",
+		},
+		{
+			Name:       "Search too long",
+			query:      "q=" + strings.Repeat("yoga+", 50),
+			err:        domain.ErrInvalidQuery,
+			errMessage: "invalid query: q must not exceed 200 characters",
+		},
+		{
+			Name:       "Invalid price",
+			query:      "min_price=cheap",
+			err:        domain.ErrInvalidQuery,
+			errMessage: "invalid query: min_price must be a non negative number",
+		},
+		{
+			Name:       "Negative price",
+			query:      "max_price=-1",
+			err:        domain.ErrInvalidQuery,
+			errMessage: "invalid query: max_price must be a non negative number",
+		},
+		{
+			Name:       "Unknown sort",
+			query:      "sort=-rating",
+			err:        domain.ErrInvalidQuery,
+			errMessage: "invalid query: sort must be one of name, price, prefixed with - to sort descending",
+		},
+		{
+			Name:  "Invalid limit",
+			query: "limit=many",
+			err:   domain.ErrInvalidPagination,
+		},
+		{
+			Name:  "Limit too large",
+			query: "limit=1001",
+			err:   domain.ErrInvalidPagination,
+		},
+		{
+			Name:       "First invalid parameter reported",
+			query:      "min_price=cheap&offset=-1",
+			err:        domain.ErrInvalidQuery,
+			errMessage: "invalid query: min_price must be a non negative number",
+		},
+	}
+
+	for _, tt := range tc {
+		ts.Run(tt.Name, func() {
+			values, err := url.ParseQuery(tt.query)
+			ts.Require().Nil(err)
+
+			got, err := ParseProductQuery(values)
+			if tt.err != nil {
+				ts.Assert().ErrorIs(err, tt.err)
+				if tt.errMessage != "" {
+					ts.Assert().EqualError(err, tt.errMessage)
+				}
+				return
+			}
+			ts.Assert().Nil(err)
+			ts.Assert().Equal(tt.expectedQuery, got)
+		})
+	}
+}
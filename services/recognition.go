@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var _ ports.RecognitionService = (*RecognitionService)(nil)
+
+// RecognitionService represents required dependencies for the revenue recognition.
+// The cost of a subscription, before tax, is booked up front and recognized one month at
+// a time as the months are over.
+type RecognitionService struct {
+	entriesRepo ports.RevenueEntriesRepository
+	subsRepo    ports.SubscriptionsRepository
+	tx          ports.TxManager
+	metrics     ports.Metrics
+	now         func() time.Time
+}
+
+// NewRecognitionService
+func NewRecognitionService(
+	r ports.RevenueEntriesRepository,
+	s ports.SubscriptionsRepository,
+	tx ports.TxManager,
+	m ports.Metrics,
+) *RecognitionService {
+	return &RecognitionService{
+		entriesRepo: r,
+		subsRepo:    s,
+		tx:          tx,
+		metrics:     m,
+		now:         time.Now,
+	}
+}
+
+// ScheduleRevenue books the monthly revenue entries of new subscriptions. Subscriptions
+// created cancelled or paused, e.g. imported ones, are adjusted as of their booking date.
+func (rs RecognitionService) ScheduleRevenue(ctx context.Context, subs []domain.Subscription, bookedOn time.Time) (err error) {
+	ctx, end := startSpan(ctx, "RecognitionService.ScheduleRevenue")
+	defer end(&err)
+	entries := []domain.RevenueEntry{}
+	for _, sub := range subs {
+		schedule := revenueSchedule(sub, bookedOn)
+		switch sub.Status {
+		case domain.SubscriptionStatusCancel:
+			recognizeRemaining(schedule, dateOr(sub.CancelledAt, bookedOn))
+		case domain.SubscriptionStatusPaused:
+			suspendRemaining(schedule, dateOr(sub.PausedAt, bookedOn))
+		}
+		entries = append(entries, schedule...)
+	}
+	return rs.entriesRepo.CreateBatch(ctx, entries)
+}
+
+// AdjustRevenue adjusts the revenue entries of a subscription, as it was before the update,
+// to a status change at the given date:
+//   - pausing suspends the months not over yet,
+//   - resuming shifts the suspended months by the length of the pause,
+//   - cancelling recognizes the months not over yet, as the subscription isn't refunded.
+func (rs RecognitionService) AdjustRevenue(
+	ctx context.Context,
+	sub domain.Subscription,
+	status domain.SubscriptionStatus,
+	at time.Time,
+) (err error) {
+	ctx, end := startSpan(ctx, "RecognitionService.AdjustRevenue", attribute.String("subscription.id", sub.ID.String()))
+	defer end(&err)
+	if status == sub.Status {
+		return nil
+	}
+	entries, err := rs.entriesRepo.ListBySubscription(ctx, sub.ID)
+	if err != nil {
+		return err
+	}
+
+	var changed []domain.RevenueEntry
+	switch {
+	case status == domain.SubscriptionStatusCancel:
+		changed = recognizeRemaining(entries, at)
+	case status == domain.SubscriptionStatusPaused:
+		changed = suspendRemaining(entries, at)
+	case sub.Status == domain.SubscriptionStatusPaused:
+		changed = resumeSuspended(entries, sub.PausedAt, at)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	return rs.entriesRepo.Update(ctx, changed)
+}
+
+// RevenueSchedule fetches the revenue entries of a subscription.
+func (rs RecognitionService) RevenueSchedule(ctx context.Context, subscriptionID uuid.UUID) (_ []domain.RevenueEntry, err error) {
+	ctx, end := startSpan(ctx, "RecognitionService.RevenueSchedule", attribute.String("subscription.id", subscriptionID.String()))
+	defer end(&err)
+	if subscriptionID == uuid.Nil {
+		return nil, domain.ErrSubscriptionIDIsInvalid
+	}
+	sub, err := rs.subsRepo.GetByID(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if !canAccess(ctx, sub) {
+		return nil, domain.ErrSubscriptionNotfound
+	}
+	return rs.entriesRepo.ListBySubscription(ctx, subscriptionID)
+}
+
+// RefundSubscription cancels a subscription and refunds the months not over yet. Cancelled
+// subscriptions can't be refunded, their remaining months being already recognized.
+// Refunds are reserved to staff.
+func (rs RecognitionService) RefundSubscription(ctx context.Context, subscriptionID uuid.UUID) (_ domain.Refund, err error) {
+	ctx, end := startSpan(ctx, "RecognitionService.RefundSubscription", attribute.String("subscription.id", subscriptionID.String()))
+	defer end(&err)
+	if err := authorize(ctx, actionRefundSubscription); err != nil {
+		return domain.Refund{}, err
+	}
+	if subscriptionID == uuid.Nil {
+		return domain.Refund{}, domain.ErrSubscriptionIDIsInvalid
+	}
+	now := rs.now().UTC()
+	refund := domain.Refund{
+		SubscriptionID: subscriptionID,
+		RefundedOn:     now,
+	}
+	var from domain.SubscriptionStatus
+	err = rs.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		sub, err := rs.subsRepo.GetByID(ctx, subscriptionID)
+		if err != nil {
+			return err
+		}
+		if !canAccess(ctx, sub) {
+			return domain.ErrSubscriptionNotfound
+		}
+		from = sub.Status
+		if sub.Status == domain.SubscriptionStatusCancel {
+			return domain.ErrCannotUpdateCancelledSubscription
+		}
+		err = rs.subsRepo.Patch(ctx, sub.ID, sub.Version, statusUpdate(sub, domain.SubscriptionStatusCancel, now))
+		if err != nil {
+			return err
+		}
+
+		entries, err := rs.entriesRepo.ListBySubscription(ctx, sub.ID)
+		if err != nil {
+			return err
+		}
+		var refunded []domain.RevenueEntry
+		for _, entry := range entries {
+			if entry.Status == domain.RevenueEntrySuspended ||
+				(entry.Status == domain.RevenueEntryScheduled && entry.RecognizeOn.After(now)) {
+				entry.Status = domain.RevenueEntryRefunded
+				entry.RefundedOn = &now
+				refunded = append(refunded, entry)
+				refund.Amount += entry.Amount
+				refund.Months++
+			}
+		}
+		refund.Amount = roundAmount(refund.Amount)
+		return rs.entriesRepo.Update(ctx, refunded)
+	})
+	if err != nil {
+		return domain.Refund{}, err
+	}
+	rs.metrics.SubscriptionStatusChanged(from, domain.SubscriptionStatusCancel)
+	return refund, nil
+}
+
+// PeriodCloseReport computes per product the revenue recognized and refunded in [from, to),
+// and the revenue still deferred at to. Products without any amount are left out.
+func (rs RecognitionService) PeriodCloseReport(ctx context.Context, from, to time.Time) (_ domain.PeriodCloseReport, err error) {
+	ctx, end := startSpan(ctx, "RecognitionService.PeriodCloseReport")
+	defer end(&err)
+	if err := authorize(ctx, actionViewReports); err != nil {
+		return domain.PeriodCloseReport{}, err
+	}
+	if !from.Before(to) {
+		return domain.PeriodCloseReport{}, domain.ErrInvalidReportPeriod
+	}
+	recognitions, err := rs.entriesRepo.PeriodClose(ctx, from, to)
+	if err != nil {
+		return domain.PeriodCloseReport{}, err
+	}
+
+	report := domain.PeriodCloseReport{
+		From:     from,
+		To:       to,
+		Products: make([]domain.ProductRecognition, 0, len(recognitions)),
+	}
+	for _, r := range recognitions {
+		r.Recognized, r.Refunded, r.Deferred = roundAmount(r.Recognized), roundAmount(r.Refunded), roundAmount(r.Deferred)
+		if r.Recognized == 0 && r.Refunded == 0 && r.Deferred == 0 {
+			continue
+		}
+		report.Recognized += r.Recognized
+		report.Refunded += r.Refunded
+		report.Deferred += r.Deferred
+		report.Products = append(report.Products, r)
+	}
+	report.Recognized = roundAmount(report.Recognized)
+	report.Refunded = roundAmount(report.Refunded)
+	report.Deferred = roundAmount(report.Deferred)
+	return report, nil
+}
+
+// revenueSchedule splits the cost of a subscription, before tax, in monthly entries
+// recognized at the end of each month. The rounding difference goes to the last month.
+func revenueSchedule(sub domain.Subscription, bookedOn time.Time) []domain.RevenueEntry {
+	months := int(sub.DurationInMonths)
+	if months <= 0 {
+		return nil
+	}
+	revenue := sub.TotalCost - sub.Tax
+	monthly := roundAmount(revenue / float64(months))
+
+	entries := make([]domain.RevenueEntry, 0, months)
+	for i := 0; i < months; i++ {
+		amount := monthly
+		if i == months-1 {
+			amount = roundAmount(revenue - monthly*float64(months-1))
+		}
+		start, end := sub.StartDate.AddDate(0, i, 0), sub.StartDate.AddDate(0, i+1, 0)
+		entries = append(entries, domain.RevenueEntry{
+			ID:             uuid.New(),
+			SubscriptionID: sub.ID,
+			ProductID:      sub.ProductID,
+			Month:          int8(i + 1),
+			PeriodStart:    start,
+			PeriodEnd:      end,
+			Amount:         amount,
+			Status:         domain.RevenueEntryScheduled,
+			RecognizeOn:    end,
+			BookedOn:       bookedOn,
+		})
+	}
+	return entries
+}
+
+// recognizeRemaining recognizes at the given date the entries not recognized yet, and returns them.
+func recognizeRemaining(entries []domain.RevenueEntry, at time.Time) []domain.RevenueEntry {
+	var changed []domain.RevenueEntry
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Status == domain.RevenueEntrySuspended ||
+			(entry.Status == domain.RevenueEntryScheduled && entry.RecognizeOn.After(at)) {
+			entry.Status = domain.RevenueEntryScheduled
+			entry.RecognizeOn = at
+			changed = append(changed, *entry)
+		}
+	}
+	return changed
+}
+
+// suspendRemaining suspends the scheduled entries not recognized at the given date, and returns them.
+func suspendRemaining(entries []domain.RevenueEntry, at time.Time) []domain.RevenueEntry {
+	var changed []domain.RevenueEntry
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Status == domain.RevenueEntryScheduled && entry.RecognizeOn.After(at) {
+			entry.Status = domain.RevenueEntrySuspended
+			changed = append(changed, *entry)
+		}
+	}
+	return changed
+}
+
+// resumeSuspended reschedules the suspended entries, shifted by the length of the pause,
+// and returns them. Without a pause date the first suspended month restarts at resumedAt.
+func resumeSuspended(entries []domain.RevenueEntry, pausedAt *time.Time, resumedAt time.Time) []domain.RevenueEntry {
+	var changed []domain.RevenueEntry
+	var shift time.Duration
+	for i := range entries {
+		entry := &entries[i]
+		if entry.Status != domain.RevenueEntrySuspended {
+			continue
+		}
+		if changed == nil {
+			shift = resumedAt.Sub(dateOr(pausedAt, entry.PeriodStart))
+			if shift < 0 {
+				shift = 0
+			}
+		}
+		entry.Status = domain.RevenueEntryScheduled
+		entry.PeriodStart = entry.PeriodStart.Add(shift)
+		entry.PeriodEnd = entry.PeriodEnd.Add(shift)
+		entry.RecognizeOn = entry.PeriodEnd
+		changed = append(changed, *entry)
+	}
+	return changed
+}
+
+// dateOr returns the date pointed to, or def when nil.
+func dateOr(date *time.Time, def time.Time) time.Time {
+	if date == nil {
+		return def
+	}
+	return *date
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/repositories"
+	"github.com/goakshit/isildur/repositories/memory"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type RecognitionServiceTestSuite struct {
+	suite.Suite
+	product domain.Product
+	repos   repositories.Repositories
+	metrics *ports.MockMetrics
+	service *RecognitionService
+}
+
+func TestRecognitionServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(RecognitionServiceTestSuite))
+}
+
+func (ts *RecognitionServiceTestSuite) SetupTest() {
+	ts.product = domain.Product{
+		ID:             uuid.New(),
+		Name:           "YOGA 1",
+		Description:    "BASIC YOGA",
+		MonthlyPrice:   10.0 / 3,
+		InstructorName: "A. Dhar",
+	}
+	store := memory.NewStore()
+	store.Seed(memory.Fixtures{Products: []domain.Product{ts.product}})
+	ts.repos = memory.NewRepositories(store)
+	ts.metrics = ports.NewMockMetrics(gomock.NewController(ts.T()))
+	ts.service = NewRecognitionService(ts.repos.RevenueEntries, ts.repos.Subscriptions, ts.repos.Tx, ts.metrics)
+}
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2022, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// schedule creates a 3 months subscription starting on the 1st of June, worth 10 before tax,
+// and books its revenue on the 20th of May.
+func (ts *RecognitionServiceTestSuite) schedule(status domain.SubscriptionStatus) domain.Subscription {
+	ctx := context.Background()
+	sub := newSubscription(ts.product, 3, date(time.June, 1), status)
+	ts.Require().Nil(ts.repos.Subscriptions.Create(ctx, sub))
+	ts.Require().Nil(ts.service.ScheduleRevenue(ctx, []domain.Subscription{sub}, date(time.May, 20)))
+	return sub
+}
+
+func (ts *RecognitionServiceTestSuite) entries(sub domain.Subscription) []domain.RevenueEntry {
+	entries, err := ts.service.RevenueSchedule(context.Background(), sub.ID)
+	ts.Require().Nil(err)
+	ts.Require().Len(entries, 3)
+	return entries
+}
+
+func (ts *RecognitionServiceTestSuite) TestRecognitionService_ScheduleRevenue() {
+	sub := ts.schedule(domain.SubscriptionStatusInactive)
+
+	entries := ts.entries(sub)
+	for i, entry := range entries {
+		ts.Assert().Equal(sub.ID, entry.SubscriptionID)
+		ts.Assert().Equal(ts.product.ID, entry.ProductID)
+		ts.Assert().EqualValues(i+1, entry.Month)
+		ts.Assert().Equal(date(time.Month(6+i), 1), entry.PeriodStart)
+		ts.Assert().Equal(date(time.Month(7+i), 1), entry.PeriodEnd)
+		ts.Assert().Equal(entry.PeriodEnd, entry.RecognizeOn)
+		ts.Assert().Equal(date(time.May, 20), entry.BookedOn)
+		ts.Assert().Equal(domain.RevenueEntryScheduled, entry.Status)
+	}
+	// The rounding difference goes to the last month
+	ts.Assert().Equal(3.33, entries[0].Amount)
+	ts.Assert().Equal(3.33, entries[1].Amount)
+	ts.Assert().Equal(3.34, entries[2].Amount)
+
+	_, err := ts.service.RevenueSchedule(context.Background(), uuid.New())
+	ts.Assert().ErrorIs(err, domain.ErrSubscriptionNotfound)
+
+	// Customers only see the schedule of their own subscriptions
+	ctx := domain.ContextWithPrincipal(context.Background(), domain.Principal{Subject: "customer-2"})
+	_, err = ts.service.RevenueSchedule(ctx, sub.ID)
+	ts.Assert().ErrorIs(err, domain.ErrSubscriptionNotfound)
+}
+
+func (ts *RecognitionServiceTestSuite) TestRecognitionService_ScheduleImported() {
+	ctx := context.Background()
+	cancelled := newSubscription(ts.product, 3, date(time.June, 1), domain.SubscriptionStatusCancel)
+	paused := newSubscription(ts.product, 3, date(time.June, 1), domain.SubscriptionStatusPaused)
+	ts.Require().Nil(ts.service.ScheduleRevenue(ctx, []domain.Subscription{cancelled, paused}, date(time.July, 15)))
+
+	entries, err := ts.repos.RevenueEntries.ListBySubscription(ctx, cancelled.ID)
+	ts.Require().Nil(err)
+	ts.Require().Len(entries, 3)
+	ts.Assert().Equal(date(time.July, 1), entries[0].RecognizeOn)
+	ts.Assert().Equal(date(time.July, 15), entries[1].RecognizeOn)
+	ts.Assert().Equal(date(time.July, 15), entries[2].RecognizeOn)
+
+	entries, err = ts.repos.RevenueEntries.ListBySubscription(ctx, paused.ID)
+	ts.Require().Nil(err)
+	ts.Require().Len(entries, 3)
+	ts.Assert().Equal(domain.RevenueEntryScheduled, entries[0].Status)
+	ts.Assert().Equal(domain.RevenueEntrySuspended, entries[1].Status)
+	ts.Assert().Equal(domain.RevenueEntrySuspended, entries[2].Status)
+}
+
+func (ts *RecognitionServiceTestSuite) TestRecognitionService_PauseAndResume() {
+	ctx := context.Background()
+	sub := ts.schedule(domain.SubscriptionStatusActive)
+
+	ts.Require().Nil(ts.service.AdjustRevenue(ctx, sub, domain.SubscriptionStatusPaused, date(time.July, 10)))
+	entries := ts.entries(sub)
+	ts.Assert().Equal(domain.RevenueEntryScheduled, entries[0].Status)
+	ts.Assert().Equal(domain.RevenueEntrySuspended, entries[1].Status)
+	ts.Assert().Equal(domain.RevenueEntrySuspended, entries[2].Status)
+
+	// Resuming shifts the suspended months by the 10 days of the pause
+	pausedAt := date(time.July, 10)
+	sub.Status, sub.PausedAt = domain.SubscriptionStatusPaused, &pausedAt
+	ts.Require().Nil(ts.service.AdjustRevenue(ctx, sub, domain.SubscriptionStatusActive, date(time.July, 20)))
+	entries = ts.entries(sub)
+	ts.Assert().Equal(date(time.July, 1), entries[0].RecognizeOn)
+	for i, entry := range entries[1:] {
+		ts.Assert().Equal(domain.RevenueEntryScheduled, entry.Status)
+		ts.Assert().Equal(date(time.Month(7+i), 11), entry.PeriodStart)
+		ts.Assert().Equal(date(time.Month(8+i), 11), entry.PeriodEnd)
+		ts.Assert().Equal(entry.PeriodEnd, entry.RecognizeOn)
+	}
+}
+
+func (ts *RecognitionServiceTestSuite) TestRecognitionService_Cancel() {
+	ctx := context.Background()
+	sub := ts.schedule(domain.SubscriptionStatusActive)
+
+	ts.Require().Nil(ts.service.AdjustRevenue(ctx, sub, domain.SubscriptionStatusCancel, date(time.July, 10)))
+	entries := ts.entries(sub)
+	ts.Assert().Equal(date(time.July, 1), entries[0].RecognizeOn)
+	ts.Assert().Equal(date(time.July, 10), entries[1].RecognizeOn)
+	ts.Assert().Equal(date(time.July, 10), entries[2].RecognizeOn)
+	for _, entry := range entries {
+		ts.Assert().Equal(domain.RevenueEntryScheduled, entry.Status)
+	}
+}
+
+func (ts *RecognitionServiceTestSuite) TestRecognitionService_RefundSubscription() {
+	ctx := context.Background()
+	sub := ts.schedule(domain.SubscriptionStatusActive)
+	ts.service.now = func() time.Time { return date(time.July, 10) }
+
+	// Only the successful refund is counted
+	ts.metrics.EXPECT().SubscriptionStatusChanged(domain.SubscriptionStatusActive, domain.SubscriptionStatusCancel).Times(1)
+	refund, err := ts.service.RefundSubscription(ctx, sub.ID)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(domain.Refund{
+		SubscriptionID: sub.ID,
+		Amount:         6.67,
+		Months:         2,
+		RefundedOn:     date(time.July, 10),
+	}, refund)
+
+	refunded, err := ts.repos.Subscriptions.GetByID(ctx, sub.ID)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(domain.SubscriptionStatusCancel, refunded.Status)
+	ts.Assert().Equal(date(time.July, 10), *refunded.CancelledAt)
+	ts.Assert().Equal(2, refunded.Version)
+
+	entries := ts.entries(sub)
+	ts.Assert().Equal(domain.RevenueEntryScheduled, entries[0].Status)
+	ts.Assert().Equal(domain.RevenueEntryRefunded, entries[1].Status)
+	ts.Assert().Equal(domain.RevenueEntryRefunded, entries[2].Status)
+	ts.Assert().Equal(date(time.July, 10), *entries[2].RefundedOn)
+
+	_, err = ts.service.RefundSubscription(ctx, sub.ID)
+	ts.Assert().ErrorIs(err, domain.ErrCannotUpdateCancelledSubscription)
+	_, err = ts.service.RefundSubscription(ctx, uuid.New())
+	ts.Assert().ErrorIs(err, domain.ErrSubscriptionNotfound)
+}
+
+func (ts *RecognitionServiceTestSuite) TestRecognitionService_PeriodCloseReport() {
+	ctx := context.Background()
+	sub := ts.schedule(domain.SubscriptionStatusActive)
+	ts.schedule(domain.SubscriptionStatusActive)
+	ts.service.now = func() time.Time { return date(time.July, 10) }
+	ts.metrics.EXPECT().SubscriptionStatusChanged(gomock.Any(), gomock.Any()).AnyTimes()
+	_, err := ts.service.RefundSubscription(ctx, sub.ID)
+	ts.Require().Nil(err)
+
+	tc := []struct {
+		Name     string
+		from, to time.Time
+		report   domain.PeriodCloseReport
+	}{
+		{
+			Name: "Before booking",
+			from: date(time.April, 1),
+			to:   date(time.May, 1),
+			report: domain.PeriodCloseReport{
+				Products: []domain.ProductRecognition{},
+			},
+		},
+		{
+			Name: "Booked, nothing recognized yet",
+			from: date(time.May, 1),
+			to:   date(time.June, 1),
+			report: domain.PeriodCloseReport{
+				Deferred: 20,
+				Products: []domain.ProductRecognition{
+					{ProductID: ts.product.ID, ProductName: ts.product.Name, Deferred: 20},
+				},
+			},
+		},
+		{
+			Name: "First month recognized, then refund",
+			from: date(time.July, 1),
+			to:   date(time.August, 1),
+			report: domain.PeriodCloseReport{
+				Recognized: 6.66,
+				Refunded:   6.67,
+				Deferred:   6.67,
+				Products: []domain.ProductRecognition{
+					{ProductID: ts.product.ID, ProductName: ts.product.Name, Recognized: 6.66, Refunded: 6.67, Deferred: 6.67},
+				},
+			},
+		},
+	}
+	for _, tt := range tc {
+		ts.Run(tt.Name, func() {
+			report, err := ts.service.PeriodCloseReport(ctx, tt.from, tt.to)
+			ts.Require().Nil(err)
+			tt.report.From, tt.report.To = tt.from, tt.to
+			ts.Assert().Equal(tt.report, report)
+		})
+	}
+
+	_, err = ts.service.PeriodCloseReport(ctx, date(time.July, 1), date(time.July, 1))
+	ts.Assert().ErrorIs(err, domain.ErrInvalidReportPeriod)
+}
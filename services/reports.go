@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+)
+
+var _ ports.ReportsService = (*ReportsService)(nil)
+
+// maxReportPeriods bounds the number of periods of an activity report, one query being run per period.
+const maxReportPeriods = 366
+
+// ReportsService represents required dependencies for the service.
+type ReportsService struct {
+	reportsRepo ports.ReportsRepository
+}
+
+// NewReportsService
+func NewReportsService(r ports.ReportsRepository) *ReportsService {
+	return &ReportsService{
+		reportsRepo: r,
+	}
+}
+
+// RevenueReport computes the MRR, the ARR and the active subscribers at a given date.
+// The monthly amount of a subscription is its cost before tax spread over its duration.
+func (rs ReportsService) RevenueReport(ctx context.Context, asOf time.Time) (_ domain.RevenueReport, err error) {
+	ctx, end := startSpan(ctx, "ReportsService.RevenueReport")
+	defer end(&err)
+	if err := authorize(ctx, actionViewReports); err != nil {
+		return domain.RevenueReport{}, err
+	}
+	products, err := rs.reportsRepo.Revenue(ctx, asOf)
+	if err != nil {
+		return domain.RevenueReport{}, err
+	}
+	customers, err := rs.reportsRepo.ActiveCustomers(ctx, asOf)
+	if err != nil {
+		return domain.RevenueReport{}, err
+	}
+
+	report := domain.RevenueReport{
+		AsOf:            asOf,
+		ActiveCustomers: customers,
+		Products:        products,
+	}
+	var mrr float64
+	for i := range report.Products {
+		product := &report.Products[i]
+		mrr += product.MRR
+		report.ActiveSubscriptions += product.ActiveSubscriptions
+		product.ARR = roundAmount(product.MRR * 12)
+		product.MRR = roundAmount(product.MRR)
+	}
+	report.MRR = roundAmount(mrr)
+	report.ARR = roundAmount(mrr * 12)
+	return report, nil
+}
+
+// ActivityReport computes the new, churned and paused subscriptions per period in [from, to).
+// Periods start at from and last one interval, the last one being cut at to.
+func (rs ReportsService) ActivityReport(
+	ctx context.Context,
+	from, to time.Time,
+	interval domain.ReportInterval,
+) (_ domain.ActivityReport, err error) {
+	ctx, end := startSpan(ctx, "ReportsService.ActivityReport")
+	defer end(&err)
+	if err := authorize(ctx, actionViewReports); err != nil {
+		return domain.ActivityReport{}, err
+	}
+	periods, err := reportPeriods(from, to, interval)
+	if err != nil {
+		return domain.ActivityReport{}, err
+	}
+
+	report := domain.ActivityReport{
+		From:     from,
+		To:       to,
+		Interval: interval,
+		Periods:  make([]domain.ActivityPeriod, 0, len(periods)),
+	}
+	for _, p := range periods {
+		products, err := rs.reportsRepo.Activity(ctx, p.Start, p.End)
+		if err != nil {
+			return domain.ActivityReport{}, err
+		}
+		p.Products = products
+		for _, product := range products {
+			p.New += product.New
+			p.Churned += product.Churned
+			p.Paused += product.Paused
+		}
+		report.Periods = append(report.Periods, p)
+	}
+	return report, nil
+}
+
+// reportPeriods splits [from, to) in consecutive periods of one interval.
+func reportPeriods(from, to time.Time, interval domain.ReportInterval) ([]domain.ActivityPeriod, error) {
+	var next func(time.Time) time.Time
+	switch interval {
+	case domain.ReportIntervalDay:
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case domain.ReportIntervalWeek:
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case domain.ReportIntervalMonth:
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return nil, domain.ErrInvalidReportPeriod
+	}
+	if !from.Before(to) {
+		return nil, domain.ErrInvalidReportPeriod
+	}
+
+	periods := []domain.ActivityPeriod{}
+	for start := from; start.Before(to); start = next(start) {
+		if len(periods) == maxReportPeriods {
+			return nil, domain.ErrInvalidReportPeriod
+		}
+		end := next(start)
+		if end.After(to) {
+			end = to
+		}
+		periods = append(periods, domain.ActivityPeriod{Start: start, End: end})
+	}
+	return periods, nil
+}
+
+// roundAmount rounds an amount to cents.
+func roundAmount(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+)
+
+type ReportsServiceTestSuite struct {
+	suite.Suite
+	reportsRepo *ports.MockReportsRepository
+	service     *ReportsService
+}
+
+func TestReportsServiceTestSuite(t *testing.T) {
+	suite.Run(t, new(ReportsServiceTestSuite))
+}
+
+func (ts *ReportsServiceTestSuite) SetupTest() {
+	ctrl := gomock.NewController(ts.T())
+	ts.reportsRepo = ports.NewMockReportsRepository(ctrl)
+	ts.service = NewReportsService(ts.reportsRepo)
+}
+
+func (ts *ReportsServiceTestSuite) TestReportsService_RevenueReport() {
+	ctx := context.Background()
+	asOf := time.Date(2022, time.July, 15, 0, 0, 0, 0, time.UTC)
+	l1, l2 := uuid.New(), uuid.New()
+
+	ts.reportsRepo.EXPECT().Revenue(gomock.Any(), asOf).Times(1).Return([]domain.ProductRevenue{
+		{ProductID: l1, ProductName: "YOGA L1", ActiveSubscriptions: 3, MRR: 15.333333333},
+		{ProductID: l2, ProductName: "YOGA L2", ActiveSubscriptions: 2, MRR: 14.004},
+	}, nil)
+	ts.reportsRepo.EXPECT().ActiveCustomers(gomock.Any(), asOf).Times(1).Return(4, nil)
+
+	report, err := ts.service.RevenueReport(ctx, asOf)
+	ts.Require().Nil(err)
+	ts.Assert().Equal(domain.RevenueReport{
+		AsOf:                asOf,
+		MRR:                 29.34,
+		ARR:                 352.05,
+		ActiveSubscriptions: 5,
+		ActiveCustomers:     4,
+		Products: []domain.ProductRevenue{
+			{ProductID: l1, ProductName: "YOGA L1", ActiveSubscriptions: 3, MRR: 15.33, ARR: 184},
+			{ProductID: l2, ProductName: "YOGA L2", ActiveSubscriptions: 2, MRR: 14, ARR: 168.05},
+		},
+	}, report)
+
+	dbErr := errors.New("connection refused")
+	ts.reportsRepo.EXPECT().Revenue(gomock.Any(), asOf).Times(1).Return(nil, dbErr)
+	_, err = ts.service.RevenueReport(ctx, asOf)
+	ts.Assert().ErrorIs(err, dbErr)
+}
+
+func (ts *ReportsServiceTestSuite) TestReportsService_ActivityReport() {
+	ctx := context.Background()
+	productID := uuid.New()
+	date := func(month time.Month, day int) time.Time {
+		return time.Date(2022, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	ts.Run("Periods are cut at the end of the range", func() {
+		ts.reportsRepo.EXPECT().Activity(gomock.Any(), date(time.June, 1), date(time.July, 1)).Times(1).
+			Return([]domain.ProductActivity{{ProductID: productID, New: 3, Paused: 1}}, nil)
+		ts.reportsRepo.EXPECT().Activity(gomock.Any(), date(time.July, 1), date(time.July, 15)).Times(1).
+			Return([]domain.ProductActivity{}, nil)
+
+		report, err := ts.service.ActivityReport(ctx, date(time.June, 1), date(time.July, 15), domain.ReportIntervalMonth)
+		ts.Require().Nil(err)
+		ts.Assert().Equal(domain.ActivityReport{
+			From:     date(time.June, 1),
+			To:       date(time.July, 15),
+			Interval: domain.ReportIntervalMonth,
+			Periods: []domain.ActivityPeriod{
+				{
+					Start:    date(time.June, 1),
+					End:      date(time.July, 1),
+					New:      3,
+					Paused:   1,
+					Products: []domain.ProductActivity{{ProductID: productID, New: 3, Paused: 1}},
+				},
+				{
+					Start:    date(time.July, 1),
+					End:      date(time.July, 15),
+					Products: []domain.ProductActivity{},
+				},
+			},
+		}, report)
+	})
+
+	ts.Run("Weekly periods", func() {
+		ts.reportsRepo.EXPECT().Activity(gomock.Any(), gomock.Any(), gomock.Any()).Times(2).Return(nil, nil)
+		report, err := ts.service.ActivityReport(ctx, date(time.June, 1), date(time.June, 15), domain.ReportIntervalWeek)
+		ts.Require().Nil(err)
+		ts.Require().Len(report.Periods, 2)
+		ts.Assert().Equal(date(time.June, 8), report.Periods[1].Start)
+	})
+
+	tc := []struct {
+		Name     string
+		from, to time.Time
+		interval domain.ReportInterval
+	}{
+		{Name: "Invalid interval", from: date(time.June, 1), to: date(time.July, 1), interval: "year"},
+		{Name: "Empty range", from: date(time.June, 1), to: date(time.June, 1), interval: domain.ReportIntervalDay},
+		{Name: "Reversed range", from: date(time.July, 1), to: date(time.June, 1), interval: domain.ReportIntervalDay},
+		{Name: "Too many periods", from: date(time.January, 1), to: date(time.January, 1).AddDate(2, 0, 0), interval: domain.ReportIntervalDay},
+	}
+	for _, tt := range tc {
+		ts.Run(tt.Name, func() {
+			_, err := ts.service.ActivityReport(ctx, tt.from, tt.to, tt.interval)
+			ts.Assert().ErrorIs(err, domain.ErrInvalidReportPeriod)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/goakshit/isildur/core/ports"
 	"github.com/goakshit/isildur/platform/constants"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 var _ ports.SubscriptionService = (*SubscriptionService)(nil)
@@ -16,50 +17,119 @@ var _ ports.SubscriptionService = (*SubscriptionService)(nil)
 type SubscriptionService struct {
 	prodRepo ports.ProductsRepository
 	subsRepo ports.SubscriptionsRepository
+	tx       ports.TxManager
+	revenue  ports.RevenueRecognizer
+	metrics  ports.Metrics
+	now      func() time.Time
 }
 
 // NewSubscriptionService
 func NewSubscriptionService(
 	s ports.SubscriptionsRepository,
 	p ports.ProductsRepository,
+	tx ports.TxManager,
+	r ports.RevenueRecognizer,
+	m ports.Metrics,
 ) *SubscriptionService {
 	return &SubscriptionService{
 		subsRepo: s,
 		prodRepo: p,
+		tx:       tx,
+		revenue:  r,
+		metrics:  m,
+		now:      time.Now,
 	}
 }
 
+// CreateSubscription creates susbscription for a product, starting today or later, for the
+// customer making the request.
 func (ss SubscriptionService) CreateSubscription(
 	ctx context.Context,
 	pID uuid.UUID,
 	durationInMonths int8,
 	startDate time.Time,
-) error {
+) (err error) {
+	ctx, end := startSpan(ctx, "SubscriptionService.CreateSubscription", attribute.String("product.id", pID.String()))
+	defer end(&err)
 
+	// Check the status of subscription
+	status, err := initialStatus(startDate, false)
+	if err != nil {
+		return err
+	}
+	if durationInMonths <= 0 {
+		return domain.ErrInvalidSubscriptionDuration
+	}
+
+	err = ss.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		// Fetch product details
+		product, err := ss.prodRepo.GetByID(ctx, pID)
+		if err != nil {
+			return err
+		}
+		subscription := newSubscription(product, durationInMonths, startDate, status)
+		if p, ok := domain.PrincipalFromContext(ctx); ok {
+			subscription.CustomerID = p.Subject
+		}
+		if err := ss.subsRepo.Create(ctx, subscription); err != nil {
+			return err
+		}
+		return ss.revenue.ScheduleRevenue(ctx, []domain.Subscription{subscription}, ss.now().UTC())
+	})
+	if err != nil {
+		return err
+	}
+	ss.metrics.SubscriptionsCreated(pID, status, 1)
+	return nil
+}
+
+// countCreated records the subscriptions created, per product and status.
+func countCreated(m ports.Metrics, subs []domain.Subscription) {
+	type key struct {
+		productID uuid.UUID
+		status    domain.SubscriptionStatus
+	}
+	counts := map[key]int{}
+	for _, sub := range subs {
+		counts[key{sub.ProductID, sub.Status}]++
+	}
+	for k, count := range counts {
+		m.SubscriptionsCreated(k.productID, k.status, count)
+	}
+}
+
+// initialStatus returns the status of a new subscription depending on its start date.
+// Start dates in the past are rejected unless allowPastStartDate is set, e.g. for imports.
+func initialStatus(startDate time.Time, allowPastStartDate bool) (domain.SubscriptionStatus, error) {
 	var status domain.SubscriptionStatus = domain.SubscriptionStatusInactive
 	todayDate := time.Now().Truncate(24 * time.Hour)
 	tomorrowDate := todayDate.Add(24 * time.Hour)
-	// Check the status of subscription
 	if equalDate(startDate, todayDate) {
 		status = domain.SubscriptionStatusActive
 	} else if startDate.After(tomorrowDate) {
 		status = domain.SubscriptionStatusInactive
 	} else if startDate.Before(todayDate) {
-		return domain.ErrInvalidStartDate
-	}
-
-	// Fetch product details
-	product, err := ss.prodRepo.GetByID(ctx, pID)
-	if err != nil {
-		return err
+		if !allowPastStartDate {
+			return "", domain.ErrInvalidStartDate
+		}
+		status = domain.SubscriptionStatusActive
 	}
+	return status, nil
+}
 
+// newSubscription builds a subscription for a product, calculating its total cost and tax.
+func newSubscription(
+	product domain.Product,
+	durationInMonths int8,
+	startDate time.Time,
+	status domain.SubscriptionStatus,
+) domain.Subscription {
 	// Calculate Total cost, and tax.
 	costBeforeTax := product.MonthlyPrice * float64(durationInMonths)
 	taxAmount := costBeforeTax * (constants.TaxPercentApplicable / 100)
 	totalCost := costBeforeTax + taxAmount
 
-	return ss.subsRepo.Create(ctx, domain.Subscription{
+	return domain.Subscription{
 		ID:               uuid.New(),
 		ProductID:        product.ID,
 		DurationInMonths: durationInMonths,
@@ -68,7 +138,8 @@ func (ss SubscriptionService) CreateSubscription(
 		Status:           status,
 		StartDate:        startDate,
 		EndDate:          startDate.AddDate(0, int(durationInMonths), 0),
-	})
+		Version:          1,
+	}
 }
 
 func equalDate(date1, date2 time.Time) bool {
@@ -77,19 +148,171 @@ func equalDate(date1, date2 time.Time) bool {
 	return y1 == y2 && m1 == m2 && d1 == d2
 }
 
+// customerScope returns the customer the caller is restricted to. Staff and calls without a
+// principal, e.g. from the admin CLI, aren't restricted.
+func customerScope(ctx context.Context) (string, bool) {
+	p, ok := domain.PrincipalFromContext(ctx)
+	if !ok || authorize(ctx, actionReadAnySubscription) == nil {
+		return "", false
+	}
+	return p.Subject, true
+}
+
+// canAccess reports whether the caller may access a subscription. Subscriptions of other
+// customers are reported as not found, so that their ids can't be probed.
+func canAccess(ctx context.Context, sub domain.Subscription) bool {
+	customer, scoped := customerScope(ctx)
+	return !scoped || sub.CustomerID == customer
+}
+
 // FetchSubscription fetches subscription for a given ID.
-func (ss SubscriptionService) FetchSubscription(ctx context.Context, id uuid.UUID) (domain.Subscription, error) {
+func (ss SubscriptionService) FetchSubscription(ctx context.Context, id uuid.UUID) (_ domain.Subscription, err error) {
+	ctx, end := startSpan(ctx, "SubscriptionService.FetchSubscription", attribute.String("subscription.id", id.String()))
+	defer end(&err)
 	if id == uuid.Nil {
 		return domain.Subscription{}, domain.ErrSubscriptionIDIsInvalid
 	}
-	return ss.subsRepo.GetByID(ctx, id)
+	subscription, err := ss.subsRepo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Subscription{}, err
+	}
+	if !canAccess(ctx, subscription) {
+		return domain.Subscription{}, domain.ErrSubscriptionNotfound
+	}
+	return subscription, nil
+}
+
+// ListSubscriptions fetches a page of subscriptions matching the filter, restricted to the
+// subscriptions of the calling customer.
+func (ss SubscriptionService) ListSubscriptions(
+	ctx context.Context,
+	filter domain.SubscriptionFilter,
+) (_ []domain.Subscription, err error) {
+	ctx, end := startSpan(ctx, "SubscriptionService.ListSubscriptions")
+	defer end(&err)
+	if err := validateFilter(filter); err != nil {
+		return nil, err
+	}
+	if customer, scoped := customerScope(ctx); scoped {
+		filter.CustomerID = customer
+	}
+	if filter.Limit > constants.MaxPageSize {
+		return nil, domain.ErrInvalidPagination
+	}
+	if filter.Limit == 0 {
+		filter.Limit = constants.DefaultPageSize
+	}
+	return ss.subsRepo.List(ctx, filter)
+}
+
+// ExportSubscriptions calls fn for every subscription matching the filter, joined with its
+// product. Unlike listing, every matching subscription is exported unless a limit is passed.
+// Exports are reserved to staff.
+func (ss SubscriptionService) ExportSubscriptions(
+	ctx context.Context,
+	filter domain.SubscriptionFilter,
+	fn func(domain.SubscriptionExportRow) error,
+) (err error) {
+	ctx, end := startSpan(ctx, "SubscriptionService.ExportSubscriptions")
+	defer end(&err)
+	if err := authorize(ctx, actionExportSubscriptions); err != nil {
+		return err
+	}
+	if err := validateFilter(filter); err != nil {
+		return err
+	}
+	return ss.subsRepo.Stream(ctx, filter, fn)
 }
 
-func (ss SubscriptionService) UpdateSubscriptionStatus(ctx context.Context, id uuid.UUID, status domain.SubscriptionStatus) error {
+// validateFilter checks the status and the pagination of a subscriptions filter.
+func validateFilter(filter domain.SubscriptionFilter) error {
+	if filter.Status != "" && domain.MapStringToSubscriptionStatus(filter.Status.String()) == "" {
+		return domain.ErrInvalidSubscriptionStatusPassed
+	}
+	if filter.Limit < 0 || filter.Offset < 0 {
+		return domain.ErrInvalidPagination
+	}
+	return nil
+}
+
+// UpdateSubscriptionStatus updates subscription status for a given ID, if it is still at the
+// expected version. Cancelled subscriptions can't be updated anymore. Customers can only pause,
+// resume and cancel their subscriptions, other changes being reserved to staff.
+func (ss SubscriptionService) UpdateSubscriptionStatus(
+	ctx context.Context,
+	id uuid.UUID,
+	version int,
+	status domain.SubscriptionStatus,
+) (err error) {
+	ctx, end := startSpan(ctx, "SubscriptionService.UpdateSubscriptionStatus", attribute.String("subscription.id", id.String()))
+	defer end(&err)
 	if id == uuid.Nil {
 		return domain.ErrSubscriptionIDIsInvalid
 	}
-	return ss.subsRepo.Patch(ctx, id, map[string]interface{}{
-		"status": status,
+	if version <= 0 {
+		return domain.ErrSubscriptionVersionRequired
+	}
+	var from domain.SubscriptionStatus
+	err = ss.tx.WithinTransaction(ctx, func(ctx context.Context) error {
+		subscription, err := ss.subsRepo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		from = subscription.Status
+		if !canAccess(ctx, subscription) {
+			return domain.ErrSubscriptionNotfound
+		}
+
+		// Fail early if the caller is working on a stale copy
+		if subscription.Version != version {
+			return domain.ErrSubscriptionVersionMismatch
+		}
+
+		// Don't allow subscription to be active if it is cancelled
+		if subscription.Status == domain.SubscriptionStatusCancel {
+			return domain.ErrCannotUpdateCancelledSubscription
+		}
+		if !customerCanSetStatus(subscription.Status, status) {
+			if err := authorize(ctx, actionOverrideStatus); err != nil {
+				return err
+			}
+		}
+
+		now := ss.now().UTC()
+		if err := ss.subsRepo.Patch(ctx, id, version, statusUpdate(subscription, status, now)); err != nil {
+			return err
+		}
+		return ss.revenue.AdjustRevenue(ctx, subscription, status, now)
 	})
+	if err != nil {
+		return err
+	}
+	if from != status {
+		ss.metrics.SubscriptionStatusChanged(from, status)
+	}
+	return nil
+}
+
+// statusUpdate returns the columns to update for a status change, recording when the
+// subscription got cancelled, paused or resumed for the reports.
+func statusUpdate(
+	subscription domain.Subscription,
+	status domain.SubscriptionStatus,
+	now time.Time,
+) map[string]interface{} {
+	update := map[string]interface{}{
+		"status": status,
+	}
+	if status == subscription.Status {
+		return update
+	}
+	switch {
+	case status == domain.SubscriptionStatusCancel:
+		update["cancelled_at"] = now
+	case status == domain.SubscriptionStatusPaused:
+		update["paused_at"] = now
+	case subscription.Status == domain.SubscriptionStatusPaused:
+		update["resumed_at"] = now
+	}
+	return update
 }
@@ -8,6 +8,8 @@ import (
 
 	"github.com/goakshit/isildur/core/domain"
 	"github.com/goakshit/isildur/core/ports"
+	"github.com/goakshit/isildur/platform/constants"
+	"github.com/goakshit/isildur/repositories"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/suite"
@@ -17,6 +19,8 @@ type SubscriptionsServiceTestSuite struct {
 	suite.Suite
 	productsRepo      *ports.MockProductsRepository
 	subscriptionsRepo *ports.MockSubscriptionsRepository
+	revenue           *ports.MockRevenueRecognizer
+	metrics           *ports.MockMetrics
 	service           *SubscriptionService
 }
 
@@ -28,7 +32,9 @@ func (ts *SubscriptionsServiceTestSuite) SetupTest() {
 	ctrl := gomock.NewController(ts.T())
 	ts.productsRepo = ports.NewMockProductsRepository(ctrl)
 	ts.subscriptionsRepo = ports.NewMockSubscriptionsRepository(ctrl)
-	ts.service = NewSubscriptionService(ts.subscriptionsRepo, ts.productsRepo)
+	ts.revenue = ports.NewMockRevenueRecognizer(ctrl)
+	ts.metrics = ports.NewMockMetrics(ctrl)
+	ts.service = NewSubscriptionService(ts.subscriptionsRepo, ts.productsRepo, repositories.NoopTxManager{}, ts.revenue, ts.metrics)
 }
 
 func (ts *SubscriptionsServiceTestSuite) TestSubscriptionService_Create() {
@@ -142,6 +148,20 @@ func (ts *SubscriptionsServiceTestSuite) TestSubscriptionService_Create() {
 				Create(gomock.Any(), gomock.Any()).
 				Times(tt.createSubsription.timesToCall).
 				Return(tt.createSubsription.retErr)
+
+			// The revenue is only scheduled once the subscription is created
+			scheduleTimes := 0
+			if tt.createSubsription.timesToCall == 1 && tt.createSubsription.retErr == nil {
+				scheduleTimes = 1
+			}
+			ts.revenue.EXPECT().
+				ScheduleRevenue(gomock.Any(), gomock.Len(1), gomock.Any()).
+				Times(scheduleTimes).
+				Return(nil)
+			// Starting today, the subscription is created active
+			ts.metrics.EXPECT().
+				SubscriptionsCreated(tt.ID, domain.SubscriptionStatusActive, 1).
+				Times(scheduleTimes)
 			err := ts.service.CreateSubscription(ctx, tt.ID, tt.DurationInMonths, tt.startDate)
 			if tt.err != nil {
 				ts.Assert().NotNil(err)
@@ -233,7 +253,30 @@ func (ts *SubscriptionsServiceTestSuite) TestSubscriptionService_FetchSubscripti
 
 func (ts *SubscriptionsServiceTestSuite) TestSubscriptionService_UpdateSubscriptionStatus() {
 	ctx := context.Background()
-	productID := uuid.New()
+	subscriptionID := uuid.New()
+	subscription := domain.Subscription{
+		ID:               subscriptionID,
+		ProductID:        uuid.New(),
+		DurationInMonths: 3,
+		Tax:              0.77,
+		TotalCost:        10.77,
+		Status:           domain.SubscriptionStatusInactive,
+		StartDate:        time.Now().AddDate(0, 0, 1),
+		EndDate:          time.Now().AddDate(0, 3, 1),
+		Version:          1,
+	}
+	cancelled := subscription
+	cancelled.Status = domain.SubscriptionStatusCancel
+	paused := subscription
+	paused.Status = domain.SubscriptionStatusPaused
+	now := time.Date(2022, time.June, 15, 10, 0, 0, 0, time.UTC)
+	ts.service.now = func() time.Time { return now }
+
+	type GetByIDMock struct {
+		timesToCall int
+		retSub      domain.Subscription
+		retErr      error
+	}
 
 	type patchMock struct {
 		timesToCall int
@@ -245,29 +288,175 @@ func (ts *SubscriptionsServiceTestSuite) TestSubscriptionService_UpdateSubscript
 		err       error
 		Status    domain.SubscriptionStatus
 		ID        uuid.UUID
+		Version   int
+		update    map[string]interface{}
+		getByID   GetByIDMock
 		patchMock patchMock
 	}{
 		{
-			Name:   "Update subscription status success",
-			ID:     productID,
-			Status: domain.SubscriptionStatusActive,
-			err:    nil,
+			Name:    "Update subscription status success",
+			ID:      subscriptionID,
+			Version: 1,
+			Status:  domain.SubscriptionStatusActive,
+			err:     nil,
+			getByID: GetByIDMock{
+				timesToCall: 1,
+				retSub:      subscription,
+			},
 			patchMock: patchMock{
 				timesToCall: 1,
 				retErr:      nil,
 			},
 		},
+		{
+			Name:    "Update subscription status: pause records the pause date",
+			ID:      subscriptionID,
+			Version: 1,
+			Status:  domain.SubscriptionStatusPaused,
+			update:  map[string]interface{}{"status": domain.SubscriptionStatusPaused, "paused_at": now},
+			getByID: GetByIDMock{
+				timesToCall: 1,
+				retSub:      subscription,
+			},
+			patchMock: patchMock{
+				timesToCall: 1,
+			},
+		},
+		{
+			Name:    "Update subscription status: resume records the resume date",
+			ID:      subscriptionID,
+			Version: 1,
+			Status:  domain.SubscriptionStatusActive,
+			update:  map[string]interface{}{"status": domain.SubscriptionStatusActive, "resumed_at": now},
+			getByID: GetByIDMock{
+				timesToCall: 1,
+				retSub:      paused,
+			},
+			patchMock: patchMock{
+				timesToCall: 1,
+			},
+		},
+		{
+			Name:    "Update subscription status: cancel records the cancellation date",
+			ID:      subscriptionID,
+			Version: 1,
+			Status:  domain.SubscriptionStatusCancel,
+			update:  map[string]interface{}{"status": domain.SubscriptionStatusCancel, "cancelled_at": now},
+			getByID: GetByIDMock{
+				timesToCall: 1,
+				retSub:      paused,
+			},
+			patchMock: patchMock{
+				timesToCall: 1,
+			},
+		},
+		{
+			Name:    "Update subscription status: stale version",
+			ID:      subscriptionID,
+			Version: 2,
+			Status:  domain.SubscriptionStatusPaused,
+			err:     domain.ErrSubscriptionVersionMismatch,
+			getByID: GetByIDMock{
+				timesToCall: 1,
+				retSub:      subscription,
+			},
+			patchMock: patchMock{
+				timesToCall: 0,
+			},
+		},
+		{
+			Name:    "Update subscription status: concurrent update",
+			ID:      subscriptionID,
+			Version: 1,
+			Status:  domain.SubscriptionStatusPaused,
+			update:  map[string]interface{}{"status": domain.SubscriptionStatusPaused, "paused_at": now},
+			err:     domain.ErrSubscriptionVersionMismatch,
+			getByID: GetByIDMock{
+				timesToCall: 1,
+				retSub:      subscription,
+			},
+			patchMock: patchMock{
+				timesToCall: 1,
+				retErr:      domain.ErrSubscriptionVersionMismatch,
+			},
+		},
+		{
+			Name:    "Update subscription status: cancelled subscription",
+			ID:      subscriptionID,
+			Version: 1,
+			Status:  domain.SubscriptionStatusActive,
+			err:     domain.ErrCannotUpdateCancelledSubscription,
+			getByID: GetByIDMock{
+				timesToCall: 1,
+				retSub:      cancelled,
+			},
+			patchMock: patchMock{
+				timesToCall: 0,
+			},
+		},
+		{
+			Name:    "Update subscription status: not found",
+			ID:      subscriptionID,
+			Version: 1,
+			Status:  domain.SubscriptionStatusActive,
+			err:     domain.ErrSubscriptionNotfound,
+			getByID: GetByIDMock{
+				timesToCall: 1,
+				retErr:      domain.ErrSubscriptionNotfound,
+			},
+			patchMock: patchMock{
+				timesToCall: 0,
+			},
+		},
+		{
+			Name:    "Update subscription status: missing version",
+			ID:      subscriptionID,
+			Version: 0,
+			Status:  domain.SubscriptionStatusPaused,
+			err:     domain.ErrSubscriptionVersionRequired,
+			getByID: GetByIDMock{
+				timesToCall: 0,
+			},
+			patchMock: patchMock{
+				timesToCall: 0,
+			},
+		},
 	}
 
 	for _, tt := range tc {
 		ts.Run(tt.Name, func() {
 			ts.subscriptionsRepo.EXPECT().
-				Patch(gomock.Any(), tt.ID, map[string]interface{}{
-					"status": tt.Status,
-				}).
+				GetByID(gomock.Any(), tt.ID).
+				Times(tt.getByID.timesToCall).
+				Return(tt.getByID.retSub, tt.getByID.retErr)
+
+			update := tt.update
+			if update == nil {
+				update = map[string]interface{}{"status": tt.Status}
+			}
+			ts.subscriptionsRepo.EXPECT().
+				Patch(gomock.Any(), tt.ID, tt.Version, update).
 				Times(tt.patchMock.timesToCall).
 				Return(tt.patchMock.retErr)
-			err := ts.service.UpdateSubscriptionStatus(ctx, tt.ID, tt.Status)
+
+			// The revenue is adjusted with the subscription as it was before the update
+			adjustTimes := 0
+			if tt.patchMock.timesToCall == 1 && tt.patchMock.retErr == nil {
+				adjustTimes = 1
+			}
+			ts.revenue.EXPECT().
+				AdjustRevenue(gomock.Any(), tt.getByID.retSub, tt.Status, now).
+				Times(adjustTimes).
+				Return(nil)
+			// Only the actual transitions are counted
+			transitionTimes := adjustTimes
+			if tt.getByID.retSub.Status == tt.Status {
+				transitionTimes = 0
+			}
+			ts.metrics.EXPECT().
+				SubscriptionStatusChanged(tt.getByID.retSub.Status, tt.Status).
+				Times(transitionTimes)
+			err := ts.service.UpdateSubscriptionStatus(ctx, tt.ID, tt.Version, tt.Status)
 			if tt.err != nil {
 				ts.Assert().NotNil(err)
 				ts.Assert().EqualError(err, tt.err.Error())
@@ -277,3 +466,175 @@ func (ts *SubscriptionsServiceTestSuite) TestSubscriptionService_UpdateSubscript
 		})
 	}
 }
+
+func (ts *SubscriptionsServiceTestSuite) TestSubscriptionService_ListSubscriptions() {
+	ctx := context.Background()
+	subscriptions := []domain.Subscription{
+		{
+			ID:        uuid.New(),
+			ProductID: uuid.New(),
+			Status:    domain.SubscriptionStatusPaused,
+			Version:   1,
+		},
+	}
+
+	tc := []struct {
+		Name          string
+		filter        domain.SubscriptionFilter
+		expectedQuery domain.SubscriptionFilter
+		err           error
+		listTimes     int
+	}{
+		{
+			Name:          "List subscriptions with default limit",
+			filter:        domain.SubscriptionFilter{Status: domain.SubscriptionStatusPaused},
+			expectedQuery: domain.SubscriptionFilter{Status: domain.SubscriptionStatusPaused, Limit: constants.DefaultPageSize},
+			listTimes:     1,
+		},
+		{
+			Name:          "List subscriptions with pagination",
+			filter:        domain.SubscriptionFilter{Limit: 10, Offset: 20},
+			expectedQuery: domain.SubscriptionFilter{Limit: 10, Offset: 20},
+			listTimes:     1,
+		},
+		{
+			Name:   "List subscriptions: invalid status",
+			filter: domain.SubscriptionFilter{Status: "expired"},
+			err:    domain.ErrInvalidSubscriptionStatusPassed,
+		},
+		{
+			Name:   "List subscriptions: limit too large",
+			filter: domain.SubscriptionFilter{Limit: constants.MaxPageSize + 1},
+			err:    domain.ErrInvalidPagination,
+		},
+		{
+			Name:   "List subscriptions: negative offset",
+			filter: domain.SubscriptionFilter{Offset: -1},
+			err:    domain.ErrInvalidPagination,
+		},
+	}
+
+	for _, tt := range tc {
+		ts.Run(tt.Name, func() {
+			ts.subscriptionsRepo.EXPECT().
+				List(gomock.Any(), tt.expectedQuery).
+				Times(tt.listTimes).
+				Return(subscriptions, nil)
+
+			got, err := ts.service.ListSubscriptions(ctx, tt.filter)
+			if tt.err != nil {
+				ts.Assert().ErrorIs(err, tt.err)
+			} else {
+				ts.Assert().Nil(err)
+				ts.Assert().Equal(subscriptions, got)
+			}
+		})
+	}
+}
+
+func (ts *SubscriptionsServiceTestSuite) TestSubscriptionService_ExportSubscriptions() {
+	ctx := context.Background()
+	fn := func(domain.SubscriptionExportRow) error { return nil }
+
+	ts.subscriptionsRepo.EXPECT().
+		Stream(gomock.Any(), domain.SubscriptionFilter{Status: domain.SubscriptionStatusActive}, gomock.Any()).
+		Times(1).
+		Return(nil)
+	ts.Assert().Nil(ts.service.ExportSubscriptions(ctx, domain.SubscriptionFilter{Status: domain.SubscriptionStatusActive}, fn))
+
+	// No default limit is applied when exporting
+	err := ts.service.ExportSubscriptions(ctx, domain.SubscriptionFilter{Status: "expired"}, fn)
+	ts.Assert().ErrorIs(err, domain.ErrInvalidSubscriptionStatusPassed)
+	err = ts.service.ExportSubscriptions(ctx, domain.SubscriptionFilter{Offset: -1}, fn)
+	ts.Assert().ErrorIs(err, domain.ErrInvalidPagination)
+}
+
+func (ts *SubscriptionsServiceTestSuite) TestSubscriptionService_CustomerScoping() {
+	ctx := domain.ContextWithPrincipal(context.Background(), domain.Principal{Subject: "customer-1"})
+	own := domain.Subscription{ID: uuid.New(), CustomerID: "customer-1", Status: domain.SubscriptionStatusActive, Version: 1}
+	other := domain.Subscription{ID: uuid.New(), CustomerID: "customer-2", Status: domain.SubscriptionStatusActive, Version: 1}
+	ts.subscriptionsRepo.EXPECT().GetByID(gomock.Any(), own.ID).AnyTimes().Return(own, nil)
+	ts.subscriptionsRepo.EXPECT().GetByID(gomock.Any(), other.ID).AnyTimes().Return(other, nil)
+
+	ts.Run("Fetch own subscription", func() {
+		got, err := ts.service.FetchSubscription(ctx, own.ID)
+		ts.Require().Nil(err)
+		ts.Assert().Equal(own, got)
+	})
+
+	ts.Run("Fetch subscription of another customer", func() {
+		_, err := ts.service.FetchSubscription(ctx, other.ID)
+		ts.Assert().ErrorIs(err, domain.ErrSubscriptionNotfound)
+	})
+
+	ts.Run("Update subscription of another customer", func() {
+		err := ts.service.UpdateSubscriptionStatus(ctx, other.ID, 1, domain.SubscriptionStatusCancel)
+		ts.Assert().ErrorIs(err, domain.ErrSubscriptionNotfound)
+	})
+
+	ts.Run("List only the own subscriptions", func() {
+		ts.subscriptionsRepo.EXPECT().
+			List(gomock.Any(), domain.SubscriptionFilter{CustomerID: "customer-1", Limit: constants.DefaultPageSize}).
+			Times(1).
+			Return([]domain.Subscription{own}, nil)
+
+		got, err := ts.service.ListSubscriptions(ctx, domain.SubscriptionFilter{CustomerID: "customer-2"})
+		ts.Require().Nil(err)
+		ts.Assert().Equal([]domain.Subscription{own}, got)
+	})
+
+	ts.Run("Create subscription for the caller", func() {
+		product := domain.Product{ID: uuid.New(), MonthlyPrice: 5}
+		ts.productsRepo.EXPECT().GetByID(gomock.Any(), product.ID).Times(1).Return(product, nil)
+		ts.subscriptionsRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).
+			DoAndReturn(func(_ context.Context, sub domain.Subscription) error {
+				ts.Assert().Equal("customer-1", sub.CustomerID)
+				return nil
+			})
+		ts.revenue.EXPECT().ScheduleRevenue(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+		ts.metrics.EXPECT().SubscriptionsCreated(product.ID, domain.SubscriptionStatusActive, 1).Times(1)
+
+		err := ts.service.CreateSubscription(ctx, product.ID, 3, time.Now())
+		ts.Assert().Nil(err)
+	})
+}
+
+func (ts *SubscriptionsServiceTestSuite) TestSubscriptionService_StaffAccess() {
+	customer := domain.ContextWithPrincipal(context.Background(), domain.Principal{
+		Subject: "customer-1",
+		Roles:   []domain.Role{domain.RoleCustomer},
+	})
+	support := domain.ContextWithPrincipal(context.Background(), domain.Principal{
+		Subject: "support-1",
+		Roles:   []domain.Role{domain.RoleSupport},
+	})
+	sub := domain.Subscription{ID: uuid.New(), CustomerID: "customer-1", Status: domain.SubscriptionStatusInactive, Version: 1}
+	ts.subscriptionsRepo.EXPECT().GetByID(gomock.Any(), sub.ID).AnyTimes().Return(sub, nil)
+
+	ts.Run("Support fetches the subscription of any customer", func() {
+		got, err := ts.service.FetchSubscription(support, sub.ID)
+		ts.Require().Nil(err)
+		ts.Assert().Equal(sub, got)
+	})
+
+	ts.Run("Customer can't activate before the start date", func() {
+		err := ts.service.UpdateSubscriptionStatus(customer, sub.ID, 1, domain.SubscriptionStatusActive)
+		ts.Assert().ErrorIs(err, domain.ErrForbidden)
+	})
+
+	ts.Run("Support overrides the status", func() {
+		ts.subscriptionsRepo.EXPECT().Patch(gomock.Any(), sub.ID, 1, gomock.Any()).Times(1).Return(nil)
+		ts.revenue.EXPECT().AdjustRevenue(gomock.Any(), sub, domain.SubscriptionStatusActive, gomock.Any()).Times(1).Return(nil)
+		ts.metrics.EXPECT().SubscriptionStatusChanged(domain.SubscriptionStatusInactive, domain.SubscriptionStatusActive).Times(1)
+
+		err := ts.service.UpdateSubscriptionStatus(support, sub.ID, 1, domain.SubscriptionStatusActive)
+		ts.Assert().Nil(err)
+	})
+
+	ts.Run("Customer can't export", func() {
+		err := ts.service.ExportSubscriptions(customer, domain.SubscriptionFilter{}, func(domain.SubscriptionExportRow) error {
+			return nil
+		})
+		ts.Assert().ErrorIs(err, domain.ErrForbidden)
+	})
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// instrumentationName names the tracer of the services.
+const instrumentationName = "github.com/goakshit/isildur/services"
+
+// startSpan starts the span of a service method, named after it. The returned function ends the
+// span with the error the method returns, to be deferred:
+//
+//	ctx, end := startSpan(ctx, "SubscriptionService.FetchSubscription")
+//	defer end(&err)
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, func(*error)) {
+	ctx, span := otel.Tracer(instrumentationName).Start(ctx, name)
+	span.SetAttributes(attrs...)
+	return ctx, func(err *error) {
+		if *err != nil {
+			span.RecordError(*err)
+			span.SetStatus(codes.Error, (*err).Error())
+		}
+		span.End()
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/goakshit/isildur/core/domain"
+	"github.com/goakshit/isildur/core/ports"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type TracingTestSuite struct {
+	suite.Suite
+	spans        *tracetest.InMemoryExporter
+	productsRepo *ports.MockProductsRepository
+	service      *ProductsService
+}
+
+func TestTracingTestSuite(t *testing.T) {
+	suite.Run(t, new(TracingTestSuite))
+}
+
+func (ts *TracingTestSuite) SetupTest() {
+	ts.spans = tracetest.NewInMemoryExporter()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(ts.spans)))
+	ctrl := gomock.NewController(ts.T())
+	ts.productsRepo = ports.NewMockProductsRepository(ctrl)
+	ts.service = NewProductsService(ts.productsRepo)
+}
+
+func (ts *TracingTestSuite) TestSpans() {
+	productID := uuid.New()
+	tt := []struct {
+		name           string
+		repoErr        error
+		expectedStatus codes.Code
+	}{
+		{name: "Success", expectedStatus: codes.Unset},
+		{name: "Error", repoErr: domain.ErrProductNotfound, expectedStatus: codes.Error},
+	}
+	for _, tc := range tt {
+		ts.Run(tc.name, func() {
+			ts.spans.Reset()
+			var repoSpan trace.SpanContext
+			ts.productsRepo.EXPECT().GetByID(gomock.Any(), productID).DoAndReturn(
+				func(ctx context.Context, id uuid.UUID) (domain.Product, error) {
+					repoSpan = trace.SpanContextFromContext(ctx)
+					return domain.Product{ID: id}, tc.repoErr
+				})
+
+			_, err := ts.service.FetchProduct(context.Background(), productID)
+			ts.Require().Equal(tc.repoErr, err)
+
+			spans := ts.spans.GetSpans()
+			ts.Require().Len(spans, 1)
+			ts.Assert().Equal("ProductsService.FetchProduct", spans[0].Name)
+			ts.Assert().Equal(tc.expectedStatus, spans[0].Status.Code)
+			// The repositories run within the span of the service
+			ts.Assert().Equal(spans[0].SpanContext, repoSpan)
+		})
+	}
+}